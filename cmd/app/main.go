@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/adal4ik/crypto-service/internal/config"
 	"github.com/adal4ik/crypto-service/internal/handler"
@@ -53,13 +54,18 @@ func main() {
 
 	db, err := repository.ConnectDB(ctx, cfg.Postgres, logger)
 	if err != nil {
-		logger.Fatal("Failed to connect to the database", zap.Error(err))
+		logger.Fatal("Failed to open the database", zap.Error(err))
 	}
 	defer db.Close()
-	logger.Info("Connected to the database successfully", zap.String("dsn", cfg.Postgres.PostgresDSN))
 	repo := repository.NewRepository(db, logger)
+	// Сервис стартует в деградированном режиме и сам переходит в рабочий,
+	// как только база станет доступна.
+	if !repo.Health.Check(ctx) {
+		logger.Warn("Database is not reachable yet, starting in degraded mode", zap.String("reason", repo.Health.Status().Reason))
+	}
+	go repo.Health.Run(ctx)
 	service := service.NewService(repo, logger, cfg)
-	handlers := handler.NewHandlers(service, logger)
+	handlers := handler.NewHandlers(service, logger, cfg.App)
 	logger.Info("All components initialized successfully")
 
 	go service.PriceCollector.Start(ctx)
@@ -0,0 +1,108 @@
+// Команда import загружает историю цен из CSV- или NDJSON-файла напрямую в базу,
+// так же как POST /import/prices:
+//
+//	go run ./cmd/import -file prices.csv
+//	go run ./cmd/import -format ndjson < prices.ndjson
+//
+// Подключение к базе берётся из того же окружения (.env), что и у сервиса.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository"
+	"github.com/adal4ik/crypto-service/internal/service"
+	"github.com/adal4ik/crypto-service/pkg/loadenv"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+)
+
+func main() {
+	file := flag.String("file", "-", "file to import, '-' for stdin")
+	format := flag.String("format", "", "csv or ndjson (default: from the file extension, csv for stdin)")
+	batch := flag.Int("batch", 0, "rows per transaction (default: IMPORT_BATCH_SIZE)")
+	verbose := flag.Bool("v", false, "log every batch")
+	flag.Parse()
+
+	if err := run(*file, *format, *batch, *verbose); err != nil {
+		fmt.Fprintln(os.Stderr, "import failed:", err)
+		os.Exit(1)
+	}
+}
+
+func run(file, format string, batch int, verbose bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	loadenv.LoadEnvFile(".env")
+	cfg := config.LoadConfig()
+	if batch > 0 {
+		cfg.App.ImportBatchSize = batch
+	}
+	log := logger.NewNopLogger()
+	if verbose {
+		log = logger.New(os.Getenv("APP_ENV"))
+	}
+
+	var input io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+		if format == "" {
+			switch strings.ToLower(filepath.Ext(file)) {
+			case ".ndjson", ".jsonl":
+				format = string(domain.ExportFormatNDJSON)
+			default:
+				format = string(domain.ExportFormatCSV)
+			}
+		}
+	}
+
+	db, err := repository.ConnectDB(ctx, cfg.Postgres, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	repo := repository.NewRepository(db, log)
+	importer := service.NewImportService(repo.Price, repo.CurrencyRepository, log, cfg.App)
+
+	report, appErr := importer.Import(ctx, domain.ExportFormat(format), input)
+	if appErr != nil {
+		if appErr.Details != nil {
+			fmt.Fprintf(os.Stderr, "written before the error: %v\n", appErr.Details)
+		}
+		return appErr
+	}
+	printReport(os.Stdout, report)
+	return nil
+}
+
+func printReport(w io.Writer, report domain.ImportReport) {
+	fmt.Fprintf(w, "rows: %d, accepted: %d, duplicate: %d, rejected: %d\n",
+		report.Total, report.Accepted, report.Duplicate, report.Rejected)
+	if len(report.Duplicates) > 0 {
+		lines := make([]string, len(report.Duplicates))
+		for i, line := range report.Duplicates {
+			lines[i] = fmt.Sprint(line)
+		}
+		fmt.Fprintf(w, "duplicate lines: %s\n", strings.Join(lines, ", "))
+	}
+	for _, rej := range report.Rejections {
+		fmt.Fprintf(w, "line %d: %s\n", rej.Line, rej.Reason)
+	}
+	if report.Truncated {
+		fmt.Fprintln(w, "(line lists truncated)")
+	}
+}
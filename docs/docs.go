@@ -24,33 +24,122 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/currency/add": {
-            "post": {
-                "description": "Adds a new cryptocurrency symbol to the tracking list.",
-                "consumes": [
+        "/api/v1/admin/buffer": {
+            "get": {
+                "description": "Returns how many collected prices are waiting in the local buffer for the database, the oldest of them, and how many buffered prices the database rejected permanently and were moved to the dead-letter file.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Write-ahead buffer status",
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.BufferStatusResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/collector/intervals": {
+            "get": {
+                "description": "Returns the effective polling interval and recent volatility for every tracked currency.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "currency"
+                    "admin"
                 ],
-                "summary": "Add a cryptocurrency",
-                "parameters": [
-                    {
-                        "description": "Symbol to add",
-                        "name": "symbol",
-                        "in": "body",
-                        "required": true,
+                "summary": "Collector polling intervals",
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
                         "schema": {
-                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.AddCurrencyRequest"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CollectorScheduleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
                         }
                     }
+                }
+            }
+        },
+        "/api/v1/analytics/correlation": {
+            "get": {
+                "description": "Aligns the selected currencies on a common grid, computes log returns between cells that both hold a real sample (gaps are not filled) and returns the Pearson (and optionally Spearman) correlation matrix with the number of overlapping returns per pair.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Correlation matrix",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated currency symbols (at least two)",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (unix seconds or RFC3339), default 30 days before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (unix seconds or RFC3339), default now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grid step, e.g. 15m, 1h (default), 1d",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also compute Spearman rank correlation",
+                        "name": "spearman",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully added",
+                    "200": {
+                        "description": "Successful response",
                         "schema": {
                             "allOf": [
                                 {
@@ -60,7 +149,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.GenericResponse"
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CorrelationResponse"
                                         }
                                     }
                                 }
@@ -73,6 +162,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -82,9 +177,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/currency/price": {
+        "/api/v1/convert": {
             "post": {
-                "description": "Get the price of a cryptocurrency at the nearest available time to the requested timestamp.",
+                "description": "Converts an amount of one cryptocurrency into another using the cross rate of their nearest USD samples at the given timestamp (now by default). Returns the rates and sample timestamps used.",
                 "consumes": [
                     "application/json"
                 ],
@@ -94,15 +189,15 @@ const docTemplate = `{
                 "tags": [
                     "price"
                 ],
-                "summary": "Get cryptocurrency price",
+                "summary": "Convert between currencies",
                 "parameters": [
                     {
-                        "description": "Coin and Timestamp",
+                        "description": "From, to, amount and optional timestamp",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.GetPriceRequest"
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.ConvertRequest"
                         }
                     }
                 ],
@@ -118,7 +213,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PriceResponse"
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.ConvertResponse"
                                         }
                                     }
                                 }
@@ -137,6 +232,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -146,9 +247,111 @@ const docTemplate = `{
                 }
             }
         },
-        "/currency/remove": {
+        "/api/v1/currencies": {
+            "get": {
+                "description": "Returns tracked cryptocurrencies with collection status: provider mapping, first and last sample and the last collection error. The last error is cleared after a successful collection. Last errors are kept in memory by the collector, so they are lost on restart. sample_count scans the whole history of every currency, so it is only returned with counts=true or sort=sample_count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "List tracked currencies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated symbols to include",
+                        "name": "symbols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only currencies that do (true) or do not (false) map to a provider",
+                        "name": "mapped",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only currencies with (true) or without (false) a last collection error",
+                        "name": "failing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only archived (true) or active (false) currencies",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include sample_count (scans the whole history)",
+                        "name": "counts",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "symbol",
+                            "created_at",
+                            "first_sample",
+                            "last_sample",
+                            "sample_count"
+                        ],
+                        "type": "string",
+                        "default": "symbol",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CurrencyStatusResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Removes a cryptocurrency symbol from the tracking list.",
+                "description": "Adds a cryptocurrency to the tracking list and returns it. Adding a symbol that is already tracked is not an error.",
                 "consumes": [
                     "application/json"
                 ],
@@ -158,21 +361,36 @@ const docTemplate = `{
                 "tags": [
                     "currency"
                 ],
-                "summary": "Remove a cryptocurrency",
+                "summary": "Track a cryptocurrency",
                 "parameters": [
                     {
-                        "description": "Symbol to remove",
-                        "name": "symbol",
+                        "description": "Symbol to track",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.RemoveCurrencyRequest"
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.AddCurrencyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Successfully removed (No Content)"
+                    "201": {
+                        "description": "Tracked; Location points to the resource",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CurrencyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -188,50 +406,2120 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "github_com_adal4ik_crypto-service_internal_domain_dto.AddCurrencyRequest": {
-            "type": "object",
-            "properties": {
-                "symbol": {
-                    "type": "string"
-                }
-            }
-        },
-        "github_com_adal4ik_crypto-service_internal_domain_dto.GenericResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                }
-            }
-        },
-        "github_com_adal4ik_crypto-service_internal_domain_dto.GetPriceRequest": {
-            "type": "object",
-            "properties": {
-                "coin": {
-                    "type": "string"
-                },
-                "timestamp": {
-                    "type": "integer"
-                }
-            }
         },
-        "github_com_adal4ik_crypto-service_internal_domain_dto.PriceResponse": {
-            "type": "object",
-            "properties": {
-                "price": {
-                    "type": "number"
-                },
-                "symbol": {
-                    "type": "string"
-                },
-                "timestamp": {
-                    "type": "integer"
+        "/api/v1/currencies/{symbol}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Get a tracked cryptocurrency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CurrencyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Archives a cryptocurrency: price collection stops, the price history is kept and stays queryable. Tracking can be resumed with POST /api/v1/currencies/{symbol}/resume.",
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Stop tracking a cryptocurrency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Archived (No Content)"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Changes metadata of a tracked cryptocurrency. Omitted fields stay unchanged; provider_ids and tags are replaced as a whole. Setting enabled to false pauses price collection.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Update currency metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.UpdateCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated currency",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CurrencyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/currencies/{symbol}/candles": {
+            "get": {
+                "description": "Aggregates price history into open/high/low/close candles. Buckets are aligned to the requested timezone (UTC by default): daily candles start at local midnight, shorter ones always last exactly one interval across DST changes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "price"
+                ],
+                "summary": "Get OHLC candles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Candle size: 1m, 5m, 1h or 1d",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (unix seconds or RFC3339), default 100 candles before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (unix seconds or RFC3339), default now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA timezone for bucket alignment, e.g. Europe/Berlin",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "omit (default) or flag to include empty buckets",
+                        "name": "empty",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CandlesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/currencies/{symbol}/drawdown": {
+            "get": {
+                "description": "Returns the maximum peak-to-trough decline within a window, the peak and trough samples, the first sample at which the price regained the peak (null if it has not) and the full underwater series. Without 'interval' raw samples are used; with it, candle closes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Maximum drawdown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window start (unix seconds or RFC3339), default 30 days before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window end (unix seconds or RFC3339), default now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Candle size: 1m, 5m, 1h or 1d; raw samples if omitted",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.DrawdownResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/currencies/{symbol}/history": {
+            "get": {
+                "description": "Returns price samples of a cryptocurrency within a time range, page by page. Pass next_cursor from the previous page as cursor to continue.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "price"
+                ],
+                "summary": "Get price history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (unix seconds or RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (unix seconds or RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-1000, default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default desc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PriceHistoryResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/currencies/{symbol}/indicators": {
+            "get": {
+                "description": "Computes SMA, EMA, RSI, MACD or Bollinger bands on candle closes over a range. Extra history before 'from' is used for warm-up; values that still cannot be computed are null.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Technical indicators",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "sma, ema, rsi, macd or bollinger",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Period (default 20, RSI 14)",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "MACD fast period (default 12)",
+                        "name": "fast",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "MACD slow period (default 26)",
+                        "name": "slow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "MACD signal period (default 9)",
+                        "name": "signal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Bollinger band width in standard deviations (default 2)",
+                        "name": "k",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Candle size: 1m, 5m, 1h (default) or 1d",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (unix seconds or RFC3339), default 100 candles before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (unix seconds or RFC3339), default now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.IndicatorResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/currencies/{symbol}/price": {
+            "get": {
+                "description": "Get the price of a cryptocurrency at a moment in time (now by default). mode selects the nearest sample (default), the last one at or before, the first one at or after the moment, or interpolates linearly between the two and returns both source samples. With max_distance set, a sample further away than that many seconds yields 404 with the distance in details.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "price"
+                ],
+                "summary": "Get cryptocurrency price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Moment (unix seconds or RFC3339), default now",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nearest (default), before, after or interpolate",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum distance to the sample in seconds",
+                        "name": "max_distance",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PriceResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/currencies/{symbol}/purge": {
+            "post": {
+                "description": "Irreversibly deletes an archived cryptocurrency together with its price history. The currency must be archived first, and the body must repeat its symbol in \"confirm\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Purge an archived cryptocurrency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Confirmation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PurgeCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Purged",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PurgeCurrencyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Currency is not archived",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/currencies/{symbol}/resume": {
+            "post": {
+                "description": "Resumes price collection for an archived cryptocurrency. Its price history is intact. Resuming an active currency is not an error.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Resume tracking a cryptocurrency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resumed currency",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CurrencyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/currencies/{symbol}/stats": {
+            "get": {
+                "description": "Returns min and max with their timestamps, mean, median, sample standard deviation, annualized realized volatility (from log returns) and sample count within a time window.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Price statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window start (unix seconds or RFC3339), default 24h before 'to'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window end (unix seconds or RFC3339), default now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PriceStatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/export/prices": {
+            "get": {
+                "description": "Streams price history as CSV or NDJSON straight from the database without buffering the whole result. The long layout has one row per sample (timestamp, symbol, price); the wide layout has one row per timestamp and one column per symbol, empty where a currency has no sample at that moment. Timestamps are RFC3339 in UTC. If the stream fails midway the connection is closed, so a truncated file never looks complete.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "price"
+                ],
+                "summary": "Export price history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated currency symbols, all tracked by default",
+                        "name": "symbols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (unix seconds or RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (unix seconds or RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv (default) or ndjson",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "long (default) or wide",
+                        "name": "layout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Export stream",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/import/prices": {
+            "post": {
+                "description": "Loads historical prices from a CSV (header with timestamp, symbol, price) or NDJSON file, sent either as the raw request body or as the 'file' field of a multipart form. Each row is validated: known symbol, positive decimal price, timestamp in unix seconds or RFC3339. Rows are written in batches, one transaction per batch. A row whose currency already has a sample at that time is reported as a duplicate and skipped. The report lists duplicate and rejected rows by line number.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "price"
+                ],
+                "summary": "Import price history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv or ndjson; detected from Content-Type or file name if omitted",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to import (multipart upload)",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import report",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.ImportReportResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/prices/batch": {
+            "post": {
+                "description": "Resolves the nearest available price for many coin/timestamp pairs in one request. Results keep the request order; an item that cannot be resolved carries its own error and does not fail the batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "price"
+                ],
+                "summary": "Batch nearest prices",
+                "parameters": [
+                    {
+                        "description": "Coin/timestamp pairs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.BatchPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.BatchPriceResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/prices/latest": {
+            "get": {
+                "description": "Returns the most recent sample, its age and the change versus the last sample at least 24h older than it, for every tracked currency or only for the given symbols.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "price"
+                ],
+                "summary": "Latest prices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated currency symbols; archived currencies are included only when listed",
+                        "name": "symbols",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.LatestPriceResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/prices/performance": {
+            "get": {
+                "description": "Returns the absolute and percentage change of the latest price over each window for every tracked currency, or only for the given symbols. The base of a window is the last sample at or before its start; if history starts later, the earliest sample is used and history_incomplete is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Price performance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated currency symbols; archived currencies are included only when listed",
+                        "name": "symbols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated windows, e.g. 1h,24h,7d,30d,ytd (default)",
+                        "name": "windows",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PerformanceResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/prices/resample": {
+            "get": {
+                "description": "Returns one value per grid point for each requested currency. Grid points without a sample in (t-step, t] are filled with the chosen strategy and flagged as not observed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Resample price series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated currency symbols",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grid start (unix seconds or RFC3339)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grid end (unix seconds or RFC3339)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grid step, e.g. 15m, 1h, 1d",
+                        "name": "step",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "previous (default), linear or null",
+                        "name": "fill",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.ResampleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/add": {
+            "post": {
+                "description": "Adds a new cryptocurrency symbol to the tracking list. Deprecated: use POST /api/v1/currencies.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Add a cryptocurrency",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Symbol to add",
+                        "name": "symbol",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.AddCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully added",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.GenericResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/price": {
+            "post": {
+                "description": "Get the price of a cryptocurrency at the requested timestamp. mode selects the nearest sample (default), the last one at or before, the first one at or after the timestamp, or interpolates linearly between the two and returns both source samples. With max_distance set, a sample further away than that many seconds yields 404 with the distance in details. Deprecated: use GET /api/v1/currencies/{symbol}/price.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "price"
+                ],
+                "summary": "Get cryptocurrency price",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Coin, timestamp and optional mode/max_distance",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.GetPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PriceResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/remove": {
+            "post": {
+                "description": "Stops tracking a cryptocurrency; its price history is kept. Deprecated: use DELETE /api/v1/currencies/{symbol}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Remove a cryptocurrency",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Symbol to remove",
+                        "name": "symbol",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.RemoveCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successfully removed (No Content)"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Reports whether the service is healthy or running in degraded mode without a database connection.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Service health",
+                "responses": {
+                    "200": {
+                        "description": "Healthy",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.HealthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Degraded",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_pkg_response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.HealthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_adal4ik_crypto-service_internal_domain_dto.AddCurrencyRequest": {
+            "type": "object",
+            "properties": {
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.BatchItemError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.BatchPriceItem": {
+            "type": "object",
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.BatchPriceRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.BatchPriceItem"
+                    }
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.BatchPriceResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.BatchPriceResult"
+                    }
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.BatchPriceResult": {
+            "type": "object",
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "error": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.BatchItemError"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "requested_timestamp": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.BufferStatusResponse": {
+            "type": "object",
+            "properties": {
+                "dead_lettered": {
+                    "description": "DeadLettered - сэмплы, отвергнутые базой и перенесённые в файл недоставленных.",
+                    "type": "integer"
+                },
+                "depth": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "oldest_age_seconds": {
+                    "type": "number"
+                },
+                "oldest_timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.CandleResponse": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "number"
+                },
+                "empty": {
+                    "type": "boolean"
+                },
+                "high": {
+                    "type": "number"
+                },
+                "low": {
+                    "type": "number"
+                },
+                "open": {
+                    "type": "number"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.CandlesResponse": {
+            "type": "object",
+            "properties": {
+                "candles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CandleResponse"
+                    }
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.CollectionErrorResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.CollectorScheduleResponse": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string"
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.SymbolScheduleResponse"
+                    }
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.ConversionLeg": {
+            "type": "object",
+            "properties": {
+                "price_usd": {
+                    "type": "number"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.ConvertRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "Timestamp - момент пересчёта (unix), 0 - сейчас.",
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.ConvertResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "from": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.ConversionLeg"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "requested_timestamp": {
+                    "type": "integer"
+                },
+                "result": {
+                    "type": "number"
+                },
+                "to": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.ConversionLeg"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.CorrelationResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "observations": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "pearson": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "spearman": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.CurrencyResponse": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "description": "ArchivedAt - время прекращения отслеживания, null у активных валют.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "display_decimals": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "provider_ids": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.CurrencyStatusResponse": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "description": "ArchivedAt - время прекращения отслеживания, null у активных валют.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "display_decimals": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "first_sample": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.CollectionErrorResponse"
+                },
+                "last_sample": {
+                    "type": "integer"
+                },
+                "mapped": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_id": {
+                    "type": "string"
+                },
+                "provider_ids": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "sample_count": {
+                    "type": "integer"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.DrawdownResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "max_drawdown": {
+                    "type": "number"
+                },
+                "peak": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint"
+                },
+                "recovery": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "trough": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint"
+                },
+                "underwater": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.UnderwaterPointResponse"
+                    }
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.GenericResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.GetPriceRequest": {
+            "type": "object",
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "max_distance": {
+                    "description": "MaxDistance - допустимая удалённость сэмпла в секундах, 0 - без ограничения.",
+                    "type": "integer"
+                },
+                "mode": {
+                    "description": "Mode - nearest (по умолчанию), before, after или interpolate.",
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "database": {
+                    "type": "string"
+                },
+                "last_check": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.ImportRejectionResponse": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.ImportReportResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "duplicate": {
+                    "type": "integer"
+                },
+                "duplicates": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "rejected": {
+                    "type": "integer"
+                },
+                "rejections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.ImportRejectionResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "truncated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.IndicatorPointResponse": {
+            "type": "object",
+            "properties": {
+                "timestamp": {
+                    "type": "integer"
+                },
+                "values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.IndicatorResponse": {
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.IndicatorPointResponse"
+                    }
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.LatestPriceResponse": {
+            "type": "object",
+            "properties": {
+                "age_seconds": {
+                    "type": "integer"
+                },
+                "change_24h": {
+                    "type": "number"
+                },
+                "change_percent_24h": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.PerformanceResponse": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.WindowChangeResponse"
+                    }
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.PriceHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "order": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.PriceResponse": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint"
+                },
+                "before": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint"
+                },
+                "interpolated": {
+                    "type": "boolean"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.PriceStatsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "first_sample": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "last_sample": {
+                    "type": "integer"
+                },
+                "max": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint"
+                },
+                "mean": {
+                    "type": "number"
+                },
+                "median": {
+                    "type": "number"
+                },
+                "min": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint"
+                },
+                "stddev": {
+                    "type": "number"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "volatility_annualized": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.PurgeCurrencyRequest": {
+            "type": "object",
+            "properties": {
+                "confirm": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.PurgeCurrencyResponse": {
+            "type": "object",
+            "properties": {
+                "deleted_samples": {
+                    "type": "integer"
+                },
+                "symbol": {
+                    "type": "string"
                 }
             }
         },
@@ -243,12 +2531,141 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.ResampleResponse": {
+            "type": "object",
+            "properties": {
+                "fill": {
+                    "type": "string"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.SeriesResponse"
+                    }
+                },
+                "step": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.SeriesPointResponse": {
+            "type": "object",
+            "properties": {
+                "observed": {
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.SeriesResponse": {
+            "type": "object",
+            "properties": {
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.SeriesPointResponse"
+                    }
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.SymbolScheduleResponse": {
+            "type": "object",
+            "properties": {
+                "interval_seconds": {
+                    "type": "number"
+                },
+                "next_due": {
+                    "type": "integer"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "volatility": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.UnderwaterPointResponse": {
+            "type": "object",
+            "properties": {
+                "drawdown": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.UpdateCurrencyRequest": {
+            "type": "object",
+            "properties": {
+                "display_decimals": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "provider_ids": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_adal4ik_crypto-service_internal_domain_dto.WindowChangeResponse": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "$ref": "#/definitions/github_com_adal4ik_crypto-service_internal_domain_dto.PricePoint"
+                },
+                "change": {
+                    "type": "number"
+                },
+                "change_percent": {
+                    "type": "number"
+                },
+                "history_incomplete": {
+                    "type": "boolean"
+                },
+                "start": {
+                    "type": "integer"
+                },
+                "window": {
+                    "type": "string"
+                }
+            }
+        },
         "github_com_adal4ik_crypto-service_pkg_response.APIError": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer"
                 },
+                "details": {},
                 "message": {
                     "type": "string"
                 },
@@ -0,0 +1,317 @@
+// Package buffer реализует локальный append-only буфер цен, которые не удалось
+// записать в базу данных. Записи хранятся построчно в JSON и воспроизводятся по порядку.
+// Сэмплы, которые база не примет никогда, переносятся в отдельный файл недоставленных
+// (путь буфера с суффиксом .dead), чтобы не блокировать очередь.
+package buffer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+)
+
+// Stats - текущее состояние буфера. DeadLettered - сколько сэмплов перенесено в файл недоставленных.
+type Stats struct {
+	Depth        int
+	Oldest       time.Time
+	DeadLettered int
+}
+
+type FileBuffer struct {
+	// mu защищает файлы и счётчики; replayMu не даёт запустить два воспроизведения сразу.
+	// Во время вызовов fn в Replay mu не удерживается, поэтому Append и Stats не ждут базу.
+	mu       sync.Mutex
+	replayMu sync.Mutex
+	path     string
+	deadPath string
+	depth    int
+	oldest   time.Time
+	dead     int
+}
+
+// permanentError помечает ошибку, которая не исчезнет при повторе.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку fn в Replay как постоянную: сэмпл уходит в файл недоставленных,
+// а воспроизведение продолжается.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent сообщает, помечена ли ошибка через Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// Open открывает (или создаёт) файл буфера и подсчитывает уже накопленные записи.
+func Open(path string) (*FileBuffer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create buffer directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open buffer file: %w", err)
+	}
+	defer f.Close()
+
+	b := &FileBuffer{path: path, deadPath: path + ".dead"}
+	samples, err := readSamples(f)
+	if err != nil {
+		return nil, err
+	}
+	b.depth = len(samples)
+	if len(samples) > 0 {
+		b.oldest = samples[0].Timestamp
+	}
+	if err := terminateLastLine(path); err != nil {
+		return nil, err
+	}
+
+	if dead, err := os.Open(b.deadPath); err == nil {
+		deadSamples, err := readSamples(dead)
+		dead.Close()
+		if err != nil {
+			return nil, err
+		}
+		b.dead = len(deadSamples)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	return b, nil
+}
+
+// terminateLastLine дописывает перевод строки, если файл оборвался посреди записи,
+// чтобы следующие записи не склеились с битой строкой.
+func terminateLastLine(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open buffer file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat buffer file: %w", err)
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return fmt.Errorf("failed to read buffer file: %w", err)
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	if _, err := f.WriteAt([]byte{'\n'}, info.Size()); err != nil {
+		return fmt.Errorf("failed to repair buffer file: %w", err)
+	}
+	return nil
+}
+
+// Append дописывает сэмплы в конец файла и синхронизирует его на диск.
+func (b *FileBuffer) Append(samples ...domain.PriceSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, err := os.OpenFile(b.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open buffer file: %w", err)
+	}
+	defer f.Close()
+
+	if err := writeSamples(f, samples); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync buffer file: %w", err)
+	}
+
+	if b.depth == 0 {
+		b.oldest = samples[0].Timestamp
+	}
+	b.depth += len(samples)
+	return nil
+}
+
+// Replay передаёт сэмплы в fn в порядке записи. Сэмплы, для которых fn вернула ошибку,
+// помеченную Permanent, переносятся в файл недоставленных; на любой другой ошибке
+// воспроизведение останавливается, а необработанный хвост остаётся в буфере.
+// fn вызывается без блокировки буфера: сэмплы, дописанные за это время, сохраняются
+// после хвоста. Возвращает число успешно воспроизведённых сэмплов.
+func (b *FileBuffer) Replay(ctx context.Context, fn func(domain.PriceSample) error) (int, error) {
+	b.replayMu.Lock()
+	defer b.replayMu.Unlock()
+
+	b.mu.Lock()
+	samples, err := b.read()
+	b.mu.Unlock()
+	if err != nil || len(samples) == 0 {
+		return 0, err
+	}
+
+	replayed, processed := 0, 0
+	var dead []domain.PriceSample
+	var replayErr error
+	for _, s := range samples {
+		if err := ctx.Err(); err != nil {
+			replayErr = err
+			break
+		}
+		if err := fn(s); err != nil {
+			if !IsPermanent(err) {
+				replayErr = err
+				break
+			}
+			dead = append(dead, s)
+		} else {
+			replayed++
+		}
+		processed++
+	}
+	if processed == 0 {
+		return 0, replayErr
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Сначала сохраняем недоставленные: при сбое между шагами сэмпл задвоится, но не потеряется.
+	if err := b.deadLetter(dead); err != nil {
+		return replayed, err
+	}
+	// Append только дописывает в конец, поэтому первые processed записей файла - те же,
+	// что в прочитанном снимке.
+	current, err := b.read()
+	if err != nil {
+		return replayed, err
+	}
+	if err := b.rewrite(current[processed:]); err != nil {
+		return replayed, err
+	}
+	return replayed, replayErr
+}
+
+// read читает все сэмплы буфера. Вызывается под mu.
+func (b *FileBuffer) read() ([]domain.PriceSample, error) {
+	if b.depth == 0 {
+		return nil, nil
+	}
+	f, err := os.Open(b.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open buffer file: %w", err)
+	}
+	defer f.Close()
+	return readSamples(f)
+}
+
+// deadLetter дописывает сэмплы в файл недоставленных. Вызывается под mu.
+func (b *FileBuffer) deadLetter(samples []domain.PriceSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(b.deadPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer f.Close()
+
+	if err := writeSamples(f, samples); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync dead-letter file: %w", err)
+	}
+	b.dead += len(samples)
+	return nil
+}
+
+func (b *FileBuffer) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Stats{Depth: b.depth, Oldest: b.oldest, DeadLettered: b.dead}
+}
+
+// rewrite атомарно заменяет файл буфера оставшимися сэмплами.
+func (b *FileBuffer) rewrite(remaining []domain.PriceSample) error {
+	tmp := b.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create buffer temp file: %w", err)
+	}
+	if err := writeSamples(f, remaining); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync buffer temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close buffer temp file: %w", err)
+	}
+	if err := os.Rename(tmp, b.path); err != nil {
+		return fmt.Errorf("failed to replace buffer file: %w", err)
+	}
+
+	b.depth = len(remaining)
+	b.oldest = time.Time{}
+	if len(remaining) > 0 {
+		b.oldest = remaining[0].Timestamp
+	}
+	return nil
+}
+
+func readSamples(f *os.File) ([]domain.PriceSample, error) {
+	var samples []domain.PriceSample
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var s domain.PriceSample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			// Обрезанная последняя строка после сбоя не должна блокировать остальной буфер.
+			continue
+		}
+		samples = append(samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read buffer file at line %d: %w", line, err)
+	}
+	return samples, nil
+}
+
+func writeSamples(f *os.File, samples []domain.PriceSample) error {
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, s := range samples {
+		if err := enc.Encode(s); err != nil {
+			return fmt.Errorf("failed to encode buffered sample: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write buffer file: %w", err)
+	}
+	return nil
+}
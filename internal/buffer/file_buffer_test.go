@@ -0,0 +1,143 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sample(symbol string, price float64, ts int64) domain.PriceSample {
+	return domain.PriceSample{Symbol: symbol, Price: decimal.NewFromFloat(price), Timestamp: time.Unix(ts, 0).UTC()}
+}
+
+func TestFileBuffer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("append_and_replay_in_order", func(t *testing.T) {
+		b, err := Open(filepath.Join(t.TempDir(), "buf", "prices.ndjson"))
+		require.NoError(t, err)
+
+		require.NoError(t, b.Append(sample("BTC", 1, 100), sample("ETH", 2, 101)))
+		require.NoError(t, b.Append(sample("BTC", 3, 102)))
+		assert.Equal(t, Stats{Depth: 3, Oldest: time.Unix(100, 0).UTC()}, b.Stats())
+
+		var got []string
+		n, err := b.Replay(ctx, func(s domain.PriceSample) error {
+			got = append(got, s.Symbol+"@"+s.Price.String())
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []string{"BTC@1", "ETH@2", "BTC@3"}, got)
+		assert.Equal(t, 0, b.Stats().Depth)
+	})
+
+	t.Run("replay_stops_on_error_and_keeps_tail", func(t *testing.T) {
+		b, err := Open(filepath.Join(t.TempDir(), "prices.ndjson"))
+		require.NoError(t, err)
+		require.NoError(t, b.Append(sample("BTC", 1, 100), sample("ETH", 2, 101), sample("SOL", 3, 102)))
+
+		dbDown := errors.New("db down")
+		n, err := b.Replay(ctx, func(s domain.PriceSample) error {
+			if s.Symbol == "ETH" {
+				return dbDown
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, dbDown)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, Stats{Depth: 2, Oldest: time.Unix(101, 0).UTC()}, b.Stats())
+	})
+
+	t.Run("reopen_restores_depth_and_skips_torn_line", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prices.ndjson")
+		b, err := Open(path)
+		require.NoError(t, err)
+		require.NoError(t, b.Append(sample("BTC", 1, 100), sample("ETH", 2, 101)))
+
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = f.WriteString(`{"symbol":"SO`)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		reopened, err := Open(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, Stats{Depth: 2, Oldest: time.Unix(100, 0).UTC()}, reopened.Stats())
+
+		require.NoError(t, reopened.Append(sample("SOL", 3, 102)))
+		var got []string
+		_, err = reopened.Replay(ctx, func(s domain.PriceSample) error {
+			got = append(got, s.Symbol)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"BTC", "ETH", "SOL"}, got)
+	})
+
+	t.Run("permanent_errors_are_dead_lettered", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prices.ndjson")
+		b, err := Open(path)
+		require.NoError(t, err)
+		require.NoError(t, b.Append(sample("BTC", 1, 100), sample("ETH", 2, 101), sample("SOL", 3, 102)))
+
+		var got []string
+		n, err := b.Replay(ctx, func(s domain.PriceSample) error {
+			if s.Symbol == "ETH" {
+				return Permanent(errors.New("numeric field overflow"))
+			}
+			got = append(got, s.Symbol)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"BTC", "SOL"}, got)
+		assert.Equal(t, Stats{DeadLettered: 1}, b.Stats())
+
+		reopened, err := Open(path)
+		require.NoError(t, err)
+		assert.Equal(t, Stats{DeadLettered: 1}, reopened.Stats())
+		dead, err := os.ReadFile(path + ".dead")
+		require.NoError(t, err)
+		assert.Contains(t, string(dead), `"symbol":"ETH"`)
+	})
+
+	t.Run("append_during_replay_is_not_blocked_and_kept", func(t *testing.T) {
+		b, err := Open(filepath.Join(t.TempDir(), "prices.ndjson"))
+		require.NoError(t, err)
+		require.NoError(t, b.Append(sample("BTC", 1, 100), sample("ETH", 2, 101)))
+
+		n, err := b.Replay(ctx, func(s domain.PriceSample) error {
+			if s.Symbol == "BTC" {
+				// Вызов под блокировкой буфера здесь бы завис.
+				require.NoError(t, b.Append(sample("SOL", 3, 102)))
+				assert.Equal(t, 3, b.Stats().Depth)
+				return nil
+			}
+			return errors.New("db down")
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, n)
+
+		var got []string
+		_, err = b.Replay(ctx, func(s domain.PriceSample) error {
+			got = append(got, s.Symbol)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ETH", "SOL"}, got)
+	})
+}
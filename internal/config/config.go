@@ -9,20 +9,62 @@ import (
 type AppConfig struct {
 	AppPort  string
 	LogLevel string
+	// PriceBatchLimit - максимальное число элементов в пакетном запросе цен.
+	PriceBatchLimit int
+	// ImportBatchSize - число строк импорта, записываемых одной транзакцией.
+	ImportBatchSize int
+	// ImportMaxBytes - максимальный размер загружаемого файла импорта.
+	ImportMaxBytes int64
 }
 
 type PostgresConfig struct {
-	DBHost      string
-	DBPort      string
-	DBName      string
-	DBUser      string
-	DBPassword  string
+	DBHost     string
+	DBPort     string
+	DBName     string
+	DBUser     string
+	DBPassword string
+	// PostgresDSN, если задан, используется вместо отдельных параметров подключения
+	// (включая настройки TLS).
 	PostgresDSN string
+
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// StatementCacheMode - режим выполнения запросов pgx: cache_statement, cache_describe,
+	// describe_exec, exec или simple_protocol.
+	StatementCacheMode     string
+	StatementCacheCapacity int
+
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
 }
 
 type CollectorConfig struct {
 	Interval   time.Duration
 	ApiBaseURL string
+	// CoinsURL - адрес справочника монет CoinGecko, из которого заполняются метаданные валют.
+	CoinsURL string
+	// ChunkSize - максимальное количество CoinGecko ID в одном запросе.
+	ChunkSize int
+	// MaxConcurrency - сколько запросов к провайдеру выполняется одновременно.
+	MaxConcurrency int
+	// RateLimitPerMinute - бюджет запросов к провайдеру в минуту.
+	RateLimitPerMinute int
+	// Adaptive включает подстройку интервала опроса под волатильность каждого символа.
+	Adaptive    bool
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// VolatilityWindow - сколько последних лог-доходностей учитывается при оценке волатильности.
+	VolatilityWindow int
+	// VolatilityTarget - волатильность (stddev лог-доходностей за Interval), при которой
+	// эффективный интервал равен Interval.
+	VolatilityTarget float64
+	// BufferPath - файл для цен, которые не удалось записать в БД. Пустая строка отключает буфер.
+	BufferPath string
 }
 
 type Config struct {
@@ -39,20 +81,44 @@ func LoadConfig() *Config {
 	}
 	cfg := &Config{
 		App: AppConfig{
-			AppPort:  getEnv("APP_PORT", "8080"),
-			LogLevel: getEnv("LOG_LEVEL", "DEBUG"),
+			AppPort:         getEnv("APP_PORT", "8080"),
+			LogLevel:        getEnv("LOG_LEVEL", "DEBUG"),
+			PriceBatchLimit: getEnvInt("PRICE_BATCH_LIMIT", 1000),
+			ImportBatchSize: getEnvInt("IMPORT_BATCH_SIZE", 1000),
+			ImportMaxBytes:  int64(getEnvInt("IMPORT_MAX_UPLOAD_MB", 512)) << 20,
 		},
 		Postgres: PostgresConfig{
-			DBHost:      getEnv("DB_HOST", "db"),
-			DBPort:      getEnv("DB_PORT", "5432"),
-			DBName:      getEnv("DB_NAME", "asd"),
-			DBUser:      getEnv("DB_USER", "postgres"),
-			DBPassword:  getEnv("DB_PASSWORD", "supersecret"),
-			PostgresDSN: getEnv("POSTGRES_DSN", "postgres://postgres:supersecret@db:5432/asd?sslmode=disable"),
+			DBHost:                 getEnv("DB_HOST", "db"),
+			DBPort:                 getEnv("DB_PORT", "5432"),
+			DBName:                 getEnv("DB_NAME", "asd"),
+			DBUser:                 getEnv("DB_USER", "postgres"),
+			DBPassword:             getEnv("DB_PASSWORD", "supersecret"),
+			PostgresDSN:            getEnv("POSTGRES_DSN", ""),
+			MaxConns:               int32(getEnvInt("DB_MAX_CONNS", 10)),
+			MinConns:               int32(getEnvInt("DB_MIN_CONNS", 0)),
+			MaxConnLifetime:        time.Duration(getEnvInt("DB_MAX_CONN_LIFETIME_SECONDS", 3600)) * time.Second,
+			MaxConnIdleTime:        time.Duration(getEnvInt("DB_MAX_CONN_IDLE_SECONDS", 1800)) * time.Second,
+			HealthCheckPeriod:      time.Duration(getEnvInt("DB_HEALTH_CHECK_PERIOD_SECONDS", 60)) * time.Second,
+			StatementCacheMode:     getEnv("DB_STATEMENT_CACHE_MODE", "cache_statement"),
+			StatementCacheCapacity: getEnvInt("DB_STATEMENT_CACHE_CAPACITY", 512),
+			SSLMode:                getEnv("DB_SSLMODE", "disable"),
+			SSLRootCert:            getEnv("DB_SSLROOTCERT", ""),
+			SSLCert:                getEnv("DB_SSLCERT", ""),
+			SSLKey:                 getEnv("DB_SSLKEY", ""),
 		},
 		Collector: CollectorConfig{
-			Interval:   time.Duration(collectorIntervalSec) * time.Second,
-			ApiBaseURL: getEnv("COINGECKO_API_URL", "https://api.coingecko.com/api/v3/simple/price"),
+			Interval:           time.Duration(collectorIntervalSec) * time.Second,
+			ApiBaseURL:         getEnv("COINGECKO_API_URL", "https://api.coingecko.com/api/v3/simple/price"),
+			CoinsURL:           getEnv("COINGECKO_COINS_URL", "https://api.coingecko.com/api/v3/coins"),
+			ChunkSize:          getEnvInt("COLLECTOR_CHUNK_SIZE", 250),
+			MaxConcurrency:     getEnvInt("COLLECTOR_MAX_CONCURRENCY", 4),
+			RateLimitPerMinute: getEnvInt("COLLECTOR_RATE_LIMIT_PER_MINUTE", 30),
+			Adaptive:           getEnvBool("COLLECTOR_ADAPTIVE", false),
+			MinInterval:        time.Duration(getEnvInt("COLLECTOR_MIN_INTERVAL_SECONDS", 10)) * time.Second,
+			MaxInterval:        time.Duration(getEnvInt("COLLECTOR_MAX_INTERVAL_SECONDS", 300)) * time.Second,
+			VolatilityWindow:   getEnvInt("COLLECTOR_VOLATILITY_WINDOW", 20),
+			VolatilityTarget:   getEnvFloat("COLLECTOR_VOLATILITY_TARGET", 0.001),
+			BufferPath:         getEnv("COLLECTOR_BUFFER_PATH", "data/price_buffer.ndjson"),
 		},
 	}
 	return cfg
@@ -64,3 +130,28 @@ func getEnv(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+// getEnvInt читает целое число из окружения; при ошибке разбора возвращает значение по умолчанию.
+func getEnvInt(key string, defaultVal int) int {
+	val, err := strconv.Atoi(getEnv(key, strconv.Itoa(defaultVal)))
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+func getEnvBool(key string, defaultVal bool) bool {
+	val, err := strconv.ParseBool(getEnv(key, strconv.FormatBool(defaultVal)))
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+func getEnvFloat(key string, defaultVal float64) float64 {
+	val, err := strconv.ParseFloat(getEnv(key, strconv.FormatFloat(defaultVal, 'f', -1, 64)), 64)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
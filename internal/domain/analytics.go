@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ResampleRequest - параметры выравнивания рядов цен на регулярную сетку.
+type ResampleRequest struct {
+	Symbols []string
+	From    time.Time
+	To      time.Time
+	Step    string
+	Fill    string
+}
+
+// SeriesPoint - значение ряда в точке сетки. Valid=false - значения нет,
+// Observed=false - значение получено заполнением пропуска.
+type SeriesPoint struct {
+	Time     time.Time
+	Value    decimal.Decimal
+	Valid    bool
+	Observed bool
+}
+
+// Series - ряд значений одной валюты на общей сетке.
+type Series struct {
+	Symbol string
+	Points []SeriesPoint
+}
+
+// StatsRequest - окно для описательной статистики по истории цен. Границы включительные.
+type StatsRequest struct {
+	Symbol string
+	From   time.Time
+	To     time.Time
+}
+
+// PriceStats - описательная статистика цен валюты в окне. Поля-указатели равны nil,
+// если в окне недостаточно сэмплов (StdDev и Volatility требуют минимум двух).
+type PriceStats struct {
+	Symbol      string
+	From        time.Time
+	To          time.Time
+	Count       int64
+	FirstSample *time.Time
+	LastSample  *time.Time
+	Min         *PriceSample
+	Max         *PriceSample
+	Mean        *decimal.Decimal
+	Median      *decimal.Decimal
+	StdDev      *decimal.Decimal
+	// LogReturnsSumSq - сумма квадратов логарифмических доходностей между соседними сэмплами.
+	LogReturnsSumSq *decimal.Decimal
+	// Volatility - реализованная волатильность, приведённая к году.
+	Volatility *decimal.Decimal
+}
+
+// PerformanceRequest - валюты (пусто - все отслеживаемые) и окна изменения цены вида 1h, 7d, ytd.
+type PerformanceRequest struct {
+	Symbols []string
+	Windows []string
+}
+
+// PerformanceAnchors - сэмплы, от которых считается изменение цены валюты: последний, самый ранний
+// в истории и для каждого начала окна - последний сэмпл не позже него (nil, если история начинается позже).
+type PerformanceAnchors struct {
+	Symbol   string
+	Latest   PriceSample
+	Earliest PriceSample
+	Bases    []*PriceSample
+}
+
+// WindowChange - изменение цены за окно. HistoryIncomplete=true - начало окна раньше доступной
+// истории, и изменение посчитано от самого раннего сэмпла. ChangePercent равен nil при нулевой базе.
+type WindowChange struct {
+	Window            string
+	Start             time.Time
+	Base              PriceSample
+	Change            decimal.Decimal
+	ChangePercent     *decimal.Decimal
+	HistoryIncomplete bool
+}
+
+// Performance - изменения цены валюты по всем запрошенным окнам.
+type Performance struct {
+	Symbol  string
+	Latest  PriceSample
+	Windows []WindowChange
+}
+
+// CorrelationRequest - параметры матрицы корреляций лог-доходностей на общей сетке.
+type CorrelationRequest struct {
+	Symbols  []string
+	From     time.Time
+	To       time.Time
+	Interval string
+	Spearman bool
+}
+
+// CorrelationMatrix - матрицы корреляций в порядке Symbols. Элемент равен nil, если пересечение
+// рядов слишком мало или один из них постоянен. Observations - число общих доходностей пары.
+type CorrelationMatrix struct {
+	Symbols      []string
+	From         time.Time
+	To           time.Time
+	Interval     time.Duration
+	Pearson      [][]*float64
+	Spearman     [][]*float64
+	Observations [][]int
+}
+
+// IndicatorRequest - технический индикатор по свечам валюты. Нулевые параметры - значения
+// по умолчанию для выбранного индикатора.
+type IndicatorRequest struct {
+	Symbol   string
+	Type     string
+	Interval string
+	From     time.Time
+	To       time.Time
+	Period   int
+	Fast     int
+	Slow     int
+	Signal   int
+	K        float64
+}
+
+// IndicatorPoint - значения всех линий индикатора на начало свечи; nil - период прогрева
+// или нет данных.
+type IndicatorPoint struct {
+	Time   time.Time
+	Values []*float64
+}
+
+// IndicatorSeries - ряд индикатора. Values в точках идут в порядке Lines.
+type IndicatorSeries struct {
+	Symbol   string
+	Type     string
+	Interval string
+	Params   map[string]float64
+	Lines    []string
+	Points   []IndicatorPoint
+}
+
+// DrawdownRequest - окно анализа просадки. Interval (1m, 5m, 1h, 1d) считает по закрытиям
+// свечей вместо отдельных сэмплов.
+type DrawdownRequest struct {
+	Symbol   string
+	From     time.Time
+	To       time.Time
+	Interval string
+}
+
+// UnderwaterPoint - просадка цены от предшествующего максимума (доля, <= 0).
+type UnderwaterPoint struct {
+	Time     time.Time
+	Price    decimal.Decimal
+	Drawdown decimal.Decimal
+}
+
+// DrawdownReport - максимальная просадка в окне: пик перед ней, самая глубокая точка,
+// момент восстановления до уровня пика (nil, если не восстановилась) и весь ряд просадок.
+type DrawdownReport struct {
+	Symbol      string
+	From        time.Time
+	To          time.Time
+	MaxDrawdown decimal.Decimal
+	Peak        *PriceSample
+	Trough      *PriceSample
+	Recovery    *PriceSample
+	Underwater  []UnderwaterPoint
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// SymbolSchedule - текущий режим опроса конкретной валюты.
+type SymbolSchedule struct {
+	Symbol     string
+	Interval   time.Duration
+	Volatility float64
+	NextDue    time.Time
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ConversionRequest - пересчёт Amount монет From в To по курсу на момент Timestamp.
+// Нулевой Timestamp - текущий момент.
+type ConversionRequest struct {
+	From      string
+	To        string
+	Amount    decimal.Decimal
+	Timestamp time.Time
+}
+
+// Conversion - результат пересчёта. From и To - USD-сэмплы, по которым посчитан кросс-курс.
+type Conversion struct {
+	From        PriceSample
+	To          PriceSample
+	Amount      decimal.Decimal
+	Result      decimal.Decimal
+	Rate        decimal.Decimal
+	RequestedAt time.Time
+}
@@ -1,16 +1,232 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+// Currency - отслеживаемая валюта и её метаданные. ProviderIDs - ID монеты у провайдеров
+// по имени провайдера; Enabled=false приостанавливает сбор цен. ArchivedAt задан у валют,
+// отслеживание которых прекращено: их история сохраняется, но цены больше не собираются.
 type Currency struct {
-	ID     uuid.UUID
+	ID              uuid.UUID
+	Symbol          string
+	CreatedAt       time.Time
+	Name            string
+	ProviderIDs     map[string]string
+	DisplayDecimals int
+	Enabled         bool
+	Tags            []string
+	Notes           string
+	ArchivedAt      *time.Time
+}
+
+// CurrencyPatch - частичное изменение метаданных валюты: nil-поля не меняются,
+// ProviderIDs и Tags заменяются целиком.
+type CurrencyPatch struct {
+	Name            *string
+	ProviderIDs     map[string]string
+	DisplayDecimals *int
+	Enabled         *bool
+	Tags            []string
+	Notes           *string
+}
+
+// CoinDetails - справочные данные монеты у провайдера.
+type CoinDetails struct {
+	ID     string
 	Symbol string
+	Name   string
+}
+
+// ProviderCoinGecko - имя провайдера цен, с которым работает сборщик.
+const ProviderCoinGecko = "coingecko"
+
+// CollectionError - последняя ошибка сбора цены валюты. Сбрасывается после успешного сбора.
+type CollectionError struct {
+	Message string
+	At      time.Time
+}
+
+// CurrencyStatus - отслеживаемая валюта и состояние сбора её цен.
+// FirstSample и LastSample равны nil, если истории нет; ProviderID пуст, если Mapped=false.
+type CurrencyStatus struct {
+	Currency
+	Provider    string
+	ProviderID  string
+	Mapped      bool
+	FirstSample *time.Time
+	LastSample  *time.Time
+	SampleCount *int64
+	LastError   *CollectionError
+}
+
+// CurrencyStatusSort - поле сортировки списка валют.
+type CurrencyStatusSort string
+
+const (
+	CurrencyStatusSortSymbol      CurrencyStatusSort = "symbol"
+	CurrencyStatusSortCreatedAt   CurrencyStatusSort = "created_at"
+	CurrencyStatusSortFirstSample CurrencyStatusSort = "first_sample"
+	CurrencyStatusSortLastSample  CurrencyStatusSort = "last_sample"
+	CurrencyStatusSortSampleCount CurrencyStatusSort = "sample_count"
+)
+
+// CurrencyStatusQuery - фильтры и сортировка списка валют. Пустой Symbols - все валюты,
+// nil в Mapped, Failing и Archived - без фильтра по этому признаку.
+type CurrencyStatusQuery struct {
+	Symbols    []string
+	Mapped     *bool
+	Failing    *bool
+	Archived   *bool
+	WithCounts bool
+	Sort       CurrencyStatusSort
+	Order      SortOrder
 }
 
 type Price struct {
 	Price     decimal.Decimal
 	Timestamp int64
 }
+
+// PriceSample - одна собранная цена валюты в момент времени. ID - идентификатор записи в price_history,
+// заполняется только при чтении истории и служит тай-брейкером курсора для одинаковых timestamp.
+type PriceSample struct {
+	ID        int64           `json:"-"`
+	Symbol    string          `json:"symbol"`
+	Price     decimal.Decimal `json:"price"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// PriceMode - какой сэмпл относительно запрошенного момента считается подходящим.
+type PriceMode string
+
+const (
+	PriceModeNearest PriceMode = "nearest" // ближайший по времени в любую сторону
+	PriceModeBefore  PriceMode = "before"  // последний не позже момента
+	PriceModeAfter   PriceMode = "after"   // первый не раньше момента
+	// PriceModeInterpolate - линейная интерполяция между последним сэмплом до момента и первым после.
+	PriceModeInterpolate PriceMode = "interpolate"
+)
+
+// PriceAtRequest - запрос цены валюты на момент времени.
+// MaxDistance = 0 - без ограничения на удалённость сэмпла.
+type PriceAtRequest struct {
+	Symbol      string
+	Timestamp   time.Time
+	Mode        PriceMode
+	MaxDistance time.Duration
+}
+
+// PriceQuote - цена на запрошенный момент. Для режима interpolate Before и After - сэмплы,
+// между которыми получена цена; Interpolated=false, если сэмпл совпал с моментом точно.
+type PriceQuote struct {
+	Price        decimal.Decimal
+	Timestamp    time.Time
+	Interpolated bool
+	Before       *PriceSample
+	After        *PriceSample
+}
+
+// SortOrder - направление сортировки по времени.
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// PriceRangeQuery - параметры выборки истории цен. Нулевые From/To означают отсутствие границы,
+// After/AfterID - позицию курсора: в выборку попадут только записи строго после пары (After, AfterID)
+// в порядке Order.
+type PriceRangeQuery struct {
+	Symbol  string
+	From    time.Time
+	To      time.Time
+	Order   SortOrder
+	Limit   int
+	After   time.Time
+	AfterID int64
+}
+
+// BucketQuery - сетка агрегации истории: ячейки (From+(k-1)*Step, From+k*Step] для k >= 1, не дальше To.
+type BucketQuery struct {
+	Symbol string
+	From   time.Time
+	To     time.Time
+	Step   time.Duration
+}
+
+// PriceBucket - первый и последний сэмплы непустой ячейки сетки.
+type PriceBucket struct {
+	First PriceSample
+	Last  PriceSample
+}
+
+// PricePage - страница истории цен и курсор на следующую страницу.
+type PricePage struct {
+	Samples    []PriceSample
+	NextCursor string
+}
+
+// CandleRequest - параметры запроса свечей в том виде, в каком их передаёт клиент.
+type CandleRequest struct {
+	Symbol       string
+	Interval     string
+	From         time.Time
+	To           time.Time
+	Timezone     string
+	IncludeEmpty bool
+}
+
+// CandleQuery - проверенные параметры агрегации свечей для репозитория.
+// Суточные бакеты выравниваются по местному времени Location, более короткие - по абсолютному
+// времени со сдвигом Location.
+type CandleQuery struct {
+	Symbol   string
+	Interval time.Duration
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+}
+
+// Candle - OHLC-свеча. Empty отмечает бакет без единого сэмпла.
+type Candle struct {
+	Start time.Time
+	Open  decimal.Decimal
+	High  decimal.Decimal
+	Low   decimal.Decimal
+	Close decimal.Decimal
+	Count int
+	Empty bool
+}
+
+// LatestPrice - последний сэмпл валюты и изменение за 24 часа. Поля изменения равны nil,
+// если сэмпла 24 часа назад нет.
+type LatestPrice struct {
+	Symbol           string
+	Price            decimal.Decimal
+	Timestamp        time.Time
+	Price24hAgo      *decimal.Decimal
+	Change24h        *decimal.Decimal
+	ChangePercent24h *decimal.Decimal
+}
+
+// PriceLookup - один запрос цены в пакетном поиске.
+type PriceLookup struct {
+	Symbol    string
+	Timestamp time.Time
+}
+
+// PriceLookupResult - результат поиска ближайшей цены для одного элемента пакета.
+// Tracked=false - валюта не отслеживается, Found=false - по ней нет истории.
+type PriceLookupResult struct {
+	Symbol      string
+	RequestedAt time.Time
+	Tracked     bool
+	Found       bool
+	Price       decimal.Decimal
+	Timestamp   time.Time
+}
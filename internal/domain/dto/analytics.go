@@ -0,0 +1,108 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// SeriesPointResponse - значение ряда в точке сетки. Value равно null, если заполнить
+// пропуск не удалось; observed=false означает, что значение получено заполнением.
+type SeriesPointResponse struct {
+	Timestamp int64            `json:"timestamp"`
+	Value     *decimal.Decimal `json:"value"`
+	Observed  bool             `json:"observed"`
+}
+
+// SeriesResponse - ряд одной валюты.
+type SeriesResponse struct {
+	Symbol string                `json:"symbol"`
+	Points []SeriesPointResponse `json:"points"`
+}
+
+// ResampleResponse - DTO для ответа GET /prices/resample.
+type ResampleResponse struct {
+	Step   string           `json:"step"`
+	Fill   string           `json:"fill"`
+	Series []SeriesResponse `json:"series"`
+}
+
+// PriceStatsResponse - DTO для ответа GET /currency/{symbol}/stats. Поля равны null,
+// если в окне недостаточно сэмплов.
+type PriceStatsResponse struct {
+	Symbol      string           `json:"symbol"`
+	From        int64            `json:"from"`
+	To          int64            `json:"to"`
+	Count       int64            `json:"count"`
+	FirstSample *int64           `json:"first_sample"`
+	LastSample  *int64           `json:"last_sample"`
+	Min         *PricePoint      `json:"min"`
+	Max         *PricePoint      `json:"max"`
+	Mean        *decimal.Decimal `json:"mean"`
+	Median      *decimal.Decimal `json:"median"`
+	StdDev      *decimal.Decimal `json:"stddev"`
+	Volatility  *decimal.Decimal `json:"volatility_annualized"`
+}
+
+// WindowChangeResponse - изменение цены за одно окно.
+type WindowChangeResponse struct {
+	Window            string           `json:"window"`
+	Start             int64            `json:"start"`
+	Base              PricePoint       `json:"base"`
+	Change            decimal.Decimal  `json:"change"`
+	ChangePercent     *decimal.Decimal `json:"change_percent"`
+	HistoryIncomplete bool             `json:"history_incomplete"`
+}
+
+// PerformanceResponse - DTO для элемента ответа GET /prices/performance.
+type PerformanceResponse struct {
+	Symbol    string                 `json:"symbol"`
+	Price     decimal.Decimal        `json:"price"`
+	Timestamp int64                  `json:"timestamp"`
+	Windows   []WindowChangeResponse `json:"windows"`
+}
+
+// CorrelationResponse - DTO для ответа GET /analytics/correlation. Строки и столбцы матриц
+// идут в порядке symbols; null - корреляция не определена.
+type CorrelationResponse struct {
+	Symbols      []string     `json:"symbols"`
+	From         int64        `json:"from"`
+	To           int64        `json:"to"`
+	Interval     string       `json:"interval"`
+	Pearson      [][]*float64 `json:"pearson"`
+	Spearman     [][]*float64 `json:"spearman,omitempty"`
+	Observations [][]int      `json:"observations"`
+}
+
+// IndicatorPointResponse - значения линий индикатора на начало свечи; null - период прогрева.
+type IndicatorPointResponse struct {
+	Timestamp int64               `json:"timestamp"`
+	Values    map[string]*float64 `json:"values"`
+}
+
+// IndicatorResponse - DTO для ответа GET /currency/{symbol}/indicators.
+type IndicatorResponse struct {
+	Symbol   string                   `json:"symbol"`
+	Type     string                   `json:"type"`
+	Interval string                   `json:"interval"`
+	Params   map[string]float64       `json:"params"`
+	Lines    []string                 `json:"lines"`
+	Points   []IndicatorPointResponse `json:"points"`
+}
+
+// UnderwaterPointResponse - просадка от предшествующего максимума в момент сэмпла.
+type UnderwaterPointResponse struct {
+	Timestamp int64           `json:"timestamp"`
+	Price     decimal.Decimal `json:"price"`
+	Drawdown  decimal.Decimal `json:"drawdown"`
+}
+
+// DrawdownResponse - DTO для ответа GET /currency/{symbol}/drawdown. Просадки - доли (-0.25 = -25%);
+// recovery равен null, если цена не вернулась к уровню пика.
+type DrawdownResponse struct {
+	Symbol      string                    `json:"symbol"`
+	From        int64                     `json:"from"`
+	To          int64                     `json:"to"`
+	Interval    string                    `json:"interval,omitempty"`
+	MaxDrawdown decimal.Decimal           `json:"max_drawdown"`
+	Peak        *PricePoint               `json:"peak"`
+	Trough      *PricePoint               `json:"trough"`
+	Recovery    *PricePoint               `json:"recovery"`
+	Underwater  []UnderwaterPointResponse `json:"underwater"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+// SymbolScheduleResponse - DTO с эффективным интервалом опроса валюты.
+type SymbolScheduleResponse struct {
+	Symbol          string  `json:"symbol"`
+	IntervalSeconds float64 `json:"interval_seconds"`
+	Volatility      float64 `json:"volatility"`
+	NextDue         int64   `json:"next_due,omitempty"`
+}
+
+// CollectorScheduleResponse - DTO для ответа GET /admin/collector/intervals.
+type CollectorScheduleResponse struct {
+	Mode    string                   `json:"mode"`
+	Symbols []SymbolScheduleResponse `json:"symbols"`
+}
+
+// BufferStatusResponse - DTO для ответа GET /admin/buffer.
+type BufferStatusResponse struct {
+	Enabled          bool    `json:"enabled"`
+	Depth            int     `json:"depth"`
+	OldestTimestamp  int64   `json:"oldest_timestamp,omitempty"`
+	OldestAgeSeconds float64 `json:"oldest_age_seconds,omitempty"`
+	// DeadLettered - сэмплы, отвергнутые базой и перенесённые в файл недоставленных.
+	DeadLettered int `json:"dead_lettered"`
+}
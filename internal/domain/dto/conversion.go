@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// ConvertRequest - DTO для запроса POST /convert.
+type ConvertRequest struct {
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+	Amount decimal.Decimal `json:"amount"`
+	// Timestamp - момент пересчёта (unix), 0 - сейчас.
+	Timestamp int64 `json:"timestamp,omitempty"`
+}
+
+// ConversionLeg - USD-цена одной из монет, использованная для кросс-курса.
+type ConversionLeg struct {
+	Symbol    string          `json:"symbol"`
+	PriceUSD  decimal.Decimal `json:"price_usd"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// ConvertResponse - DTO для ответа POST /convert.
+type ConvertResponse struct {
+	From               ConversionLeg   `json:"from"`
+	To                 ConversionLeg   `json:"to"`
+	Amount             decimal.Decimal `json:"amount"`
+	Result             decimal.Decimal `json:"result"`
+	Rate               decimal.Decimal `json:"rate"`
+	RequestedTimestamp int64           `json:"requested_timestamp"`
+}
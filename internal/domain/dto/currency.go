@@ -8,25 +8,92 @@ type AddCurrencyRequest struct {
 	Symbol string `json:"symbol"`
 }
 
-// RemoveCurrencyRequest - DTO для запроса на удаление валюты.
+// RemoveCurrencyRequest - DTO для запроса на прекращение отслеживания валюты.
 // POST /currency/remove
 type RemoveCurrencyRequest struct {
 	Symbol string `json:"symbol"`
 }
 
+// CurrencyResponse - отслеживаемая валюта и её метаданные.
+// POST /api/v1/currencies, GET и PATCH /api/v1/currencies/{symbol}
+type CurrencyResponse struct {
+	Symbol          string            `json:"symbol"`
+	CreatedAt       int64             `json:"created_at"`
+	Name            string            `json:"name"`
+	ProviderIDs     map[string]string `json:"provider_ids"`
+	DisplayDecimals int               `json:"display_decimals"`
+	Enabled         bool              `json:"enabled"`
+	Tags            []string          `json:"tags"`
+	Notes           string            `json:"notes"`
+	// ArchivedAt - время прекращения отслеживания, null у активных валют.
+	ArchivedAt *int64 `json:"archived_at"`
+}
+
+// PurgeCurrencyRequest - DTO для безвозвратного удаления архивной валюты.
+// Confirm должен повторять символ валюты.
+// POST /api/v1/currencies/{symbol}/purge
+type PurgeCurrencyRequest struct {
+	Confirm string `json:"confirm"`
+}
+
+// PurgeCurrencyResponse - результат удаления валюты и её истории.
+type PurgeCurrencyResponse struct {
+	Symbol         string `json:"symbol"`
+	DeletedSamples int64  `json:"deleted_samples"`
+}
+
+// UpdateCurrencyRequest - DTO для изменения метаданных валюты. Отсутствующие поля не меняются,
+// provider_ids и tags заменяются целиком.
+// PATCH /api/v1/currencies/{symbol}
+type UpdateCurrencyRequest struct {
+	Name            *string           `json:"name,omitempty"`
+	ProviderIDs     map[string]string `json:"provider_ids,omitempty"`
+	DisplayDecimals *int              `json:"display_decimals,omitempty"`
+	Enabled         *bool             `json:"enabled,omitempty"`
+	Tags            []string          `json:"tags,omitempty"`
+	Notes           *string           `json:"notes,omitempty"`
+}
+
+// CollectionErrorResponse - последняя ошибка сбора цены валюты.
+type CollectionErrorResponse struct {
+	Message string `json:"message"`
+	At      int64  `json:"at"`
+}
+
+// CurrencyStatusResponse - отслеживаемая валюта и состояние сбора её цен.
+// GET /api/v1/currencies
+type CurrencyStatusResponse struct {
+	CurrencyResponse
+	Provider    string                   `json:"provider"`
+	ProviderID  string                   `json:"provider_id,omitempty"`
+	Mapped      bool                     `json:"mapped"`
+	FirstSample *int64                   `json:"first_sample"`
+	LastSample  *int64                   `json:"last_sample"`
+	SampleCount *int64                   `json:"sample_count,omitempty"`
+	LastError   *CollectionErrorResponse `json:"last_error"`
+}
+
 // GetPriceRequest - DTO для запроса цены.
 // GET /currency/price
 // Используем теги, чтобы связать поля с параметрами запроса или телом JSON.
 type GetPriceRequest struct {
 	Coin      string `json:"coin"`
 	Timestamp int64  `json:"timestamp"`
+	// Mode - nearest (по умолчанию), before, after или interpolate.
+	Mode string `json:"mode,omitempty"`
+	// MaxDistance - допустимая удалённость сэмпла в секундах, 0 - без ограничения.
+	MaxDistance int64 `json:"max_distance,omitempty"`
 }
 
 // PriceResponse - DTO для ответа с ценой.
+// Для interpolate в Before/After - сэмплы, между которыми интерполирована цена.
 type PriceResponse struct {
-	Symbol    string          `json:"symbol"`
-	Price     decimal.Decimal `json:"price"`
-	Timestamp int64           `json:"timestamp"`
+	Symbol       string          `json:"symbol"`
+	Price        decimal.Decimal `json:"price"`
+	Timestamp    int64           `json:"timestamp"`
+	Interpolated bool            `json:"interpolated,omitempty"`
+	Before       *PricePoint     `json:"before,omitempty"`
+	After        *PricePoint     `json:"after,omitempty"`
 }
 
 // GenericResponse - универсальный ответ для простых операций.
@@ -34,3 +101,78 @@ type GenericResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message,omitempty"`
 }
+
+// PricePoint - одна точка истории цен.
+type PricePoint struct {
+	Price     decimal.Decimal `json:"price"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// PriceHistoryResponse - DTO для ответа GET /currency/{symbol}/history.
+type PriceHistoryResponse struct {
+	Symbol     string       `json:"symbol"`
+	Order      string       `json:"order"`
+	Items      []PricePoint `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// CandleResponse - одна OHLC-свеча. У пустых свечей цены отсутствуют.
+type CandleResponse struct {
+	Start   int64            `json:"start"`
+	Open    *decimal.Decimal `json:"open,omitempty"`
+	High    *decimal.Decimal `json:"high,omitempty"`
+	Low     *decimal.Decimal `json:"low,omitempty"`
+	Close   *decimal.Decimal `json:"close,omitempty"`
+	Samples int              `json:"samples"`
+	Empty   bool             `json:"empty,omitempty"`
+}
+
+// CandlesResponse - DTO для ответа GET /currency/{symbol}/candles.
+type CandlesResponse struct {
+	Symbol   string           `json:"symbol"`
+	Interval string           `json:"interval"`
+	Timezone string           `json:"timezone"`
+	Candles  []CandleResponse `json:"candles"`
+}
+
+// LatestPriceResponse - последняя цена валюты для табло.
+type LatestPriceResponse struct {
+	Symbol           string           `json:"symbol"`
+	Price            decimal.Decimal  `json:"price"`
+	Timestamp        int64            `json:"timestamp"`
+	AgeSeconds       int64            `json:"age_seconds"`
+	Change24h        *decimal.Decimal `json:"change_24h"`
+	ChangePercent24h *decimal.Decimal `json:"change_percent_24h"`
+}
+
+// BatchPriceRequest - DTO для пакетного запроса ближайших цен.
+// POST /prices/batch
+type BatchPriceRequest struct {
+	Items []BatchPriceItem `json:"items"`
+}
+
+// BatchPriceItem - одна пара монета/время в пакетном запросе.
+type BatchPriceItem struct {
+	Coin      string `json:"coin"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// BatchItemError - ошибка отдельного элемента пакета.
+type BatchItemError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// BatchPriceResult - результат одного элемента пакета: цена или ошибка.
+type BatchPriceResult struct {
+	Coin               string           `json:"coin"`
+	RequestedTimestamp int64            `json:"requested_timestamp"`
+	Price              *decimal.Decimal `json:"price,omitempty"`
+	Timestamp          int64            `json:"timestamp,omitempty"`
+	Error              *BatchItemError  `json:"error,omitempty"`
+}
+
+// BatchPriceResponse - DTO для ответа POST /prices/batch. Порядок результатов совпадает с запросом.
+type BatchPriceResponse struct {
+	Results []BatchPriceResult `json:"results"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+// HealthResponse - DTO для ответа GET /health.
+type HealthResponse struct {
+	Status    string `json:"status"`
+	Database  string `json:"database"`
+	Reason    string `json:"reason,omitempty"`
+	Since     int64  `json:"since"`
+	LastCheck int64  `json:"last_check,omitempty"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+// ImportRejectionResponse - строка файла, не прошедшая проверку.
+type ImportRejectionResponse struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// ImportReportResponse - DTO для ответа POST /import/prices. Списки строк ограничены
+// 1000 элементами; truncated=true - часть номеров строк в них не попала, счётчики точные.
+type ImportReportResponse struct {
+	Total      int                       `json:"total"`
+	Accepted   int                       `json:"accepted"`
+	Duplicate  int                       `json:"duplicate"`
+	Rejected   int                       `json:"rejected"`
+	Duplicates []int                     `json:"duplicates"`
+	Rejections []ImportRejectionResponse `json:"rejections"`
+	Truncated  bool                      `json:"truncated,omitempty"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// ExportFormat - формат выгрузки истории цен.
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// ExportLayout - раскладка выгрузки.
+type ExportLayout string
+
+const (
+	ExportLayoutLong ExportLayout = "long" // строка на сэмпл: timestamp, symbol, price
+	ExportLayoutWide ExportLayout = "wide" // строка на момент времени, колонка на валюту
+)
+
+// ExportRequest - запрос выгрузки истории. Пустой Symbols - все отслеживаемые валюты,
+// нулевые From/To - без ограничения с этой стороны.
+type ExportRequest struct {
+	Symbols []string
+	From    time.Time
+	To      time.Time
+	Format  ExportFormat
+	Layout  ExportLayout
+}
+
+// ExportQuery - выборка истории для выгрузки: сэмплы валют Symbols в [From, To]
+// по возрастанию времени.
+type ExportQuery struct {
+	Symbols []string
+	From    time.Time
+	To      time.Time
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// HealthStatus - состояние подключения к базе данных.
+type HealthStatus struct {
+	Healthy   bool
+	Reason    string
+	Since     time.Time
+	LastCheck time.Time
+}
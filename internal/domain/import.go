@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ImportRow - проверенная строка файла импорта. Line - номер строки в файле (с 1).
+type ImportRow struct {
+	Line      int
+	Symbol    string
+	Price     decimal.Decimal
+	Timestamp time.Time
+}
+
+// ImportRejection - строка, не прошедшая проверку.
+type ImportRejection struct {
+	Line   int
+	Reason string
+}
+
+// ImportReport - итог импорта. Дубликат - строка, чья пара (валюта, время) уже есть в истории
+// или раньше в том же файле. Счётчики точные, а списки строк ограничены по длине;
+// Truncated означает, что часть номеров строк в них не попала.
+type ImportReport struct {
+	Total      int
+	Accepted   int
+	Duplicate  int
+	Rejected   int
+	Duplicates []int
+	Rejections []ImportRejection
+	Truncated  bool
+}
@@ -0,0 +1,186 @@
+// Package export пишет поток сэмплов истории цен в CSV или NDJSON, не накапливая его в памяти.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+)
+
+const bufferSize = 64 << 10
+
+// Writer принимает сэмплы, отсортированные по времени, и пишет их в выбранной раскладке.
+// Close дописывает незавершённую строку и сбрасывает буфер.
+type Writer interface {
+	Write(sample domain.PriceSample) error
+	Close() error
+}
+
+// NewWriter пишет заголовок и возвращает Writer. symbols задаёт колонки раскладки wide.
+func NewWriter(w io.Writer, format domain.ExportFormat, layout domain.ExportLayout, symbols []string) (Writer, error) {
+	var enc encoder
+	switch format {
+	case domain.ExportFormatCSV:
+		enc = &csvEncoder{w: csv.NewWriter(bufio.NewWriterSize(w, bufferSize))}
+	case domain.ExportFormatNDJSON:
+		enc = &ndjsonEncoder{w: bufio.NewWriterSize(w, bufferSize)}
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+
+	switch layout {
+	case domain.ExportLayoutLong:
+		return &longWriter{enc: enc}, enc.header([]string{"timestamp", "symbol", "price"})
+	case domain.ExportLayoutWide:
+		ww := &wideWriter{enc: enc, columns: make(map[string]int, len(symbols)), values: make([]*string, len(symbols))}
+		for i, symbol := range symbols {
+			ww.columns[symbol] = i
+		}
+		return ww, enc.header(append([]string{"timestamp"}, symbols...))
+	default:
+		return nil, fmt.Errorf("unknown export layout %q", layout)
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+type longWriter struct {
+	enc encoder
+}
+
+func (lw *longWriter) Write(sample domain.PriceSample) error {
+	ts, price := formatTime(sample.Timestamp), sample.Price.String()
+	return lw.enc.record([]*string{&ts, &sample.Symbol, &price})
+}
+
+func (lw *longWriter) Close() error {
+	return lw.enc.flush()
+}
+
+// wideWriter собирает сэмплы с одинаковым временем в одну строку; валюты без сэмпла
+// в этот момент остаются пустыми. В памяти держится только текущая строка.
+type wideWriter struct {
+	enc     encoder
+	columns map[string]int
+	current time.Time
+	values  []*string
+	pending bool
+}
+
+func (ww *wideWriter) Write(sample domain.PriceSample) error {
+	col, ok := ww.columns[sample.Symbol]
+	if !ok {
+		return nil
+	}
+	if ww.pending && !sample.Timestamp.Equal(ww.current) {
+		if err := ww.emit(); err != nil {
+			return err
+		}
+	}
+	price := sample.Price.String()
+	ww.current, ww.values[col], ww.pending = sample.Timestamp, &price, true
+	return nil
+}
+
+func (ww *wideWriter) emit() error {
+	ts := formatTime(ww.current)
+	if err := ww.enc.record(append([]*string{&ts}, ww.values...)); err != nil {
+		return err
+	}
+	clear(ww.values)
+	ww.pending = false
+	return nil
+}
+
+func (ww *wideWriter) Close() error {
+	if ww.pending {
+		if err := ww.emit(); err != nil {
+			return err
+		}
+	}
+	return ww.enc.flush()
+}
+
+// encoder пишет строки из колонок, объявленных в header; nil - пустое значение.
+type encoder interface {
+	header(columns []string) error
+	record(values []*string) error
+	flush() error
+}
+
+type csvEncoder struct {
+	w   *csv.Writer
+	row []string
+}
+
+func (e *csvEncoder) header(columns []string) error {
+	e.row = make([]string, len(columns))
+	return e.w.Write(columns)
+}
+
+func (e *csvEncoder) record(values []*string) error {
+	for i, v := range values {
+		e.row[i] = ""
+		if v != nil {
+			e.row[i] = *v
+		}
+	}
+	return e.w.Write(e.row)
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonEncoder пишет каждую строку отдельным JSON-объектом с ключами в порядке колонок.
+type ndjsonEncoder struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+func (e *ndjsonEncoder) header(columns []string) error {
+	e.keys = make([][]byte, len(columns))
+	for i, c := range columns {
+		key, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		e.keys[i] = key
+	}
+	return nil
+}
+
+func (e *ndjsonEncoder) record(values []*string) error {
+	e.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		e.w.Write(e.keys[i])
+		e.w.WriteByte(':')
+		if v == nil {
+			e.w.WriteString("null")
+			continue
+		}
+		value, err := json.Marshal(*v)
+		if err != nil {
+			return err
+		}
+		e.w.Write(value)
+	}
+	e.w.WriteByte('}')
+	_, err := e.w.WriteString("\n")
+	return err
+}
+
+func (e *ndjsonEncoder) flush() error {
+	return e.w.Flush()
+}
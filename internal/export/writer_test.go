@@ -0,0 +1,87 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+	samples := []domain.PriceSample{
+		{Symbol: "BTC", Price: decimal.RequireFromString("42000.5"), Timestamp: t0},
+		{Symbol: "ETH", Price: decimal.RequireFromString("2200"), Timestamp: t0},
+		{Symbol: "ETH", Price: decimal.RequireFromString("2201.25"), Timestamp: t1},
+	}
+
+	tests := []struct {
+		name   string
+		format domain.ExportFormat
+		layout domain.ExportLayout
+		want   string
+	}{
+		{
+			name:   "csv_long",
+			format: domain.ExportFormatCSV,
+			layout: domain.ExportLayoutLong,
+			want: "timestamp,symbol,price\n" +
+				"2024-01-01T00:00:00Z,BTC,42000.5\n" +
+				"2024-01-01T00:00:00Z,ETH,2200\n" +
+				"2024-01-01T00:01:00Z,ETH,2201.25\n",
+		},
+		{
+			name:   "csv_wide",
+			format: domain.ExportFormatCSV,
+			layout: domain.ExportLayoutWide,
+			want: "timestamp,BTC,ETH\n" +
+				"2024-01-01T00:00:00Z,42000.5,2200\n" +
+				"2024-01-01T00:01:00Z,,2201.25\n",
+		},
+		{
+			name:   "ndjson_long",
+			format: domain.ExportFormatNDJSON,
+			layout: domain.ExportLayoutLong,
+			want: `{"timestamp":"2024-01-01T00:00:00Z","symbol":"BTC","price":"42000.5"}` + "\n" +
+				`{"timestamp":"2024-01-01T00:00:00Z","symbol":"ETH","price":"2200"}` + "\n" +
+				`{"timestamp":"2024-01-01T00:01:00Z","symbol":"ETH","price":"2201.25"}` + "\n",
+		},
+		{
+			name:   "ndjson_wide",
+			format: domain.ExportFormatNDJSON,
+			layout: domain.ExportLayoutWide,
+			want: `{"timestamp":"2024-01-01T00:00:00Z","BTC":"42000.5","ETH":"2200"}` + "\n" +
+				`{"timestamp":"2024-01-01T00:01:00Z","BTC":null,"ETH":"2201.25"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, tt.format, tt.layout, []string{"BTC", "ETH"})
+			require.NoError(t, err)
+			for _, s := range samples {
+				require.NoError(t, w.Write(s))
+			}
+			require.NoError(t, w.Close())
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	t.Run("empty_wide", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, domain.ExportFormatCSV, domain.ExportLayoutWide, []string{"BTC"})
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, "timestamp,BTC\n", buf.String())
+	})
+
+	t.Run("unknown_format", func(t *testing.T) {
+		_, err := NewWriter(&bytes.Buffer{}, "xml", domain.ExportLayoutLong, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/domain/dto"
+	"github.com/adal4ik/crypto-service/internal/service"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/adal4ik/crypto-service/pkg/response"
+	"github.com/go-chi/chi/v5"
+)
+
+type AnalyticsHandler struct {
+	service     service.AnalyticsServiceInterface
+	logger      logger.Logger
+	handleError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func NewAnalyticsHandler(
+	s service.AnalyticsServiceInterface,
+	l logger.Logger,
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error),
+) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service:     s,
+		logger:      l,
+		handleError: errorHandler,
+	}
+}
+
+// @Summary      Resample price series
+// @Description  Returns one value per grid point for each requested currency. Grid points without a sample in (t-step, t] are filled with the chosen strategy and flagged as not observed.
+// @Tags         analytics
+// @Produce      json
+// @Param        symbols  query  string  true   "Comma-separated currency symbols"
+// @Param        from     query  string  true   "Grid start (unix seconds or RFC3339)"
+// @Param        to       query  string  true   "Grid end (unix seconds or RFC3339)"
+// @Param        step     query  string  true   "Grid step, e.g. 15m, 1h, 1d"
+// @Param        fill     query  string  false  "previous (default), linear or null"
+// @Success      200  {object}  response.SuccessResponse{data=dto.ResampleResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/prices/resample [get]
+func (h *AnalyticsHandler) Resample(w http.ResponseWriter, r *http.Request) {
+	from, appErr := parseTimeParam(r, "from")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	to, appErr := parseTimeParam(r, "to")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	req := domain.ResampleRequest{
+		Symbols: parseListParam(r, "symbols"),
+		From:    from,
+		To:      to,
+		Step:    r.URL.Query().Get("step"),
+		Fill:    r.URL.Query().Get("fill"),
+	}
+
+	series, appErr := h.service.Resample(r.Context(), req)
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.ResampleResponse{
+		Step:   req.Step,
+		Fill:   req.Fill,
+		Series: make([]dto.SeriesResponse, 0, len(series)),
+	}
+	if respDTO.Fill == "" {
+		respDTO.Fill = "previous"
+	}
+	for _, s := range series {
+		respDTO.Series = append(respDTO.Series, toSeriesResponse(s))
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+func toSeriesResponse(s domain.Series) dto.SeriesResponse {
+	item := dto.SeriesResponse{Symbol: s.Symbol, Points: make([]dto.SeriesPointResponse, 0, len(s.Points))}
+	for _, p := range s.Points {
+		point := dto.SeriesPointResponse{Timestamp: p.Time.Unix(), Observed: p.Observed}
+		if p.Valid {
+			point.Value = &p.Value
+		}
+		item.Points = append(item.Points, point)
+	}
+	return item
+}
+
+// @Summary      Price statistics
+// @Description  Returns min and max with their timestamps, mean, median, sample standard deviation, annualized realized volatility (from log returns) and sample count within a time window.
+// @Tags         analytics
+// @Produce      json
+// @Param        symbol  path   string  true   "Currency symbol"
+// @Param        from    query  string  false  "Window start (unix seconds or RFC3339), default 24h before 'to'"
+// @Param        to      query  string  false  "Window end (unix seconds or RFC3339), default now"
+// @Success      200  {object}  response.SuccessResponse{data=dto.PriceStatsResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol}/stats [get]
+func (h *AnalyticsHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	from, appErr := parseTimeParam(r, "from")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	to, appErr := parseTimeParam(r, "to")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	stats, appErr := h.service.Stats(r.Context(), domain.StatsRequest{Symbol: chi.URLParam(r, "symbol"), From: from, To: to})
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.PriceStatsResponse{
+		Symbol:      stats.Symbol,
+		From:        stats.From.Unix(),
+		To:          stats.To.Unix(),
+		Count:       stats.Count,
+		FirstSample: unixOrNil(stats.FirstSample),
+		LastSample:  unixOrNil(stats.LastSample),
+		Min:         toPricePoint(stats.Min),
+		Max:         toPricePoint(stats.Max),
+		Mean:        stats.Mean,
+		Median:      stats.Median,
+		StdDev:      stats.StdDev,
+		Volatility:  stats.Volatility,
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// formatStep печатает шаг сетки в том же виде, в каком его принимает parseStep: "1d", "1h", "15m".
+func formatStep(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func unixOrNil(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	ts := t.Unix()
+	return &ts
+}
+
+// @Summary      Price performance
+// @Description  Returns the absolute and percentage change of the latest price over each window for every tracked currency, or only for the given symbols. The base of a window is the last sample at or before its start; if history starts later, the earliest sample is used and history_incomplete is set.
+// @Tags         analytics
+// @Produce      json
+// @Param        symbols  query  string  false  "Comma-separated currency symbols; archived currencies are included only when listed"
+// @Param        windows  query  string  false  "Comma-separated windows, e.g. 1h,24h,7d,30d,ytd (default)"
+// @Success      200  {object}  response.SuccessResponse{data=[]dto.PerformanceResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/prices/performance [get]
+func (h *AnalyticsHandler) Performance(w http.ResponseWriter, r *http.Request) {
+	req := domain.PerformanceRequest{
+		Symbols: parseListParam(r, "symbols"),
+		Windows: parseListParam(r, "windows"),
+	}
+	performance, appErr := h.service.Performance(r.Context(), req)
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := make([]dto.PerformanceResponse, 0, len(performance))
+	for _, p := range performance {
+		item := dto.PerformanceResponse{
+			Symbol:    p.Symbol,
+			Price:     p.Latest.Price,
+			Timestamp: p.Latest.Timestamp.Unix(),
+			Windows:   make([]dto.WindowChangeResponse, 0, len(p.Windows)),
+		}
+		for _, c := range p.Windows {
+			item.Windows = append(item.Windows, dto.WindowChangeResponse{
+				Window:            c.Window,
+				Start:             c.Start.Unix(),
+				Base:              dto.PricePoint{Price: c.Base.Price, Timestamp: c.Base.Timestamp.Unix()},
+				Change:            c.Change,
+				ChangePercent:     c.ChangePercent,
+				HistoryIncomplete: c.HistoryIncomplete,
+			})
+		}
+		respDTO = append(respDTO, item)
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// @Summary      Correlation matrix
+// @Description  Aligns the selected currencies on a common grid, computes log returns between cells that both hold a real sample (gaps are not filled) and returns the Pearson (and optionally Spearman) correlation matrix with the number of overlapping returns per pair.
+// @Tags         analytics
+// @Produce      json
+// @Param        symbols   query  string  true   "Comma-separated currency symbols (at least two)"
+// @Param        from      query  string  false  "Range start (unix seconds or RFC3339), default 30 days before 'to'"
+// @Param        to        query  string  false  "Range end (unix seconds or RFC3339), default now"
+// @Param        interval  query  string  false  "Grid step, e.g. 15m, 1h (default), 1d"
+// @Param        spearman  query  bool    false  "Also compute Spearman rank correlation"
+// @Success      200  {object}  response.SuccessResponse{data=dto.CorrelationResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/analytics/correlation [get]
+func (h *AnalyticsHandler) Correlation(w http.ResponseWriter, r *http.Request) {
+	from, appErr := parseTimeParam(r, "from")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	to, appErr := parseTimeParam(r, "to")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	spearman, appErr := parseBoolParam(r, "spearman")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	matrix, appErr := h.service.Correlation(r.Context(), domain.CorrelationRequest{
+		Symbols:  parseListParam(r, "symbols"),
+		From:     from,
+		To:       to,
+		Interval: r.URL.Query().Get("interval"),
+		Spearman: spearman,
+	})
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.CorrelationResponse{
+		Symbols:      matrix.Symbols,
+		From:         matrix.From.Unix(),
+		To:           matrix.To.Unix(),
+		Interval:     formatStep(matrix.Interval),
+		Pearson:      matrix.Pearson,
+		Spearman:     matrix.Spearman,
+		Observations: matrix.Observations,
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// @Summary      Technical indicators
+// @Description  Computes SMA, EMA, RSI, MACD or Bollinger bands on candle closes over a range. Extra history before 'from' is used for warm-up; values that still cannot be computed are null.
+// @Tags         analytics
+// @Produce      json
+// @Param        symbol    path   string  true   "Currency symbol"
+// @Param        type      query  string  true   "sma, ema, rsi, macd or bollinger"
+// @Param        period    query  int     false  "Period (default 20, RSI 14)"
+// @Param        fast      query  int     false  "MACD fast period (default 12)"
+// @Param        slow      query  int     false  "MACD slow period (default 26)"
+// @Param        signal    query  int     false  "MACD signal period (default 9)"
+// @Param        k         query  number  false  "Bollinger band width in standard deviations (default 2)"
+// @Param        interval  query  string  false  "Candle size: 1m, 5m, 1h (default) or 1d"
+// @Param        from      query  string  false  "Range start (unix seconds or RFC3339), default 100 candles before 'to'"
+// @Param        to        query  string  false  "Range end (unix seconds or RFC3339), default now"
+// @Success      200  {object}  response.SuccessResponse{data=dto.IndicatorResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol}/indicators [get]
+func (h *AnalyticsHandler) Indicators(w http.ResponseWriter, r *http.Request) {
+	req := domain.IndicatorRequest{
+		Symbol:   chi.URLParam(r, "symbol"),
+		Type:     r.URL.Query().Get("type"),
+		Interval: r.URL.Query().Get("interval"),
+	}
+	var appErr *apperrors.AppError
+	if req.From, appErr = parseTimeParam(r, "from"); appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	if req.To, appErr = parseTimeParam(r, "to"); appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	intParams := []struct {
+		name string
+		dst  *int
+	}{{"period", &req.Period}, {"fast", &req.Fast}, {"slow", &req.Slow}, {"signal", &req.Signal}}
+	for _, p := range intParams {
+		if *p.dst, appErr = parseIntParam(r, p.name); appErr != nil {
+			h.handleError(w, r, appErr)
+			return
+		}
+	}
+	if raw := r.URL.Query().Get("k"); raw != "" {
+		k, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			h.handleError(w, r, apperrors.NewBadRequest("parameter 'k' must be a number", err))
+			return
+		}
+		req.K = k
+	}
+
+	series, appErr := h.service.Indicators(r.Context(), req)
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.IndicatorResponse{
+		Symbol:   series.Symbol,
+		Type:     series.Type,
+		Interval: series.Interval,
+		Params:   series.Params,
+		Lines:    series.Lines,
+		Points:   make([]dto.IndicatorPointResponse, 0, len(series.Points)),
+	}
+	for _, p := range series.Points {
+		point := dto.IndicatorPointResponse{Timestamp: p.Time.Unix(), Values: make(map[string]*float64, len(series.Lines))}
+		for i, line := range series.Lines {
+			point.Values[line] = p.Values[i]
+		}
+		respDTO.Points = append(respDTO.Points, point)
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// @Summary      Maximum drawdown
+// @Description  Returns the maximum peak-to-trough decline within a window, the peak and trough samples, the first sample at which the price regained the peak (null if it has not) and the full underwater series. Without 'interval' raw samples are used; with it, candle closes.
+// @Tags         analytics
+// @Produce      json
+// @Param        symbol    path   string  true   "Currency symbol"
+// @Param        from      query  string  false  "Window start (unix seconds or RFC3339), default 30 days before 'to'"
+// @Param        to        query  string  false  "Window end (unix seconds or RFC3339), default now"
+// @Param        interval  query  string  false  "Candle size: 1m, 5m, 1h or 1d; raw samples if omitted"
+// @Success      200  {object}  response.SuccessResponse{data=dto.DrawdownResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol}/drawdown [get]
+func (h *AnalyticsHandler) Drawdown(w http.ResponseWriter, r *http.Request) {
+	req := domain.DrawdownRequest{
+		Symbol:   chi.URLParam(r, "symbol"),
+		Interval: r.URL.Query().Get("interval"),
+	}
+	var appErr *apperrors.AppError
+	if req.From, appErr = parseTimeParam(r, "from"); appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	if req.To, appErr = parseTimeParam(r, "to"); appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	report, appErr := h.service.Drawdown(r.Context(), req)
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.DrawdownResponse{
+		Symbol:      report.Symbol,
+		From:        report.From.Unix(),
+		To:          report.To.Unix(),
+		Interval:    req.Interval,
+		MaxDrawdown: report.MaxDrawdown,
+		Peak:        toPricePoint(report.Peak),
+		Trough:      toPricePoint(report.Trough),
+		Recovery:    toPricePoint(report.Recovery),
+		Underwater:  make([]dto.UnderwaterPointResponse, 0, len(report.Underwater)),
+	}
+	for _, p := range report.Underwater {
+		respDTO.Underwater = append(respDTO.Underwater, dto.UnderwaterPointResponse{
+			Timestamp: p.Time.Unix(),
+			Price:     p.Price,
+			Drawdown:  p.Drawdown,
+		})
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
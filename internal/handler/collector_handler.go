@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain/dto"
+	"github.com/adal4ik/crypto-service/internal/service"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/adal4ik/crypto-service/pkg/response"
+)
+
+type CollectorHandler struct {
+	service     service.CollectorInterface
+	logger      logger.Logger
+	handleError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func NewCollectorHandler(
+	s service.CollectorInterface,
+	l logger.Logger,
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error),
+) *CollectorHandler {
+	return &CollectorHandler{
+		service:     s,
+		logger:      l,
+		handleError: errorHandler,
+	}
+}
+
+// @Summary      Collector polling intervals
+// @Description  Returns the effective polling interval and recent volatility for every tracked currency.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=dto.CollectorScheduleResponse} "Successful response"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/admin/collector/intervals [get]
+func (h *CollectorHandler) GetIntervals(w http.ResponseWriter, r *http.Request) {
+	schedules, appErr := h.service.Schedules(r.Context())
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.CollectorScheduleResponse{
+		Mode:    "fixed",
+		Symbols: make([]dto.SymbolScheduleResponse, 0, len(schedules)),
+	}
+	if h.service.Adaptive() {
+		respDTO.Mode = "adaptive"
+	}
+	for _, s := range schedules {
+		item := dto.SymbolScheduleResponse{
+			Symbol:          s.Symbol,
+			IntervalSeconds: s.Interval.Seconds(),
+			Volatility:      s.Volatility,
+		}
+		if !s.NextDue.IsZero() {
+			item.NextDue = s.NextDue.Unix()
+		}
+		respDTO.Symbols = append(respDTO.Symbols, item)
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// @Summary      Write-ahead buffer status
+// @Description  Returns how many collected prices are waiting in the local buffer for the database, the oldest of them, and how many buffered prices the database rejected permanently and were moved to the dead-letter file.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=dto.BufferStatusResponse} "Successful response"
+// @Router       /api/v1/admin/buffer [get]
+func (h *CollectorHandler) GetBufferStatus(w http.ResponseWriter, r *http.Request) {
+	stats, enabled := h.service.BufferStats()
+
+	respDTO := dto.BufferStatusResponse{
+		Enabled:      enabled,
+		Depth:        stats.Depth,
+		DeadLettered: stats.DeadLettered,
+	}
+	if !stats.Oldest.IsZero() {
+		respDTO.OldestTimestamp = stats.Oldest.Unix()
+		respDTO.OldestAgeSeconds = time.Since(stats.Oldest).Seconds()
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
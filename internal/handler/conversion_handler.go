@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/domain/dto"
+	"github.com/adal4ik/crypto-service/internal/service"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/adal4ik/crypto-service/pkg/response"
+)
+
+type ConversionHandler struct {
+	service     service.ConversionServiceInterface
+	logger      logger.Logger
+	handleError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func NewConversionHandler(
+	s service.ConversionServiceInterface,
+	l logger.Logger,
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error),
+) *ConversionHandler {
+	return &ConversionHandler{
+		service:     s,
+		logger:      l,
+		handleError: errorHandler,
+	}
+}
+
+// @Summary      Convert between currencies
+// @Description  Converts an amount of one cryptocurrency into another using the cross rate of their nearest USD samples at the given timestamp (now by default). Returns the rates and sample timestamps used.
+// @Tags         price
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ConvertRequest true "From, to, amount and optional timestamp"
+// @Success      200  {object}  response.SuccessResponse{data=dto.ConvertResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      422  {object}  response.APIError "Unprocessable Entity"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/convert [post]
+func (h *ConversionHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	var req dto.ConvertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, apperrors.NewBadRequest("invalid request body", err))
+		return
+	}
+
+	convReq := domain.ConversionRequest{From: req.From, To: req.To, Amount: req.Amount}
+	if req.Timestamp != 0 {
+		convReq.Timestamp = time.Unix(req.Timestamp, 0)
+	}
+	conv, appErr := h.service.Convert(r.Context(), convReq)
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.ConvertResponse{
+		From:               dto.ConversionLeg{Symbol: conv.From.Symbol, PriceUSD: conv.From.Price, Timestamp: conv.From.Timestamp.Unix()},
+		To:                 dto.ConversionLeg{Symbol: conv.To.Symbol, PriceUSD: conv.To.Price, Timestamp: conv.To.Timestamp.Unix()},
+		Amount:             conv.Amount,
+		Result:             conv.Result,
+		Rate:               conv.Rate,
+		RequestedTimestamp: conv.RequestedAt.Unix(),
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
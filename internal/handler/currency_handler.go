@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/internal/domain/dto"
 	"github.com/adal4ik/crypto-service/internal/service"
 	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
 	"github.com/adal4ik/crypto-service/pkg/response"
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 )
 
@@ -49,6 +52,7 @@ func (h *CurrencyHandler) handleError(w http.ResponseWriter, r *http.Request, er
 			Code:     appErr.Code,
 			Message:  appErr.Message,
 			Resource: r.URL.Path,
+			Details:  appErr.Details,
 		}
 		jsonErr.Send(w)
 		return
@@ -63,8 +67,9 @@ func (h *CurrencyHandler) handleError(w http.ResponseWriter, r *http.Request, er
 }
 
 // @Summary      Add a cryptocurrency
-// @Description  Adds a new cryptocurrency symbol to the tracking list.
+// @Description  Adds a new cryptocurrency symbol to the tracking list. Deprecated: use POST /api/v1/currencies.
 // @Tags         currency
+// @Deprecated
 // @Accept       json
 // @Produce      json
 // @Param        symbol body dto.AddCurrencyRequest true "Symbol to add"
@@ -88,8 +93,9 @@ func (h *CurrencyHandler) CreateCurrency(w http.ResponseWriter, r *http.Request)
 }
 
 // @Summary      Remove a cryptocurrency
-// @Description  Removes a cryptocurrency symbol from the tracking list.
+// @Description  Stops tracking a cryptocurrency; its price history is kept. Deprecated: use DELETE /api/v1/currencies/{symbol}.
 // @Tags         currency
+// @Deprecated
 // @Accept       json
 // @Produce      json
 // @Param        symbol body dto.RemoveCurrencyRequest true "Symbol to remove"
@@ -104,10 +110,273 @@ func (h *CurrencyHandler) RemoveCurrency(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.service.RemoveCurrency(r.Context(), req.Symbol); err != nil {
+	if err := h.service.ArchiveCurrency(r.Context(), req.Symbol); err != nil {
 		h.handleError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// @Summary      List tracked currencies
+// @Description  Returns tracked cryptocurrencies with collection status: provider mapping, first and last sample and the last collection error. The last error is cleared after a successful collection. Last errors are kept in memory by the collector, so they are lost on restart. sample_count scans the whole history of every currency, so it is only returned with counts=true or sort=sample_count.
+// @Tags         currency
+// @Produce      json
+// @Param        symbols  query  string  false  "Comma-separated symbols to include"
+// @Param        mapped   query  bool    false  "Only currencies that do (true) or do not (false) map to a provider"
+// @Param        failing  query  bool    false  "Only currencies with (true) or without (false) a last collection error"
+// @Param        archived query  bool    false  "Only archived (true) or active (false) currencies"
+// @Param        counts   query  bool    false  "Include sample_count (scans the whole history)"
+// @Param        sort     query  string  false  "Sort field"  Enums(symbol, created_at, first_sample, last_sample, sample_count)  default(symbol)
+// @Param        order    query  string  false  "Sort order"  Enums(asc, desc)  default(asc)
+// @Success      200  {object}  response.SuccessResponse{data=[]dto.CurrencyStatusResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies [get]
+func (h *CurrencyHandler) ListCurrencies(w http.ResponseWriter, r *http.Request) {
+	mapped, appErr := parseOptionalBoolParam(r, "mapped")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	failing, appErr := parseOptionalBoolParam(r, "failing")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	archived, appErr := parseOptionalBoolParam(r, "archived")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	counts, appErr := parseBoolParam(r, "counts")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	statuses, err := h.service.ListStatuses(r.Context(), domain.CurrencyStatusQuery{
+		Symbols:    parseListParam(r, "symbols"),
+		Mapped:     mapped,
+		Failing:    failing,
+		Archived:   archived,
+		WithCounts: counts,
+		Sort:       domain.CurrencyStatusSort(r.URL.Query().Get("sort")),
+		Order:      domain.SortOrder(r.URL.Query().Get("order")),
+	})
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	respDTO := make([]dto.CurrencyStatusResponse, 0, len(statuses))
+	for _, st := range statuses {
+		respDTO = append(respDTO, toCurrencyStatusResponse(st))
+	}
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// @Summary      Track a cryptocurrency
+// @Description  Adds a cryptocurrency to the tracking list and returns it. Adding a symbol that is already tracked is not an error.
+// @Tags         currency
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.AddCurrencyRequest true "Symbol to track"
+// @Success      201  {object}  response.SuccessResponse{data=dto.CurrencyResponse} "Tracked; Location points to the resource"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies [post]
+func (h *CurrencyHandler) PostCurrency(w http.ResponseWriter, r *http.Request) {
+	var req dto.AddCurrencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, apperrors.NewBadRequest("invalid request body", err))
+		return
+	}
+
+	if err := h.service.AddCurrency(r.Context(), req.Symbol); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	currency, err := h.service.GetCurrency(r.Context(), req.Symbol)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/currencies/"+currency.Symbol)
+	response.New(http.StatusCreated, "success", toCurrencyResponse(currency)).Send(w)
+}
+
+// @Summary      Get a tracked cryptocurrency
+// @Tags         currency
+// @Produce      json
+// @Param        symbol  path  string  true  "Currency symbol"
+// @Success      200  {object}  response.SuccessResponse{data=dto.CurrencyResponse} "Successful response"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol} [get]
+func (h *CurrencyHandler) GetCurrency(w http.ResponseWriter, r *http.Request) {
+	currency, err := h.service.GetCurrency(r.Context(), chi.URLParam(r, "symbol"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.New(http.StatusOK, "success", toCurrencyResponse(currency)).Send(w)
+}
+
+// @Summary      Update currency metadata
+// @Description  Changes metadata of a tracked cryptocurrency. Omitted fields stay unchanged; provider_ids and tags are replaced as a whole. Setting enabled to false pauses price collection.
+// @Tags         currency
+// @Accept       json
+// @Produce      json
+// @Param        symbol   path  string                     true  "Currency symbol"
+// @Param        request  body  dto.UpdateCurrencyRequest  true  "Fields to change"
+// @Success      200  {object}  response.SuccessResponse{data=dto.CurrencyResponse} "Updated currency"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol} [patch]
+func (h *CurrencyHandler) PatchCurrency(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateCurrencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, apperrors.NewBadRequest("invalid request body", err))
+		return
+	}
+
+	currency, err := h.service.UpdateCurrency(r.Context(), chi.URLParam(r, "symbol"), domain.CurrencyPatch{
+		Name:            req.Name,
+		ProviderIDs:     req.ProviderIDs,
+		DisplayDecimals: req.DisplayDecimals,
+		Enabled:         req.Enabled,
+		Tags:            req.Tags,
+		Notes:           req.Notes,
+	})
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.New(http.StatusOK, "success", toCurrencyResponse(currency)).Send(w)
+}
+
+// @Summary      Stop tracking a cryptocurrency
+// @Description  Archives a cryptocurrency: price collection stops, the price history is kept and stays queryable. Tracking can be resumed with POST /api/v1/currencies/{symbol}/resume.
+// @Tags         currency
+// @Param        symbol  path  string  true  "Currency symbol"
+// @Success      204 "Archived (No Content)"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol} [delete]
+func (h *CurrencyHandler) DeleteCurrency(w http.ResponseWriter, r *http.Request) {
+	currency, err := h.service.GetCurrency(r.Context(), chi.URLParam(r, "symbol"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	if err := h.service.ArchiveCurrency(r.Context(), currency.Symbol); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary      Resume tracking a cryptocurrency
+// @Description  Resumes price collection for an archived cryptocurrency. Its price history is intact. Resuming an active currency is not an error.
+// @Tags         currency
+// @Produce      json
+// @Param        symbol  path  string  true  "Currency symbol"
+// @Success      200  {object}  response.SuccessResponse{data=dto.CurrencyResponse} "Resumed currency"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol}/resume [post]
+func (h *CurrencyHandler) ResumeCurrency(w http.ResponseWriter, r *http.Request) {
+	currency, err := h.service.ResumeCurrency(r.Context(), chi.URLParam(r, "symbol"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.New(http.StatusOK, "success", toCurrencyResponse(currency)).Send(w)
+}
+
+// @Summary      Purge an archived cryptocurrency
+// @Description  Irreversibly deletes an archived cryptocurrency together with its price history. The currency must be archived first, and the body must repeat its symbol in "confirm".
+// @Tags         currency
+// @Accept       json
+// @Produce      json
+// @Param        symbol   path  string                    true  "Currency symbol"
+// @Param        request  body  dto.PurgeCurrencyRequest  true  "Confirmation"
+// @Success      200  {object}  response.SuccessResponse{data=dto.PurgeCurrencyResponse} "Purged"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      409  {object}  response.APIError "Currency is not archived"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol}/purge [post]
+func (h *CurrencyHandler) PurgeCurrency(w http.ResponseWriter, r *http.Request) {
+	var req dto.PurgeCurrencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, apperrors.NewBadRequest("invalid request body", err))
+		return
+	}
+
+	symbol := chi.URLParam(r, "symbol")
+	deleted, err := h.service.PurgeCurrency(r.Context(), symbol, req.Confirm)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response.New(http.StatusOK, "success", dto.PurgeCurrencyResponse{
+		Symbol:         strings.ToUpper(strings.TrimSpace(symbol)),
+		DeletedSamples: deleted,
+	}).Send(w)
+}
+
+func toCurrencyResponse(c domain.Currency) dto.CurrencyResponse {
+	resp := dto.CurrencyResponse{
+		Symbol:          c.Symbol,
+		CreatedAt:       c.CreatedAt.Unix(),
+		Name:            c.Name,
+		ProviderIDs:     c.ProviderIDs,
+		DisplayDecimals: c.DisplayDecimals,
+		Enabled:         c.Enabled,
+		Tags:            c.Tags,
+		Notes:           c.Notes,
+	}
+	if c.ArchivedAt != nil {
+		ts := c.ArchivedAt.Unix()
+		resp.ArchivedAt = &ts
+	}
+	if resp.ProviderIDs == nil {
+		resp.ProviderIDs = map[string]string{}
+	}
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+	return resp
+}
+
+func toCurrencyStatusResponse(st domain.CurrencyStatus) dto.CurrencyStatusResponse {
+	resp := dto.CurrencyStatusResponse{
+		CurrencyResponse: toCurrencyResponse(st.Currency),
+		Provider:         st.Provider,
+		ProviderID:       st.ProviderID,
+		Mapped:           st.Mapped,
+		SampleCount:      st.SampleCount,
+	}
+	if st.FirstSample != nil {
+		ts := st.FirstSample.Unix()
+		resp.FirstSample = &ts
+	}
+	if st.LastSample != nil {
+		ts := st.LastSample.Unix()
+		resp.LastSample = &ts
+	}
+	if st.LastError != nil {
+		resp.LastError = &dto.CollectionErrorResponse{Message: st.LastError.Message, At: st.LastError.At.Unix()}
+	}
+	return resp
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Исходные маршруты без версии - устаревшие псевдонимы /api/v1. Они отвечают как раньше, но сообщают
+// о снятии с поддержки: Deprecation (RFC 9745), Sunset (RFC 8594) и ссылка на замену.
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
+)
+
+// legacySuccessors - замены маршрутов-глаголов; символ у них в теле, поэтому замена - коллекция.
+var legacySuccessors = map[string]string{
+	"/currency/add":    "/api/v1/currencies",
+	"/currency/remove": "/api/v1/currencies",
+	"/currency/price":  "/api/v1/currencies",
+}
+
+func deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", legacyDeprecatedAt.Unix()))
+		w.Header().Set("Sunset", legacySunsetAt.Format(http.TimeFormat))
+		if successor, ok := legacySuccessors[r.URL.Path]; ok {
+			w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/service"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	_ "github.com/adal4ik/crypto-service/pkg/response" // модели ошибок для swag-аннотаций
+	"go.uber.org/zap"
+)
+
+var exportContentTypes = map[domain.ExportFormat]string{
+	domain.ExportFormatCSV:    "text/csv; charset=utf-8",
+	domain.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+type ExportHandler struct {
+	service     service.ExportServiceInterface
+	logger      logger.Logger
+	handleError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func NewExportHandler(
+	s service.ExportServiceInterface,
+	l logger.Logger,
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error),
+) *ExportHandler {
+	return &ExportHandler{
+		service:     s,
+		logger:      l,
+		handleError: errorHandler,
+	}
+}
+
+// @Summary      Export price history
+// @Description  Streams price history as CSV or NDJSON straight from the database without buffering the whole result. The long layout has one row per sample (timestamp, symbol, price); the wide layout has one row per timestamp and one column per symbol, empty where a currency has no sample at that moment. Timestamps are RFC3339 in UTC. If the stream fails midway the connection is closed, so a truncated file never looks complete.
+// @Tags         price
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        symbols  query  string  false  "Comma-separated currency symbols, all tracked by default"
+// @Param        from     query  string  false  "Range start (unix seconds or RFC3339)"
+// @Param        to       query  string  false  "Range end (unix seconds or RFC3339)"
+// @Param        format   query  string  false  "csv (default) or ndjson"
+// @Param        layout   query  string  false  "long (default) or wide"
+// @Success      200  {file}    file "Export stream"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/export/prices [get]
+func (h *ExportHandler) Prices(w http.ResponseWriter, r *http.Request) {
+	req := domain.ExportRequest{
+		Symbols: parseListParam(r, "symbols"),
+		Format:  domain.ExportFormat(r.URL.Query().Get("format")),
+		Layout:  domain.ExportLayout(r.URL.Query().Get("layout")),
+	}
+	var appErr *apperrors.AppError
+	if req.From, appErr = parseTimeParam(r, "from"); appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	if req.To, appErr = parseTimeParam(r, "to"); appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	if req.Format == "" {
+		req.Format = domain.ExportFormatCSV
+	}
+
+	sw := &streamWriter{w: w, contentType: exportContentTypes[req.Format], filename: "prices." + string(req.Format)}
+	appErr = h.service.Export(r.Context(), req, sw)
+	switch {
+	case appErr == nil:
+	case r.Context().Err() != nil:
+		h.logger.Info("export cancelled by client", zap.String("url", r.URL.Path), zap.Int64("bytes", sw.written))
+	case !sw.started:
+		h.handleError(w, r, appErr)
+	default:
+		// Статус уже отправлен: обрываем соединение, чтобы клиент не принял обрезанный файл за полный.
+		h.logger.Error("export failed midway", zap.Error(appErr), zap.Int64("bytes", sw.written))
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// streamWriter отправляет заголовки ответа при первой записи, чтобы ошибки,
+// возникшие до начала выгрузки, ещё можно было вернуть обычным JSON.
+type streamWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+	written     int64
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	if !sw.started {
+		sw.started = true
+		sw.w.Header().Set("Content-Type", sw.contentType)
+		sw.w.Header().Set("Content-Disposition", `attachment; filename="`+sw.filename+`"`)
+		sw.w.WriteHeader(http.StatusOK)
+	}
+	n, err := sw.w.Write(p)
+	sw.written += int64(n)
+	return n, err
+}
@@ -1,20 +1,33 @@
 package handler
 
 import (
+	"github.com/adal4ik/crypto-service/internal/config"
 	"github.com/adal4ik/crypto-service/internal/service"
 	"github.com/adal4ik/crypto-service/pkg/logger"
 )
 
 type Handlers struct {
-	Currency *CurrencyHandler
-	Price    *PriceHandler
+	Currency   *CurrencyHandler
+	Price      *PriceHandler
+	Collector  *CollectorHandler
+	Health     *HealthHandler
+	Analytics  *AnalyticsHandler
+	Conversion *ConversionHandler
+	Export     *ExportHandler
+	Import     *ImportHandler
 }
 
-func NewHandlers(s *service.Service, logger logger.Logger) *Handlers {
+func NewHandlers(s *service.Service, logger logger.Logger, cfg config.AppConfig) *Handlers {
 	currencyHandler := NewCurrencyHandler(s.Currency, logger)
 
 	return &Handlers{
-		Currency: currencyHandler,
-		Price:    NewPriceHandler(s.Price, logger, currencyHandler.handleError),
+		Currency:   currencyHandler,
+		Price:      NewPriceHandler(s.Price, logger, currencyHandler.handleError),
+		Collector:  NewCollectorHandler(s.PriceCollector, logger, currencyHandler.handleError),
+		Health:     NewHealthHandler(s.Health, logger),
+		Analytics:  NewAnalyticsHandler(s.Analytics, logger, currencyHandler.handleError),
+		Conversion: NewConversionHandler(s.Conversion, logger, currencyHandler.handleError),
+		Export:     NewExportHandler(s.Export, logger, currencyHandler.handleError),
+		Import:     NewImportHandler(s.Import, logger, currencyHandler.handleError, cfg.ImportMaxBytes),
 	}
 }
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/adal4ik/crypto-service/internal/domain/dto"
+	"github.com/adal4ik/crypto-service/internal/service"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/adal4ik/crypto-service/pkg/response"
+	"go.uber.org/zap"
+)
+
+// retryAfterSeconds - подсказка клиенту, когда повторить запрос в деградированном режиме.
+const retryAfterSeconds = "5"
+
+type HealthHandler struct {
+	service service.HealthServiceInterface
+	logger  logger.Logger
+}
+
+func NewHealthHandler(s service.HealthServiceInterface, l logger.Logger) *HealthHandler {
+	return &HealthHandler{
+		service: s,
+		logger:  l,
+	}
+}
+
+// @Summary      Service health
+// @Description  Reports whether the service is healthy or running in degraded mode without a database connection.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=dto.HealthResponse} "Healthy"
+// @Failure      503  {object}  response.SuccessResponse{data=dto.HealthResponse} "Degraded"
+// @Router       /health [get]
+func (h *HealthHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	status := h.service.Status()
+
+	respDTO := dto.HealthResponse{
+		Status:   "healthy",
+		Database: "up",
+		Reason:   status.Reason,
+		Since:    status.Since.Unix(),
+	}
+	if !status.LastCheck.IsZero() {
+		respDTO.LastCheck = status.LastCheck.Unix()
+	}
+	code := http.StatusOK
+	if !status.Healthy {
+		respDTO.Status = "degraded"
+		respDTO.Database = "down"
+		code = http.StatusServiceUnavailable
+	}
+
+	response.New(code, respDTO.Status, respDTO).Send(w)
+}
+
+// RequireDatabase отвечает 503 на запросы к данным, пока база недоступна.
+func (h *HealthHandler) RequireDatabase(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := h.service.Status()
+		if status.Healthy {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h.logger.Warn("Rejecting request in degraded mode",
+			zap.String("url", r.URL.Path),
+			zap.String("reason", status.Reason),
+		)
+		w.Header().Set("Retry-After", retryAfterSeconds)
+		response.APIError{
+			Code:     http.StatusServiceUnavailable,
+			Message:  "service is running in degraded mode: " + status.Reason,
+			Resource: r.URL.Path,
+		}.Send(w)
+	})
+}
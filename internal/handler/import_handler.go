@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/domain/dto"
+	"github.com/adal4ik/crypto-service/internal/service"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/adal4ik/crypto-service/pkg/response"
+)
+
+type ImportHandler struct {
+	service     service.ImportServiceInterface
+	logger      logger.Logger
+	handleError func(w http.ResponseWriter, r *http.Request, err error)
+	maxBytes    int64
+}
+
+func NewImportHandler(
+	s service.ImportServiceInterface,
+	l logger.Logger,
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error),
+	maxBytes int64,
+) *ImportHandler {
+	return &ImportHandler{
+		service:     s,
+		logger:      l,
+		handleError: errorHandler,
+		maxBytes:    maxBytes,
+	}
+}
+
+// @Summary      Import price history
+// @Description  Loads historical prices from a CSV (header with timestamp, symbol, price) or NDJSON file, sent either as the raw request body or as the 'file' field of a multipart form. Each row is validated: known symbol, positive decimal price, timestamp in unix seconds or RFC3339. Rows are written in batches, one transaction per batch. A row whose currency already has a sample at that time is reported as a duplicate and skipped. The report lists duplicate and rejected rows by line number.
+// @Tags         price
+// @Accept       text/csv
+// @Accept       application/x-ndjson
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        format  query     string  false  "csv or ndjson; detected from Content-Type or file name if omitted"
+// @Param        file    formData  file    false  "File to import (multipart upload)"
+// @Success      200  {object}  response.SuccessResponse{data=dto.ImportReportResponse} "Import report"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      413  {object}  response.APIError "Request Entity Too Large"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/import/prices [post]
+func (h *ImportHandler) Prices(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes)
+
+	body, format, appErr := h.upload(r)
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	if explicit := r.URL.Query().Get("format"); explicit != "" {
+		format = domain.ExportFormat(explicit)
+	}
+
+	report, appErr := h.service.Import(r.Context(), format, body)
+	if appErr != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(appErr, &tooLarge) {
+			appErr = apperrors.New(http.StatusRequestEntityTooLarge, "file exceeds the upload limit", appErr.Err).WithDetails(appErr.Details)
+		}
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.ImportReportResponse{
+		Total:      report.Total,
+		Accepted:   report.Accepted,
+		Duplicate:  report.Duplicate,
+		Rejected:   report.Rejected,
+		Duplicates: make([]int, 0, len(report.Duplicates)),
+		Rejections: make([]dto.ImportRejectionResponse, 0, len(report.Rejections)),
+		Truncated:  report.Truncated,
+	}
+	respDTO.Duplicates = append(respDTO.Duplicates, report.Duplicates...)
+	for _, rej := range report.Rejections {
+		respDTO.Rejections = append(respDTO.Rejections, dto.ImportRejectionResponse{Line: rej.Line, Reason: rej.Reason})
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// upload возвращает поток файла - тело запроса или поле file multipart-формы - и формат,
+// угаданный по Content-Type или расширению имени файла (пустой, если угадать не удалось).
+func (h *ImportHandler) upload(r *http.Request) (io.Reader, domain.ExportFormat, *apperrors.AppError) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, formatFromMediaType(mediaType), nil
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", apperrors.NewBadRequest("invalid multipart form", err)
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, "", apperrors.NewBadRequest("multipart form has no 'file' field", nil)
+		}
+		if err != nil {
+			return nil, "", apperrors.NewBadRequest("invalid multipart form", err)
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		switch strings.ToLower(path.Ext(part.FileName())) {
+		case ".csv":
+			return part, domain.ExportFormatCSV, nil
+		case ".ndjson", ".jsonl":
+			return part, domain.ExportFormatNDJSON, nil
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		return part, formatFromMediaType(partType), nil
+	}
+}
+
+func formatFromMediaType(mediaType string) domain.ExportFormat {
+	switch mediaType {
+	case "text/csv":
+		return domain.ExportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return domain.ExportFormatNDJSON
+	}
+	return ""
+}
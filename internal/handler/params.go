@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+)
+
+// parseTimeParam читает время из query-параметра: unix-секунды или RFC3339.
+// Отсутствующий параметр даёт нулевое время.
+func parseTimeParam(r *http.Request, name string) (time.Time, *apperrors.AppError) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, apperrors.NewBadRequest(fmt.Sprintf("parameter '%s' must be a unix timestamp or RFC3339 time", name), err)
+	}
+	return t, nil
+}
+
+// parseIntParam читает целое из query-параметра; отсутствующий параметр даёт 0.
+func parseIntParam(r *http.Request, name string) (int, *apperrors.AppError) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, apperrors.NewBadRequest(fmt.Sprintf("parameter '%s' must be an integer", name), err)
+	}
+	return v, nil
+}
+
+// parseBoolParam читает булев query-параметр (true/false, 1/0); отсутствующий параметр даёт false.
+func parseBoolParam(r *http.Request, name string) (bool, *apperrors.AppError) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, apperrors.NewBadRequest(fmt.Sprintf("parameter '%s' must be true or false", name), err)
+	}
+	return v, nil
+}
+
+// parseOptionalBoolParam читает булев query-параметр; отсутствующий параметр даёт nil.
+func parseOptionalBoolParam(r *http.Request, name string) (*bool, *apperrors.AppError) {
+	if r.URL.Query().Get(name) == "" {
+		return nil, nil
+	}
+	v, appErr := parseBoolParam(r, name)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return &v, nil
+}
+
+// parseListParam разбирает список через запятую, отбрасывая пустые элементы.
+func parseListParam(r *http.Request, name string) []string {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
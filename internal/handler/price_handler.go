@@ -3,12 +3,16 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/internal/domain/dto"
 	"github.com/adal4ik/crypto-service/internal/service"
 	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
 	"github.com/adal4ik/crypto-service/pkg/response"
+	"github.com/go-chi/chi/v5"
 )
 
 type PriceHandler struct {
@@ -30,11 +34,12 @@ func NewPriceHandler(
 }
 
 // @Summary      Get cryptocurrency price
-// @Description  Get the price of a cryptocurrency at the nearest available time to the requested timestamp.
+// @Description  Get the price of a cryptocurrency at the requested timestamp. mode selects the nearest sample (default), the last one at or before, the first one at or after the timestamp, or interpolates linearly between the two and returns both source samples. With max_distance set, a sample further away than that many seconds yields 404 with the distance in details. Deprecated: use GET /api/v1/currencies/{symbol}/price.
 // @Tags         price
+// @Deprecated
 // @Accept       json
 // @Produce      json
-// @Param        request body dto.GetPriceRequest true "Coin and Timestamp"
+// @Param        request body dto.GetPriceRequest true "Coin, timestamp and optional mode/max_distance"
 // @Success      200  {object}  response.SuccessResponse{data=dto.PriceResponse} "Successful response"
 // @Failure      400  {object}  response.APIError "Bad Request"
 // @Failure      404  {object}  response.APIError "Not Found"
@@ -56,16 +61,291 @@ func (h *PriceHandler) GetPrice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	price, foundTime, appErr := h.service.GetNearestPrice(r.Context(), req.Coin, req.Timestamp)
+	quote, appErr := h.service.GetPriceAt(r.Context(), domain.PriceAtRequest{
+		Symbol:      req.Coin,
+		Timestamp:   time.Unix(req.Timestamp, 0),
+		Mode:        domain.PriceMode(req.Mode),
+		MaxDistance: time.Duration(req.MaxDistance) * time.Second,
+	})
 	if appErr != nil {
 		h.handleError(w, r, appErr)
 		return
 	}
 
 	respDTO := dto.PriceResponse{
-		Symbol:    req.Coin,
-		Price:     price,
-		Timestamp: foundTime.Unix(),
+		Symbol:       req.Coin,
+		Price:        quote.Price,
+		Timestamp:    quote.Timestamp.Unix(),
+		Interpolated: quote.Interpolated,
+		Before:       toPricePoint(quote.Before),
+		After:        toPricePoint(quote.After),
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// @Summary      Get cryptocurrency price
+// @Description  Get the price of a cryptocurrency at a moment in time (now by default). mode selects the nearest sample (default), the last one at or before, the first one at or after the moment, or interpolates linearly between the two and returns both source samples. With max_distance set, a sample further away than that many seconds yields 404 with the distance in details.
+// @Tags         price
+// @Produce      json
+// @Param        symbol        path   string  true   "Currency symbol"
+// @Param        at            query  string  false  "Moment (unix seconds or RFC3339), default now"
+// @Param        mode          query  string  false  "nearest (default), before, after or interpolate"
+// @Param        max_distance  query  int     false  "Maximum distance to the sample in seconds"
+// @Success      200  {object}  response.SuccessResponse{data=dto.PriceResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol}/price [get]
+func (h *PriceHandler) GetPriceAt(w http.ResponseWriter, r *http.Request) {
+	at, appErr := parseTimeParam(r, "at")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	maxDistance, appErr := parseIntParam(r, "max_distance")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	symbol := strings.ToUpper(chi.URLParam(r, "symbol"))
+	quote, appErr := h.service.GetPriceAt(r.Context(), domain.PriceAtRequest{
+		Symbol:      symbol,
+		Timestamp:   at,
+		Mode:        domain.PriceMode(r.URL.Query().Get("mode")),
+		MaxDistance: time.Duration(maxDistance) * time.Second,
+	})
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.PriceResponse{
+		Symbol:       symbol,
+		Price:        quote.Price,
+		Timestamp:    quote.Timestamp.Unix(),
+		Interpolated: quote.Interpolated,
+		Before:       toPricePoint(quote.Before),
+		After:        toPricePoint(quote.After),
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+func toPricePoint(s *domain.PriceSample) *dto.PricePoint {
+	if s == nil {
+		return nil
+	}
+	return &dto.PricePoint{Price: s.Price, Timestamp: s.Timestamp.Unix()}
+}
+
+// @Summary      Get price history
+// @Description  Returns price samples of a cryptocurrency within a time range, page by page. Pass next_cursor from the previous page as cursor to continue.
+// @Tags         price
+// @Produce      json
+// @Param        symbol  path   string  true   "Currency symbol"
+// @Param        from    query  string  false  "Range start (unix seconds or RFC3339)"
+// @Param        to      query  string  false  "Range end (unix seconds or RFC3339)"
+// @Param        limit   query  int     false  "Page size (1-1000, default 100)"
+// @Param        order   query  string  false  "asc or desc (default desc)"
+// @Param        cursor  query  string  false  "Opaque cursor from a previous page"
+// @Success      200  {object}  response.SuccessResponse{data=dto.PriceHistoryResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol}/history [get]
+func (h *PriceHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	from, appErr := parseTimeParam(r, "from")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	to, appErr := parseTimeParam(r, "to")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	limit, appErr := parseIntParam(r, "limit")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	query := domain.PriceRangeQuery{
+		Symbol: chi.URLParam(r, "symbol"),
+		From:   from,
+		To:     to,
+		Order:  domain.SortOrder(r.URL.Query().Get("order")),
+		Limit:  limit,
+	}
+	page, appErr := h.service.GetHistory(r.Context(), query, r.URL.Query().Get("cursor"))
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	order := query.Order
+	if order == "" {
+		order = domain.SortDesc
+	}
+	respDTO := dto.PriceHistoryResponse{
+		Symbol:     strings.ToUpper(query.Symbol),
+		Order:      string(order),
+		Items:      make([]dto.PricePoint, 0, len(page.Samples)),
+		NextCursor: page.NextCursor,
+	}
+	for _, s := range page.Samples {
+		respDTO.Items = append(respDTO.Items, dto.PricePoint{Price: s.Price, Timestamp: s.Timestamp.Unix()})
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// @Summary      Get OHLC candles
+// @Description  Aggregates price history into open/high/low/close candles. Buckets are aligned to the requested timezone (UTC by default): daily candles start at local midnight, shorter ones always last exactly one interval across DST changes.
+// @Tags         price
+// @Produce      json
+// @Param        symbol    path   string  true   "Currency symbol"
+// @Param        interval  query  string  true   "Candle size: 1m, 5m, 1h or 1d"
+// @Param        from      query  string  false  "Range start (unix seconds or RFC3339), default 100 candles before 'to'"
+// @Param        to        query  string  false  "Range end (unix seconds or RFC3339), default now"
+// @Param        tz        query  string  false  "IANA timezone for bucket alignment, e.g. Europe/Berlin"
+// @Param        empty     query  string  false  "omit (default) or flag to include empty buckets"
+// @Success      200  {object}  response.SuccessResponse{data=dto.CandlesResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      404  {object}  response.APIError "Not Found"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/currencies/{symbol}/candles [get]
+func (h *PriceHandler) GetCandles(w http.ResponseWriter, r *http.Request) {
+	from, appErr := parseTimeParam(r, "from")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	to, appErr := parseTimeParam(r, "to")
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+	req := domain.CandleRequest{
+		Symbol:   chi.URLParam(r, "symbol"),
+		Interval: r.URL.Query().Get("interval"),
+		From:     from,
+		To:       to,
+		Timezone: r.URL.Query().Get("tz"),
+	}
+	switch r.URL.Query().Get("empty") {
+	case "", "omit":
+	case "flag":
+		req.IncludeEmpty = true
+	default:
+		h.handleError(w, r, apperrors.NewBadRequest("parameter 'empty' must be 'omit' or 'flag'", nil))
+		return
+	}
+
+	candles, appErr := h.service.GetCandles(r.Context(), req)
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.CandlesResponse{
+		Symbol:   strings.ToUpper(req.Symbol),
+		Interval: req.Interval,
+		Timezone: "UTC",
+		Candles:  make([]dto.CandleResponse, 0, len(candles)),
+	}
+	if req.Timezone != "" {
+		respDTO.Timezone = req.Timezone
+	}
+	for _, c := range candles {
+		item := dto.CandleResponse{Start: c.Start.Unix(), Samples: c.Count, Empty: c.Empty}
+		if !c.Empty {
+			item.Open, item.High, item.Low, item.Close = &c.Open, &c.High, &c.Low, &c.Close
+		}
+		respDTO.Candles = append(respDTO.Candles, item)
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// @Summary      Latest prices
+// @Description  Returns the most recent sample, its age and the change versus the last sample at least 24h older than it, for every tracked currency or only for the given symbols.
+// @Tags         price
+// @Produce      json
+// @Param        symbols  query  string  false  "Comma-separated currency symbols; archived currencies are included only when listed"
+// @Success      200  {object}  response.SuccessResponse{data=[]dto.LatestPriceResponse} "Successful response"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/prices/latest [get]
+func (h *PriceHandler) GetLatest(w http.ResponseWriter, r *http.Request) {
+	prices, appErr := h.service.GetLatest(r.Context(), parseListParam(r, "symbols"))
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	now := time.Now()
+	respDTO := make([]dto.LatestPriceResponse, 0, len(prices))
+	for _, p := range prices {
+		respDTO = append(respDTO, dto.LatestPriceResponse{
+			Symbol:           p.Symbol,
+			Price:            p.Price,
+			Timestamp:        p.Timestamp.Unix(),
+			AgeSeconds:       int64(now.Sub(p.Timestamp).Seconds()),
+			Change24h:        p.Change24h,
+			ChangePercent24h: p.ChangePercent24h,
+		})
+	}
+
+	response.New(http.StatusOK, "success", respDTO).Send(w)
+}
+
+// @Summary      Batch nearest prices
+// @Description  Resolves the nearest available price for many coin/timestamp pairs in one request. Results keep the request order; an item that cannot be resolved carries its own error and does not fail the batch.
+// @Tags         price
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.BatchPriceRequest true "Coin/timestamp pairs"
+// @Success      200  {object}  response.SuccessResponse{data=dto.BatchPriceResponse} "Successful response"
+// @Failure      400  {object}  response.APIError "Bad Request"
+// @Failure      500  {object}  response.APIError "Internal Server Error"
+// @Router       /api/v1/prices/batch [post]
+func (h *PriceHandler) GetPriceBatch(w http.ResponseWriter, r *http.Request) {
+	var req dto.BatchPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, apperrors.NewBadRequest("invalid request body", err))
+		return
+	}
+
+	lookups := make([]domain.PriceLookup, 0, len(req.Items))
+	for _, item := range req.Items {
+		lookup := domain.PriceLookup{Symbol: item.Coin}
+		if item.Timestamp != 0 {
+			lookup.Timestamp = time.Unix(item.Timestamp, 0).UTC()
+		}
+		lookups = append(lookups, lookup)
+	}
+
+	results, appErr := h.service.GetNearestPrices(r.Context(), lookups)
+	if appErr != nil {
+		h.handleError(w, r, appErr)
+		return
+	}
+
+	respDTO := dto.BatchPriceResponse{Results: make([]dto.BatchPriceResult, 0, len(results))}
+	for i, res := range results {
+		item := dto.BatchPriceResult{Coin: res.Symbol, RequestedTimestamp: req.Items[i].Timestamp}
+		if res.Err != nil {
+			item.Error = &dto.BatchItemError{Code: res.Err.Code, Message: res.Err.Message}
+		} else {
+			price := res.Price
+			item.Price, item.Timestamp = &price, res.Timestamp.Unix()
+		}
+		respDTO.Results = append(respDTO.Results, item)
 	}
 
 	response.New(http.StatusOK, "success", respDTO).Send(w)
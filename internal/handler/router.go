@@ -14,15 +14,65 @@ func Router(h *Handlers) http.Handler {
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", "Location", "Deprecation", "Sunset"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any major browsers
 	}))
 	r.Use(middleware.Logger)
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
+	r.Get("/health", h.Health.GetHealth)
+
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Route("/currencies", func(r chi.Router) {
+			r.Use(h.Health.RequireDatabase)
+			r.Get("/", h.Currency.ListCurrencies)
+			r.Post("/", h.Currency.PostCurrency)
+			r.Route("/{symbol}", func(r chi.Router) {
+				r.Get("/", h.Currency.GetCurrency)
+				r.Patch("/", h.Currency.PatchCurrency)
+				r.Delete("/", h.Currency.DeleteCurrency)
+				r.Post("/resume", h.Currency.ResumeCurrency)
+				r.Post("/purge", h.Currency.PurgeCurrency)
+				r.Get("/price", h.Price.GetPriceAt)
+				r.Get("/history", h.Price.GetHistory)
+				r.Get("/candles", h.Price.GetCandles)
+				r.Get("/stats", h.Analytics.Stats)
+				r.Get("/indicators", h.Analytics.Indicators)
+				r.Get("/drawdown", h.Analytics.Drawdown)
+			})
+		})
+		r.Route("/prices", func(r chi.Router) {
+			r.Use(h.Health.RequireDatabase)
+			r.Get("/latest", h.Price.GetLatest)
+			r.Post("/batch", h.Price.GetPriceBatch)
+			r.Get("/resample", h.Analytics.Resample)
+			r.Get("/performance", h.Analytics.Performance)
+		})
+		r.With(h.Health.RequireDatabase).Post("/convert", h.Conversion.Convert)
+		r.Route("/analytics", func(r chi.Router) {
+			r.Use(h.Health.RequireDatabase)
+			r.Get("/correlation", h.Analytics.Correlation)
+		})
+		r.Route("/export", func(r chi.Router) {
+			r.Use(h.Health.RequireDatabase)
+			r.Get("/prices", h.Export.Prices)
+		})
+		r.Route("/import", func(r chi.Router) {
+			r.Use(h.Health.RequireDatabase)
+			r.Post("/prices", h.Import.Prices)
+		})
+		r.Route("/admin", func(r chi.Router) {
+			// Буфер открыт и без базы: он как раз и нужен, пока она недоступна.
+			r.With(h.Health.RequireDatabase).Get("/collector/intervals", h.Collector.GetIntervals)
+			r.Get("/buffer", h.Collector.GetBufferStatus)
+		})
+	})
+
+	// Устаревшие псевдонимы исходных маршрутов, см. deprecated.
 	r.Route("/currency", func(r chi.Router) {
+		r.Use(deprecated, h.Health.RequireDatabase)
 		r.Post("/add", h.Currency.CreateCurrency)
 		r.Post("/remove", h.Currency.RemoveCurrency)
 		r.Post("/price", h.Price.GetPrice)
@@ -0,0 +1,197 @@
+// Package importer читает файлы истории цен (CSV или NDJSON) построчно и проверяет каждую строку.
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// maxLineBytes - предел длины строки NDJSON.
+const maxLineBytes = 1 << 20
+
+// maxPrice - первая цена, не помещающаяся в NUMERIC(20, 8) колонки price_history.price.
+var maxPrice = decimal.New(1, 12)
+
+// Record - строка файла: проверенный сэмпл или причина отказа в Err.
+type Record struct {
+	Row domain.ImportRow
+	Err error
+}
+
+// Reader отдаёт строки файла по одной; после последней строки Next возвращает io.EOF.
+// Прочие ошибки Next означают, что файл дальше читать нельзя.
+type Reader interface {
+	Next() (Record, error)
+}
+
+// NewReader создаёт Reader для формата выгрузки (csv или ndjson), так что выгруженный
+// в раскладке long файл можно загрузить обратно. У CSV обязателен заголовок с колонками
+// timestamp, symbol и price в любом порядке.
+func NewReader(r io.Reader, format domain.ExportFormat) (Reader, error) {
+	switch format {
+	case domain.ExportFormatCSV:
+		return newCSVReader(r)
+	case domain.ExportFormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64<<10), maxLineBytes)
+		return &ndjsonReader{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("unknown import format %q, expected csv or ndjson", format)
+	}
+}
+
+type csvReader struct {
+	r                     *csv.Reader
+	timestamp, sym, price int
+	width                 int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("file is empty")
+		}
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	reader := &csvReader{r: cr, timestamp: -1, sym: -1, price: -1, width: len(header)}
+	columns := map[string]*int{"timestamp": &reader.timestamp, "symbol": &reader.sym, "price": &reader.price}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if idx, ok := columns[name]; ok && *idx < 0 {
+			*idx = i
+		}
+	}
+	var missing []string
+	for _, name := range []string{"timestamp", "symbol", "price"} {
+		if *columns[name] < 0 {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("header is missing columns: %s", strings.Join(missing, ", "))
+	}
+	return reader, nil
+}
+
+func (cr *csvReader) Next() (Record, error) {
+	fields, err := cr.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Record{Row: domain.ImportRow{Line: parseErr.StartLine}, Err: parseErr.Err}, nil
+		}
+		return Record{}, err
+	}
+	line, _ := cr.r.FieldPos(0)
+	if len(fields) != cr.width {
+		return Record{Row: domain.ImportRow{Line: line}, Err: fmt.Errorf("expected %d fields, got %d", cr.width, len(fields))}, nil
+	}
+	return parseRecord(line, fields[cr.timestamp], fields[cr.sym], fields[cr.price]), nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// ndjsonRow - строка NDJSON; timestamp и price допускаются и строкой, и числом.
+type ndjsonRow struct {
+	Timestamp json.RawMessage `json:"timestamp"`
+	Symbol    string          `json:"symbol"`
+	Price     json.RawMessage `json:"price"`
+}
+
+func (nr *ndjsonReader) Next() (Record, error) {
+	for nr.scanner.Scan() {
+		nr.line++
+		raw := nr.scanner.Bytes()
+		if len(strings.TrimSpace(string(raw))) == 0 {
+			continue
+		}
+		var row ndjsonRow
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return Record{Row: domain.ImportRow{Line: nr.line}, Err: errors.New("invalid JSON")}, nil
+		}
+		return parseRecord(nr.line, jsonScalar(row.Timestamp), row.Symbol, jsonScalar(row.Price)), nil
+	}
+	if err := nr.scanner.Err(); err != nil {
+		return Record{}, fmt.Errorf("line %d: %w", nr.line+1, err)
+	}
+	return Record{}, io.EOF
+}
+
+// jsonScalar возвращает строку JSON без кавычек или текст числа как есть.
+func jsonScalar(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
+
+// parseRecord проверяет поля строки: непустой символ, положительная цена и время
+// в unix-секундах (возможно, дробных) или RFC3339.
+func parseRecord(line int, rawTimestamp, rawSymbol, rawPrice string) Record {
+	record := Record{Row: domain.ImportRow{Line: line, Symbol: strings.ToUpper(strings.TrimSpace(rawSymbol))}}
+	if record.Row.Symbol == "" {
+		record.Err = errors.New("symbol is empty")
+		return record
+	}
+
+	price, err := decimal.NewFromString(strings.TrimSpace(rawPrice))
+	switch {
+	case err != nil:
+		record.Err = fmt.Errorf("price %q is not a decimal number", rawPrice)
+		return record
+	case !price.IsPositive():
+		record.Err = fmt.Errorf("price %s is not positive", price)
+		return record
+	case price.GreaterThanOrEqual(maxPrice):
+		record.Err = fmt.Errorf("price %s is too large", price)
+		return record
+	}
+	record.Row.Price = price
+
+	ts, err := parseTimestamp(strings.TrimSpace(rawTimestamp))
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	record.Row.Timestamp = ts
+	return record
+}
+
+func parseTimestamp(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, errors.New("timestamp is empty")
+	}
+	if unix, err := decimal.NewFromString(raw); err == nil {
+		nanos := unix.Shift(9)
+		if nanos.IsNegative() || unix.GreaterThan(decimal.NewFromInt(1<<33)) {
+			return time.Time{}, fmt.Errorf("timestamp %q is out of range", raw)
+		}
+		return time.Unix(0, nanos.IntPart()).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timestamp %q is neither unix seconds nor RFC3339", raw)
+	}
+	return t.UTC(), nil
+}
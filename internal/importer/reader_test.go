@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, r Reader) []Record {
+	t.Helper()
+	var records []Record
+	for {
+		record, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func TestReader_CSV(t *testing.T) {
+	input := "symbol,price,timestamp\n" +
+		"btc,42000.5,2024-01-01T00:00:00Z\n" +
+		"ETH,-1,1704067200\n" +
+		"ETH,2200,yesterday\n" +
+		"SOL,95\n" +
+		"SOL,95.25,1704067200.5\n"
+
+	r, err := NewReader(strings.NewReader(input), domain.ExportFormatCSV)
+	require.NoError(t, err)
+	records := readAll(t, r)
+
+	require.Len(t, records, 5)
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, 2, records[0].Row.Line)
+	assert.Equal(t, "BTC", records[0].Row.Symbol)
+	assert.True(t, decimal.RequireFromString("42000.5").Equal(records[0].Row.Price))
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), records[0].Row.Timestamp)
+
+	assert.EqualError(t, records[1].Err, "price -1 is not positive")
+	assert.Equal(t, 3, records[1].Row.Line)
+	assert.EqualError(t, records[2].Err, `timestamp "yesterday" is neither unix seconds nor RFC3339`)
+	assert.EqualError(t, records[3].Err, "expected 3 fields, got 2")
+	assert.Equal(t, 5, records[3].Row.Line)
+
+	assert.NoError(t, records[4].Err)
+	assert.Equal(t, time.Unix(1704067200, 500_000_000).UTC(), records[4].Row.Timestamp)
+}
+
+func TestReader_CSVMissingColumns(t *testing.T) {
+	_, err := NewReader(strings.NewReader("time,symbol,price\n"), domain.ExportFormatCSV)
+	assert.EqualError(t, err, "header is missing columns: timestamp")
+}
+
+func TestReader_NDJSON(t *testing.T) {
+	input := `{"timestamp":"2024-01-01T00:00:00.123Z","symbol":"BTC","price":"42000.5"}` + "\n" +
+		"\n" +
+		`{"timestamp":1704067200,"symbol":"ETH","price":2200}` + "\n" +
+		`{"timestamp":1704067200,"symbol":"ETH"` + "\n" +
+		`{"timestamp":1704067200,"symbol":"","price":1}` + "\n"
+
+	r, err := NewReader(strings.NewReader(input), domain.ExportFormatNDJSON)
+	require.NoError(t, err)
+	records := readAll(t, r)
+
+	require.Len(t, records, 4)
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 123_000_000, time.UTC), records[0].Row.Timestamp)
+	assert.NoError(t, records[1].Err)
+	assert.Equal(t, 3, records[1].Row.Line)
+	assert.True(t, decimal.NewFromInt(2200).Equal(records[1].Row.Price))
+	assert.EqualError(t, records[2].Err, "invalid JSON")
+	assert.Equal(t, 4, records[2].Row.Line)
+	assert.EqualError(t, records[3].Err, "symbol is empty")
+}
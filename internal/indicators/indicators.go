@@ -0,0 +1,149 @@
+// Package indicators содержит чистые функции технических индикаторов над рядом цен закрытия.
+// Значения в период прогрева, когда данных ещё недостаточно, равны NaN.
+package indicators
+
+import "math"
+
+// SMA - простая скользящая средняя за period значений. NaN во входе до начала ряда пропускаются.
+func SMA(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	start := firstValid(values)
+	if period < 1 || start < 0 {
+		return out
+	}
+	var sum float64
+	for i := start; i < len(values); i++ {
+		sum += values[i]
+		if i-start >= period {
+			sum -= values[i-period]
+		}
+		if i-start >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+// EMA - экспоненциальная скользящая средняя с множителем 2/(period+1). Первое значение -
+// SMA первых period значений, NaN во входе до начала ряда пропускаются.
+func EMA(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	if period < 1 {
+		return out
+	}
+	start := firstValid(values)
+	if start < 0 || len(values)-start < period {
+		return out
+	}
+	k := 2 / float64(period+1)
+	var seed float64
+	for _, v := range values[start : start+period] {
+		seed += v
+	}
+	prev := seed / float64(period)
+	out[start+period-1] = prev
+	for i := start + period; i < len(values); i++ {
+		prev = (values[i]-prev)*k + prev
+		out[i] = prev
+	}
+	return out
+}
+
+// RSI - индекс относительной силы Уайлдера: средние рост и падение за первые period изменений -
+// простые средние, дальше сглаживаются как (prev*(period-1) + current) / period.
+// NaN во входе до начала ряда пропускаются.
+func RSI(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	start := firstValid(values)
+	if period < 1 || start < 0 || len(values)-start <= period {
+		return out
+	}
+	var gain, loss float64
+	for i := start + 1; i <= start+period; i++ {
+		g, l := change(values[i-1], values[i])
+		gain += g
+		loss += l
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+	out[start+period] = rsi(gain, loss)
+	for i := start + period + 1; i < len(values); i++ {
+		g, l := change(values[i-1], values[i])
+		gain = (gain*float64(period-1) + g) / float64(period)
+		loss = (loss*float64(period-1) + l) / float64(period)
+		out[i] = rsi(gain, loss)
+	}
+	return out
+}
+
+// MACD возвращает линию MACD (EMA fast - EMA slow), сигнальную линию (EMA signal от MACD)
+// и гистограмму (MACD - сигнальная).
+func MACD(values []float64, fast, slow, signal int) (macd, signalLine, histogram []float64) {
+	fastEMA, slowEMA := EMA(values, fast), EMA(values, slow)
+	macd = nanSlice(len(values))
+	for i := range values {
+		macd[i] = fastEMA[i] - slowEMA[i]
+	}
+	signalLine = EMA(macd, signal)
+	histogram = nanSlice(len(values))
+	for i := range values {
+		histogram[i] = macd[i] - signalLine[i]
+	}
+	return macd, signalLine, histogram
+}
+
+// Bollinger возвращает среднюю (SMA за period) и полосы на k стандартных отклонений
+// (генеральных, как в классическом определении) выше и ниже неё.
+func Bollinger(values []float64, period int, k float64) (middle, upper, lower []float64) {
+	middle = SMA(values, period)
+	upper, lower = nanSlice(len(values)), nanSlice(len(values))
+	for i := range values {
+		if math.IsNaN(middle[i]) {
+			continue
+		}
+		var variance float64
+		for _, v := range values[i-period+1 : i+1] {
+			d := v - middle[i]
+			variance += d * d
+		}
+		sd := math.Sqrt(variance / float64(period))
+		upper[i] = middle[i] + k*sd
+		lower[i] = middle[i] - k*sd
+	}
+	return middle, upper, lower
+}
+
+func change(prev, cur float64) (gain, loss float64) {
+	d := cur - prev
+	if d > 0 {
+		return d, 0
+	}
+	return 0, -d
+}
+
+func rsi(gain, loss float64) float64 {
+	if loss == 0 {
+		if gain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+func firstValid(values []float64) int {
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+func nanSlice(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Ряды из учебных примеров StockCharts (ChartSchool): 10-дневная EMA и 14-дневный RSI.
+var (
+	emaCloses = []float64{
+		22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+		22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+	}
+	rsiCloses = []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	}
+)
+
+func assertWarmup(t *testing.T, values []float64, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		assert.True(t, math.IsNaN(values[i]), "index %d must be NaN during warm-up", i)
+	}
+	assert.False(t, math.IsNaN(values[n]), "index %d must be defined after warm-up", n)
+}
+
+func assertRounded(t *testing.T, want []float64, got []float64, places int) {
+	t.Helper()
+	require.Len(t, got, len(want))
+	scale := math.Pow(10, float64(places))
+	for i := range want {
+		assert.Equal(t, want[i], math.Round(got[i]*scale)/scale, "index %d", i)
+	}
+}
+
+func TestSMA(t *testing.T) {
+	sma := SMA([]float64{1, 2, 3, 4, 5}, 3)
+
+	assertWarmup(t, sma, 2)
+	assert.Equal(t, []float64{2, 3, 4}, sma[2:])
+}
+
+func TestSMA_SkipsLeadingNaN(t *testing.T) {
+	sma := SMA([]float64{math.NaN(), 1, 2, 3}, 2)
+
+	assertWarmup(t, sma, 2)
+	assert.Equal(t, []float64{1.5, 2.5}, sma[2:])
+}
+
+func TestEMA(t *testing.T) {
+	ema := EMA(emaCloses, 10)
+
+	assertWarmup(t, ema, 9)
+	// StockCharts: 22.22 (SMA-затравка), 22.21, 22.24, ..., 23.34.
+	assertRounded(t, []float64{22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28, 23.34}, ema[9:], 2)
+	assert.InDelta(t, 23.339801121099786, ema[19], 1e-12)
+}
+
+func TestEMA_SkipsLeadingNaN(t *testing.T) {
+	ema := EMA([]float64{math.NaN(), math.NaN(), 1, 2, 3}, 2)
+
+	assertWarmup(t, ema, 3)
+	assert.Equal(t, 1.5, ema[3])
+	assert.InDelta(t, 2.5, ema[4], 1e-12)
+}
+
+func TestRSI(t *testing.T) {
+	rsi := RSI(rsiCloses, 14)
+
+	assertWarmup(t, rsi, 14)
+	// Точный расчёт по Уайлдеру; StockCharts публикует 70.53, 66.32, ... из-за округления
+	// промежуточных средних до двух знаков.
+	assertRounded(t, []float64{70.46, 66.25, 66.48, 69.35, 66.29, 57.92}, rsi[14:], 2)
+}
+
+func TestRSI_SkipsLeadingNaN(t *testing.T) {
+	rsi := RSI(append([]float64{math.NaN(), math.NaN()}, rsiCloses...), 14)
+
+	assertWarmup(t, rsi, 16)
+	assert.Equal(t, 70.46, math.Round(rsi[16]*100)/100)
+}
+
+func TestRSI_Bounds(t *testing.T) {
+	assert.Equal(t, 100.0, RSI([]float64{1, 2, 3, 4}, 3)[3])
+	assert.Equal(t, 0.0, RSI([]float64{4, 3, 2, 1}, 3)[3])
+	assert.Equal(t, 50.0, RSI([]float64{1, 1, 1, 1}, 3)[3])
+}
+
+func TestMACD(t *testing.T) {
+	macd, signal, hist := MACD(emaCloses, 3, 6, 3)
+
+	assertWarmup(t, macd, 5)
+	assertWarmup(t, signal, 7)
+	assertWarmup(t, hist, 7)
+	assert.InDelta(t, 0.1438498121501688, macd[19], 1e-12)
+	assert.InDelta(t, 0.21557958027566002, signal[19], 1e-12)
+	assert.InDelta(t, -0.07172976812549123, hist[19], 1e-12)
+}
+
+func TestBollinger(t *testing.T) {
+	middle, upper, lower := Bollinger(emaCloses, 5, 2)
+
+	assertWarmup(t, middle, 4)
+	assertWarmup(t, upper, 4)
+	assertWarmup(t, lower, 4)
+	assert.InDelta(t, 23.842, middle[19], 1e-12)
+	assert.InDelta(t, 24.136591242232353, upper[19], 1e-12)
+	assert.InDelta(t, 23.547408757767645, lower[19], 1e-12)
+}
+
+func TestSpec_Normalize(t *testing.T) {
+	spec, err := Spec{Type: "MACD"}.Normalize()
+	require.NoError(t, err)
+	assert.Equal(t, Spec{Type: TypeMACD, Fast: 12, Slow: 26, Signal: 9}, spec)
+	assert.Equal(t, []string{"macd", "signal", "histogram"}, spec.Lines())
+	assert.Equal(t, 4*26+9, spec.Lookback())
+
+	spec, err = Spec{Type: TypeBollinger}.Normalize()
+	require.NoError(t, err)
+	assert.Equal(t, 20, spec.Period)
+	assert.Equal(t, 2.0, spec.K)
+	assert.Equal(t, 19, spec.Lookback())
+
+	_, err = Spec{Type: TypeSMA, Period: MaxPeriod + 1}.Normalize()
+	assert.Error(t, err)
+	_, err = Spec{Type: TypeMACD, Fast: 26, Slow: 12}.Normalize()
+	assert.Error(t, err)
+	_, err = Spec{Type: "vwap"}.Normalize()
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package indicators
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type - вид индикатора.
+type Type string
+
+const (
+	TypeSMA       Type = "sma"
+	TypeEMA       Type = "ema"
+	TypeRSI       Type = "rsi"
+	TypeMACD      Type = "macd"
+	TypeBollinger Type = "bollinger"
+)
+
+// MaxPeriod - верхняя граница периодов, чтобы прогрев не требовал неограниченной истории.
+const MaxPeriod = 500
+
+// smoothingLookback - во сколько периодов истории прогревать EMA-подобные индикаторы, чтобы
+// влияние начального значения практически исчезло.
+const smoothingLookback = 4
+
+// Spec - индикатор и его параметры. Нулевые параметры заменяются значениями по умолчанию.
+type Spec struct {
+	Type   Type
+	Period int
+	Fast   int
+	Slow   int
+	Signal int
+	K      float64
+}
+
+// Normalize проверяет спецификацию и подставляет параметры по умолчанию:
+// SMA/EMA/Bollinger - 20, RSI - 14, MACD - 12/26/9, Bollinger K - 2.
+func (s Spec) Normalize() (Spec, error) {
+	s.Type = Type(strings.ToLower(string(s.Type)))
+	switch s.Type {
+	case TypeSMA, TypeEMA:
+		s.Period = withDefault(s.Period, 20)
+	case TypeRSI:
+		s.Period = withDefault(s.Period, 14)
+	case TypeBollinger:
+		s.Period = withDefault(s.Period, 20)
+		if s.K == 0 {
+			s.K = 2
+		}
+		if s.K < 0 {
+			return Spec{}, fmt.Errorf("k must be positive")
+		}
+	case TypeMACD:
+		s.Fast = withDefault(s.Fast, 12)
+		s.Slow = withDefault(s.Slow, 26)
+		s.Signal = withDefault(s.Signal, 9)
+		if s.Fast >= s.Slow {
+			return Spec{}, fmt.Errorf("fast period must be shorter than slow period")
+		}
+		for _, p := range []int{s.Fast, s.Slow, s.Signal} {
+			if p < 1 || p > MaxPeriod {
+				return Spec{}, fmt.Errorf("periods must be between 1 and %d", MaxPeriod)
+			}
+		}
+		return s, nil
+	default:
+		return Spec{}, fmt.Errorf("unknown indicator type %q", s.Type)
+	}
+	if s.Period < 1 || s.Period > MaxPeriod {
+		return Spec{}, fmt.Errorf("period must be between 1 and %d", MaxPeriod)
+	}
+	return s, nil
+}
+
+// Lines - имена линий, которые возвращает Compute, в том же порядке.
+func (s Spec) Lines() []string {
+	switch s.Type {
+	case TypeMACD:
+		return []string{"macd", "signal", "histogram"}
+	case TypeBollinger:
+		return []string{"middle", "upper", "lower"}
+	default:
+		return []string{string(s.Type)}
+	}
+}
+
+// Lookback - сколько значений до начала интересующего диапазона нужно для прогрева.
+// Для SMA и полос Боллинджера это ровно окно; для сглаженных (EMA, RSI, MACD) - несколько
+// периодов, чтобы значение не зависело от того, с какого места начат расчёт.
+func (s Spec) Lookback() int {
+	switch s.Type {
+	case TypeSMA, TypeBollinger:
+		return s.Period - 1
+	case TypeEMA, TypeRSI:
+		return smoothingLookback * s.Period
+	case TypeMACD:
+		return smoothingLookback*s.Slow + s.Signal
+	default:
+		return 0
+	}
+}
+
+// Params - параметры индикатора для отображения клиенту.
+func (s Spec) Params() map[string]float64 {
+	switch s.Type {
+	case TypeMACD:
+		return map[string]float64{"fast": float64(s.Fast), "slow": float64(s.Slow), "signal": float64(s.Signal)}
+	case TypeBollinger:
+		return map[string]float64{"period": float64(s.Period), "k": s.K}
+	default:
+		return map[string]float64{"period": float64(s.Period)}
+	}
+}
+
+// Compute считает индикатор по ценам закрытия; результат - по срезу на каждую линию из Lines.
+func (s Spec) Compute(closes []float64) [][]float64 {
+	switch s.Type {
+	case TypeSMA:
+		return [][]float64{SMA(closes, s.Period)}
+	case TypeEMA:
+		return [][]float64{EMA(closes, s.Period)}
+	case TypeRSI:
+		return [][]float64{RSI(closes, s.Period)}
+	case TypeMACD:
+		macd, signal, hist := MACD(closes, s.Fast, s.Slow, s.Signal)
+		return [][]float64{macd, signal, hist}
+	case TypeBollinger:
+		middle, upper, lower := Bollinger(closes, s.Period, s.K)
+		return [][]float64{middle, upper, lower}
+	default:
+		return nil
+	}
+}
+
+func withDefault(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
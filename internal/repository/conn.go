@@ -2,44 +2,128 @@ package repository
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/adal4ik/crypto-service/internal/config"
 	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
-
-	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
-func ConnectDB(ctx context.Context, cfg config.PostgresConfig, logger logger.Logger) (*sql.DB, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
-	)
+// DB - часть API pgxpool.Pool, которой пользуются репозитории. Позволяет подменять пул в тестах.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Ping(ctx context.Context) error
+}
+
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
 
-	db, err := sql.Open("pgx", connStr)
+// ConnectDB создаёт пул соединений, не дожидаясь доступности базы:
+// реальное подключение и переподключения отслеживает DBHealth.
+func ConnectDB(ctx context.Context, cfg config.PostgresConfig, logger logger.Logger) (*pgxpool.Pool, error) {
+	poolCfg, err := NewPoolConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open DB: %w", err)
+		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DB pool: %w", err)
+	}
+	logger.Debug("Database pool created, connectivity is checked in background",
+		zap.String("host", poolCfg.ConnConfig.Host),
+		zap.String("database", poolCfg.ConnConfig.Database),
+		zap.Int32("max_conns", poolCfg.MaxConns),
+		zap.Bool("tls", poolCfg.ConnConfig.TLSConfig != nil),
+	)
+	return pool, nil
+}
+
+// NewPoolConfig собирает конфигурацию пула: строка подключения берётся из PostgresDSN,
+// если он задан, иначе из отдельных параметров, а лимиты пула и кэш запросов
+// применяются в обоих случаях.
+func NewPoolConfig(cfg config.PostgresConfig) (*pgxpool.Config, error) {
+	connStr := cfg.PostgresDSN
+	if connStr == "" {
+		connStr = buildConnString(cfg)
+	}
 
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+	poolCfg, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DB config: %w", err)
+	}
+
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, fmt.Errorf("timeout: failed to connect to DB within the deadline: %w", ctx.Err())
-		case <-ticker.C:
-			logger.Debug("Attempting to connect to the database")
-			if err := db.PingContext(ctx); err == nil {
-				logger.Info("Connected to the database successfully", zap.String("dsn", connStr))
-				return db, nil
-			}
+	if cfg.StatementCacheMode != "" {
+		mode, ok := queryExecModes[cfg.StatementCacheMode]
+		if !ok {
+			return nil, fmt.Errorf("unknown statement cache mode %q", cfg.StatementCacheMode)
 		}
+		poolCfg.ConnConfig.DefaultQueryExecMode = mode
 	}
+	if cfg.StatementCacheCapacity > 0 {
+		poolCfg.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
+		poolCfg.ConnConfig.DescriptionCacheCapacity = cfg.StatementCacheCapacity
+	}
+
+	return poolCfg, nil
+}
+
+func buildConnString(cfg config.PostgresConfig) string {
+	params := []string{
+		"host=" + quoteConnValue(cfg.DBHost),
+		"port=" + quoteConnValue(cfg.DBPort),
+		"user=" + quoteConnValue(cfg.DBUser),
+		"password=" + quoteConnValue(cfg.DBPassword),
+		"dbname=" + quoteConnValue(cfg.DBName),
+	}
+	sslMode := cfg.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	params = append(params, "sslmode="+quoteConnValue(sslMode))
+	if cfg.SSLRootCert != "" {
+		params = append(params, "sslrootcert="+quoteConnValue(cfg.SSLRootCert))
+	}
+	if cfg.SSLCert != "" {
+		params = append(params, "sslcert="+quoteConnValue(cfg.SSLCert))
+	}
+	if cfg.SSLKey != "" {
+		params = append(params, "sslkey="+quoteConnValue(cfg.SSLKey))
+	}
+	return strings.Join(params, " ")
+}
+
+// quoteConnValue экранирует значение для строки подключения формата key=value.
+func quoteConnValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
 }
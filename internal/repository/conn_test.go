@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPoolConfig(t *testing.T) {
+	base := config.PostgresConfig{
+		DBHost:     "db",
+		DBPort:     "5432",
+		DBName:     "crypto",
+		DBUser:     "postgres",
+		DBPassword: "it's secret",
+		SSLMode:    "disable",
+	}
+
+	t.Run("builds_from_parts_with_pool_settings", func(t *testing.T) {
+		cfg := base
+		cfg.MaxConns = 25
+		cfg.MinConns = 5
+		cfg.MaxConnLifetime = time.Hour
+		cfg.MaxConnIdleTime = 10 * time.Minute
+		cfg.StatementCacheMode = "describe_exec"
+		cfg.StatementCacheCapacity = 128
+
+		poolCfg, err := NewPoolConfig(cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "db", poolCfg.ConnConfig.Host)
+		assert.Equal(t, "crypto", poolCfg.ConnConfig.Database)
+		assert.Equal(t, "it's secret", poolCfg.ConnConfig.Password)
+		assert.Nil(t, poolCfg.ConnConfig.TLSConfig)
+		assert.Equal(t, int32(25), poolCfg.MaxConns)
+		assert.Equal(t, int32(5), poolCfg.MinConns)
+		assert.Equal(t, time.Hour, poolCfg.MaxConnLifetime)
+		assert.Equal(t, 10*time.Minute, poolCfg.MaxConnIdleTime)
+		assert.Equal(t, pgx.QueryExecModeDescribeExec, poolCfg.ConnConfig.DefaultQueryExecMode)
+		assert.Equal(t, 128, poolCfg.ConnConfig.StatementCacheCapacity)
+	})
+
+	t.Run("dsn_takes_precedence", func(t *testing.T) {
+		cfg := base
+		cfg.PostgresDSN = "postgres://app:pw@managed.example.com:6432/prod?sslmode=require"
+
+		poolCfg, err := NewPoolConfig(cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "managed.example.com", poolCfg.ConnConfig.Host)
+		assert.Equal(t, uint16(6432), poolCfg.ConnConfig.Port)
+		assert.Equal(t, "prod", poolCfg.ConnConfig.Database)
+		assert.NotNil(t, poolCfg.ConnConfig.TLSConfig)
+	})
+
+	t.Run("tls_mode_from_parts", func(t *testing.T) {
+		cfg := base
+		cfg.SSLMode = "require"
+
+		poolCfg, err := NewPoolConfig(cfg)
+
+		require.NoError(t, err)
+		require.NotNil(t, poolCfg.ConnConfig.TLSConfig)
+		assert.Empty(t, poolCfg.ConnConfig.Fallbacks)
+	})
+
+	t.Run("unknown_statement_cache_mode", func(t *testing.T) {
+		cfg := base
+		cfg.StatementCacheMode = "bogus"
+
+		_, err := NewPoolConfig(cfg)
+
+		assert.Error(t, err)
+	})
+}
@@ -2,37 +2,59 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"errors"
+	"net/http"
+	"time"
 
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
 type CurrencyRepositoryInterface interface {
 	Add(ctx context.Context, symbol string) *apperrors.AppError
-	Remove(ctx context.Context, symbol string) *apperrors.AppError
+	Archive(ctx context.Context, symbol string) *apperrors.AppError
+	Resume(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError)
+	Purge(ctx context.Context, symbol string) (int64, *apperrors.AppError)
 	GetAll(ctx context.Context) ([]string, *apperrors.AppError)
+	List(ctx context.Context) ([]domain.Currency, *apperrors.AppError)
+	Get(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError)
+	ListStatuses(ctx context.Context, withCounts bool) ([]domain.CurrencyStatus, *apperrors.AppError)
+	Update(ctx context.Context, symbol string, patch domain.CurrencyPatch) (domain.Currency, *apperrors.AppError)
+	FillMetadata(ctx context.Context, symbol, name, coingeckoID string) (bool, *apperrors.AppError)
+}
+
+// currencyColumns - колонки tracked_currencies в порядке currencyFields.
+const currencyColumns = `c.id, c.symbol, c.created_at, c.name, c.provider_ids, c.display_decimals, c.enabled, c.tags, c.notes, c.archived_at`
+
+// currencyFields возвращает приёмники Scan для currencyColumns.
+func currencyFields(c *domain.Currency) []any {
+	return []any{&c.ID, &c.Symbol, &c.CreatedAt, &c.Name, &c.ProviderIDs, &c.DisplayDecimals, &c.Enabled, &c.Tags, &c.Notes, &c.ArchivedAt}
 }
 
 type CurrencyRepository struct {
-	db     *sql.DB
+	db     DB
 	logger logger.Logger
 }
 
-func NewCurrencyRepository(db *sql.DB, logger logger.Logger) *CurrencyRepository {
+func NewCurrencyRepository(db DB, logger logger.Logger) *CurrencyRepository {
 	return &CurrencyRepository{
 		db:     db,
 		logger: logger,
 	}
 }
+
+// Add начинает отслеживать валюту. Повторное добавление архивной валюты возобновляет её отслеживание.
 func (r *CurrencyRepository) Add(ctx context.Context, symbol string) *apperrors.AppError {
 	l := r.logger.With(zap.String("symbol", symbol), zap.String("layer", "repo"))
 	l.Info("Adding currency to DB")
 
-	query := `INSERT INTO tracked_currencies (symbol) VALUES ($1) ON CONFLICT (symbol) DO NOTHING;`
+	query := `INSERT INTO tracked_currencies (symbol) VALUES ($1) ON CONFLICT (symbol) DO UPDATE SET archived_at = NULL;`
 
-	_, err := r.db.ExecContext(ctx, query, symbol)
+	_, err := r.db.Exec(ctx, query, symbol)
 	if err != nil {
 		l.Error("DB error on add", zap.Error(err))
 		return apperrors.NewInternalServerError("database error", err)
@@ -40,26 +62,91 @@ func (r *CurrencyRepository) Add(ctx context.Context, symbol string) *apperrors.
 	return nil
 }
 
-func (r *CurrencyRepository) Remove(ctx context.Context, symbol string) *apperrors.AppError {
+// Archive прекращает отслеживание валюты, сохраняя её историю. Время архивации
+// при повторном вызове не меняется.
+func (r *CurrencyRepository) Archive(ctx context.Context, symbol string) *apperrors.AppError {
 	l := r.logger.With(zap.String("symbol", symbol), zap.String("layer", "repo"))
-	l.Info("Removing currency from DB")
+	l.Info("Archiving currency in DB")
 
-	query := `DELETE FROM tracked_currencies WHERE symbol = $1;`
+	query := `UPDATE tracked_currencies SET archived_at = NOW() WHERE symbol = $1 AND archived_at IS NULL;`
 
-	_, err := r.db.ExecContext(ctx, query, symbol)
+	_, err := r.db.Exec(ctx, query, symbol)
 	if err != nil {
-		l.Error("DB error on remove", zap.Error(err))
+		l.Error("DB error on archive", zap.Error(err))
 		return apperrors.NewInternalServerError("database error", err)
 	}
 	return nil
 }
 
+// Resume возобновляет отслеживание архивной валюты; 404, если символ не отслеживается.
+func (r *CurrencyRepository) Resume(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", symbol), zap.String("layer", "repo"))
+	l.Info("Resuming currency in DB")
+
+	query := `UPDATE tracked_currencies c SET archived_at = NULL WHERE c.symbol = $1 RETURNING ` + currencyColumns + `;`
+	var c domain.Currency
+	err := r.db.QueryRow(ctx, query, symbol).Scan(currencyFields(&c)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Currency{}, apperrors.NewNotFound("currency is not tracked", err)
+		}
+		l.Error("DB error on resume", zap.Error(err))
+		return domain.Currency{}, apperrors.NewInternalServerError("database error", err)
+	}
+	return c, nil
+}
+
+// Purge безвозвратно удаляет архивную валюту вместе с историей и возвращает число удалённых цен.
+// 404, если символ не отслеживается, 409 - если валюта не в архиве.
+func (r *CurrencyRepository) Purge(ctx context.Context, symbol string) (int64, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", symbol), zap.String("layer", "repo"))
+	l.Info("Purging currency from DB")
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		l.Error("DB error on begin purge", zap.Error(err))
+		return 0, apperrors.NewInternalServerError("database error", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	var archivedAt *time.Time
+	err = tx.QueryRow(ctx, `SELECT id, archived_at FROM tracked_currencies WHERE symbol = $1 FOR UPDATE;`, symbol).Scan(&id, &archivedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, apperrors.NewNotFound("currency is not tracked", err)
+		}
+		l.Error("DB error on lock currency for purge", zap.Error(err))
+		return 0, apperrors.NewInternalServerError("database error", err)
+	}
+	if archivedAt == nil {
+		return 0, apperrors.New(http.StatusConflict, "currency must be archived before purge", nil)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM price_history WHERE currency_id = $1;`, id)
+	if err != nil {
+		l.Error("DB error on purge history", zap.Error(err))
+		return 0, apperrors.NewInternalServerError("database error", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM tracked_currencies WHERE id = $1;`, id); err != nil {
+		l.Error("DB error on purge currency", zap.Error(err))
+		return 0, apperrors.NewInternalServerError("database error", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		l.Error("DB error on commit purge", zap.Error(err))
+		return 0, apperrors.NewInternalServerError("database error", err)
+	}
+	l.Warn("currency purged", zap.Int64("deleted_samples", tag.RowsAffected()))
+	return tag.RowsAffected(), nil
+}
+
 func (r *CurrencyRepository) GetAll(ctx context.Context) ([]string, *apperrors.AppError) {
 	l := r.logger.With(zap.String("layer", "repo"))
 	l.Info("Getting all tracked currencies from DB")
 
 	query := `SELECT symbol FROM tracked_currencies;`
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		l.Error("DB error on get all", zap.Error(err))
 		return nil, apperrors.NewInternalServerError("database error", err)
@@ -75,6 +162,168 @@ func (r *CurrencyRepository) GetAll(ctx context.Context) ([]string, *apperrors.A
 		}
 		symbols = append(symbols, symbol)
 	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate symbols", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
 
 	return symbols, nil
 }
+
+// List возвращает отслеживаемые валюты по алфавиту.
+func (r *CurrencyRepository) List(ctx context.Context) ([]domain.Currency, *apperrors.AppError) {
+	l := r.logger.With(zap.String("layer", "repo"))
+	l.Info("Listing tracked currencies from DB")
+
+	query := `SELECT ` + currencyColumns + ` FROM tracked_currencies c ORDER BY c.symbol;`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		l.Error("DB error on list", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	var currencies []domain.Currency
+	for rows.Next() {
+		var c domain.Currency
+		if err := rows.Scan(currencyFields(&c)...); err != nil {
+			l.Error("DB error on scan currency", zap.Error(err))
+			return nil, apperrors.NewInternalServerError("database error", err)
+		}
+		currencies = append(currencies, c)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate currencies", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	return currencies, nil
+}
+
+// Get возвращает отслеживаемую валюту; 404, если символ не отслеживается.
+func (r *CurrencyRepository) Get(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", symbol), zap.String("layer", "repo"))
+	l.Info("Getting currency from DB")
+
+	query := `SELECT ` + currencyColumns + ` FROM tracked_currencies c WHERE c.symbol = $1;`
+	var c domain.Currency
+	err := r.db.QueryRow(ctx, query, symbol).Scan(currencyFields(&c)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Currency{}, apperrors.NewNotFound("currency is not tracked", err)
+		}
+		l.Error("DB error on get", zap.Error(err))
+		return domain.Currency{}, apperrors.NewInternalServerError("database error", err)
+	}
+	return c, nil
+}
+
+// ListStatuses возвращает валюты по алфавиту вместе с границами их истории. Первый и последний
+// сэмплы - проходы по индексу (currency_id, timestamp) с LIMIT 1, поэтому не зависят от длины истории.
+// Число сэмплов требует полного прохода по истории каждой валюты и считается только при withCounts,
+// иначе SampleCount равен nil.
+func (r *CurrencyRepository) ListStatuses(ctx context.Context, withCounts bool) ([]domain.CurrencyStatus, *apperrors.AppError) {
+	l := r.logger.With(zap.Bool("with_counts", withCounts), zap.String("layer", "repo"))
+	l.Info("Listing currency statuses from DB")
+
+	count, countJoin := "NULL::bigint", ""
+	if withCounts {
+		count = "h.sample_count"
+		countJoin = `
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS sample_count
+			FROM price_history p
+			WHERE p.currency_id = c.id
+		) h`
+	}
+	query := `
+		SELECT ` + currencyColumns + `, f.timestamp, l.timestamp, ` + count + `
+		FROM tracked_currencies c
+		LEFT JOIN LATERAL (
+			SELECT timestamp
+			FROM price_history p
+			WHERE p.currency_id = c.id
+			ORDER BY timestamp ASC
+			LIMIT 1
+		) f ON true
+		LEFT JOIN LATERAL (
+			SELECT timestamp
+			FROM price_history p
+			WHERE p.currency_id = c.id
+			ORDER BY timestamp DESC
+			LIMIT 1
+		) l ON true` + countJoin + `
+		ORDER BY c.symbol;`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		l.Error("DB error on list statuses", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	var statuses []domain.CurrencyStatus
+	for rows.Next() {
+		var s domain.CurrencyStatus
+		if err := rows.Scan(append(currencyFields(&s.Currency), &s.FirstSample, &s.LastSample, &s.SampleCount)...); err != nil {
+			l.Error("DB error on scan currency status", zap.Error(err))
+			return nil, apperrors.NewInternalServerError("database error", err)
+		}
+		statuses = append(statuses, s)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate currency statuses", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	return statuses, nil
+}
+
+// Update меняет метаданные валюты и возвращает её новое состояние; 404, если символ не отслеживается.
+func (r *CurrencyRepository) Update(ctx context.Context, symbol string, patch domain.CurrencyPatch) (domain.Currency, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", symbol), zap.String("layer", "repo"))
+	l.Info("Updating currency metadata in DB")
+
+	query := `
+		UPDATE tracked_currencies c SET
+			name = COALESCE($2, c.name),
+			provider_ids = COALESCE($3, c.provider_ids),
+			display_decimals = COALESCE($4, c.display_decimals),
+			enabled = COALESCE($5, c.enabled),
+			tags = COALESCE($6, c.tags),
+			notes = COALESCE($7, c.notes)
+		WHERE c.symbol = $1
+		RETURNING ` + currencyColumns + `;`
+	var c domain.Currency
+	err := r.db.QueryRow(ctx, query, symbol, patch.Name, patch.ProviderIDs, patch.DisplayDecimals, patch.Enabled, patch.Tags, patch.Notes).
+		Scan(currencyFields(&c)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Currency{}, apperrors.NewNotFound("currency is not tracked", err)
+		}
+		l.Error("DB error on update", zap.Error(err))
+		return domain.Currency{}, apperrors.NewInternalServerError("database error", err)
+	}
+	return c, nil
+}
+
+// FillMetadata записывает имя и ID у CoinGecko, только если имя валюты всё ещё пустое, а её ID у CoinGecko
+// не задан или совпадает с coingeckoID; остальные ключи provider_ids не трогаются. Возвращает false,
+// если строка успела измениться и запись пропущена.
+func (r *CurrencyRepository) FillMetadata(ctx context.Context, symbol, name, coingeckoID string) (bool, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", symbol), zap.String("layer", "repo"))
+	l.Info("Filling currency metadata in DB")
+
+	query := `
+		UPDATE tracked_currencies SET
+			name = $2,
+			provider_ids = provider_ids || jsonb_build_object('coingecko', $3::text)
+		WHERE symbol = $1
+			AND (name IS NULL OR name = '')
+			AND COALESCE(provider_ids->>'coingecko', '') IN ('', $3);`
+	tag, err := r.db.Exec(ctx, query, symbol, name, coingeckoID)
+	if err != nil {
+		l.Error("DB error on metadata fill", zap.Error(err))
+		return false, apperrors.NewInternalServerError("database error", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -3,11 +3,16 @@ package repository
 import (
 	"context"
 	"errors"
+	"net/http"
 	"regexp"
 	"testing"
+	"time"
 
-	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -17,15 +22,15 @@ func TestCurrencyRepository_Add(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
-		defer db.Close()
+		defer mock.Close()
 
-		repo := NewCurrencyRepository(db, nopLogger)
+		repo := NewCurrencyRepository(mock, nopLogger)
 		symbol := "BTC"
-		query := regexp.QuoteMeta(`INSERT INTO tracked_currencies (symbol) VALUES ($1) ON CONFLICT (symbol) DO NOTHING;`)
+		query := regexp.QuoteMeta(`INSERT INTO tracked_currencies (symbol) VALUES ($1) ON CONFLICT (symbol) DO UPDATE SET archived_at = NULL;`)
 
-		mock.ExpectExec(query).WithArgs(symbol).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(query).WithArgs(symbol).WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		appErr := repo.Add(ctx, symbol)
 
@@ -34,13 +39,13 @@ func TestCurrencyRepository_Add(t *testing.T) {
 	})
 
 	t.Run("failure_db_error", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
-		defer db.Close()
+		defer mock.Close()
 
-		repo := NewCurrencyRepository(db, nopLogger)
+		repo := NewCurrencyRepository(mock, nopLogger)
 		symbol := "ETH"
-		query := regexp.QuoteMeta(`INSERT INTO tracked_currencies (symbol) VALUES ($1) ON CONFLICT (symbol) DO NOTHING;`)
+		query := regexp.QuoteMeta(`INSERT INTO tracked_currencies (symbol) VALUES ($1) ON CONFLICT (symbol) DO UPDATE SET archived_at = NULL;`)
 		dbError := errors.New("db is down")
 
 		mock.ExpectExec(query).WithArgs(symbol).WillReturnError(dbError)
@@ -53,22 +58,22 @@ func TestCurrencyRepository_Add(t *testing.T) {
 	})
 }
 
-func TestCurrencyRepository_Remove(t *testing.T) {
+func TestCurrencyRepository_Archive(t *testing.T) {
 	nopLogger := logger.NewNopLogger()
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
-		defer db.Close()
+		defer mock.Close()
 
-		repo := NewCurrencyRepository(db, nopLogger)
+		repo := NewCurrencyRepository(mock, nopLogger)
 		symbol := "BTC"
-		query := regexp.QuoteMeta(`DELETE FROM tracked_currencies WHERE symbol = $1;`)
+		query := regexp.QuoteMeta(`UPDATE tracked_currencies SET archived_at = NOW() WHERE symbol = $1 AND archived_at IS NULL;`)
 
-		mock.ExpectExec(query).WithArgs(symbol).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(query).WithArgs(symbol).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
-		appErr := repo.Remove(ctx, symbol)
+		appErr := repo.Archive(ctx, symbol)
 
 		assert.Nil(t, appErr)
 		require.NoError(t, mock.ExpectationsWereMet())
@@ -80,15 +85,15 @@ func TestCurrencyRepository_GetAll(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
-		defer db.Close()
+		defer mock.Close()
 
-		repo := NewCurrencyRepository(db, nopLogger)
+		repo := NewCurrencyRepository(mock, nopLogger)
 		query := regexp.QuoteMeta(`SELECT symbol FROM tracked_currencies;`)
 
 		expectedSymbols := []string{"BTC", "ETH"}
-		rows := sqlmock.NewRows([]string{"symbol"}).
+		rows := pgxmock.NewRows([]string{"symbol"}).
 			AddRow(expectedSymbols[0]).
 			AddRow(expectedSymbols[1])
 
@@ -102,11 +107,11 @@ func TestCurrencyRepository_GetAll(t *testing.T) {
 	})
 
 	t.Run("failure_db_error_on_query", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
-		defer db.Close()
+		defer mock.Close()
 
-		repo := NewCurrencyRepository(db, nopLogger)
+		repo := NewCurrencyRepository(mock, nopLogger)
 		query := regexp.QuoteMeta(`SELECT symbol FROM tracked_currencies;`)
 		dbError := errors.New("query failed")
 
@@ -119,3 +124,318 @@ func TestCurrencyRepository_GetAll(t *testing.T) {
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+var currencyColumnNames = []string{"id", "symbol", "created_at", "name", "provider_ids", "display_decimals", "enabled", "tags", "notes", "archived_at"}
+
+func currencyValues(c domain.Currency) []any {
+	return []any{c.ID, c.Symbol, c.CreatedAt, c.Name, c.ProviderIDs, c.DisplayDecimals, c.Enabled, c.Tags, c.Notes, c.ArchivedAt}
+}
+
+func TestCurrencyRepository_Get(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	query := regexp.QuoteMeta(`SELECT ` + currencyColumns + ` FROM tracked_currencies c WHERE c.symbol = $1;`)
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		want := domain.Currency{
+			ID:              uuid.New(),
+			Symbol:          "BTC",
+			CreatedAt:       time.Unix(1700000000, 0),
+			Name:            "Bitcoin",
+			ProviderIDs:     map[string]string{"coingecko": "bitcoin"},
+			DisplayDecimals: 2,
+			Enabled:         true,
+			Tags:            []string{"layer-1"},
+		}
+		mock.ExpectQuery(query).WithArgs("BTC").
+			WillReturnRows(pgxmock.NewRows(currencyColumnNames).AddRow(currencyValues(want)...))
+
+		currency, appErr := repo.Get(ctx, "BTC")
+
+		assert.Nil(t, appErr)
+		assert.Equal(t, want, currency)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not_tracked", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		mock.ExpectQuery(query).WithArgs("DOGE").WillReturnError(pgx.ErrNoRows)
+
+		_, appErr := repo.Get(ctx, "DOGE")
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCurrencyRepository_List(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCurrencyRepository(mock, nopLogger)
+	createdAt := time.Unix(1700000000, 0)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + currencyColumns + ` FROM tracked_currencies c ORDER BY c.symbol;`)).
+		WillReturnRows(pgxmock.NewRows(currencyColumnNames).
+			AddRow(currencyValues(domain.Currency{ID: uuid.New(), Symbol: "BTC", CreatedAt: createdAt, Enabled: true})...).
+			AddRow(currencyValues(domain.Currency{ID: uuid.New(), Symbol: "ETH", CreatedAt: createdAt, Enabled: false})...))
+
+	currencies, appErr := repo.List(ctx)
+
+	assert.Nil(t, appErr)
+	require.Len(t, currencies, 2)
+	assert.Equal(t, "ETH", currencies[1].Symbol)
+	assert.False(t, currencies[1].Enabled)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCurrencyRepository_ListStatuses(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	createdAt := time.Unix(1700000000, 0)
+	first, last := time.Unix(1700000060, 0), time.Unix(1700003600, 0)
+	columns := append(currencyColumnNames, "timestamp", "timestamp", "sample_count")
+	sixty, zero := int64(60), int64(0)
+
+	t.Run("with_counts", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		mock.ExpectQuery(`ORDER BY timestamp DESC\s+LIMIT 1\s+\) l ON true\s+CROSS JOIN LATERAL \(\s+SELECT COUNT\(\*\)`).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(append(currencyValues(domain.Currency{ID: uuid.New(), Symbol: "BTC", CreatedAt: createdAt}), &first, &last, &sixty)...).
+				AddRow(append(currencyValues(domain.Currency{ID: uuid.New(), Symbol: "DOGE", CreatedAt: createdAt}), (*time.Time)(nil), (*time.Time)(nil), &zero)...))
+
+		statuses, appErr := repo.ListStatuses(ctx, true)
+
+		assert.Nil(t, appErr)
+		require.Len(t, statuses, 2)
+		require.NotNil(t, statuses[0].LastSample)
+		assert.True(t, last.Equal(*statuses[0].LastSample))
+		require.NotNil(t, statuses[0].SampleCount)
+		assert.Equal(t, int64(60), *statuses[0].SampleCount)
+		assert.Nil(t, statuses[1].FirstSample)
+		require.NotNil(t, statuses[1].SampleCount)
+		assert.Zero(t, *statuses[1].SampleCount)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("without_counts_probes_index_only", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		mock.ExpectQuery(`NULL::bigint\s+FROM tracked_currencies c\s+LEFT JOIN LATERAL .* ORDER BY timestamp ASC\s+LIMIT 1\s+\) f ON true\s+LEFT JOIN LATERAL .* ORDER BY timestamp DESC\s+LIMIT 1\s+\) l ON true\s+ORDER BY c.symbol`).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(append(currencyValues(domain.Currency{ID: uuid.New(), Symbol: "BTC", CreatedAt: createdAt}), &first, &last, (*int64)(nil))...))
+
+		statuses, appErr := repo.ListStatuses(ctx, false)
+
+		assert.Nil(t, appErr)
+		require.Len(t, statuses, 1)
+		require.NotNil(t, statuses[0].FirstSample)
+		assert.True(t, first.Equal(*statuses[0].FirstSample))
+		assert.Nil(t, statuses[0].SampleCount)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCurrencyRepository_Update(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	query := `UPDATE tracked_currencies c SET`
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		name, enabled := "Solana", false
+		patch := domain.CurrencyPatch{Name: &name, Enabled: &enabled, Tags: []string{}}
+		want := domain.Currency{ID: uuid.New(), Symbol: "SOL", CreatedAt: time.Unix(1700000000, 0), Name: name, DisplayDecimals: 4, Tags: []string{}}
+		mock.ExpectQuery(query).
+			WithArgs("SOL", patch.Name, patch.ProviderIDs, patch.DisplayDecimals, patch.Enabled, patch.Tags, patch.Notes).
+			WillReturnRows(pgxmock.NewRows(currencyColumnNames).AddRow(currencyValues(want)...))
+
+		currency, appErr := repo.Update(ctx, "SOL", patch)
+
+		assert.Nil(t, appErr)
+		assert.Equal(t, want, currency)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not_tracked", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		mock.ExpectQuery(query).
+			WithArgs("DOGE", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, appErr := repo.Update(ctx, "DOGE", domain.CurrencyPatch{})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCurrencyRepository_Resume(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	query := regexp.QuoteMeta(`UPDATE tracked_currencies c SET archived_at = NULL WHERE c.symbol = $1 RETURNING ` + currencyColumns + `;`)
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		want := domain.Currency{ID: uuid.New(), Symbol: "BTC", CreatedAt: time.Unix(1700000000, 0), Enabled: true}
+		mock.ExpectQuery(query).WithArgs("BTC").
+			WillReturnRows(pgxmock.NewRows(currencyColumnNames).AddRow(currencyValues(want)...))
+
+		currency, appErr := repo.Resume(ctx, "BTC")
+
+		assert.Nil(t, appErr)
+		assert.Equal(t, want, currency)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not_tracked", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		mock.ExpectQuery(query).WithArgs("DOGE").WillReturnError(pgx.ErrNoRows)
+
+		_, appErr := repo.Resume(ctx, "DOGE")
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCurrencyRepository_Purge(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	lockQuery := regexp.QuoteMeta(`SELECT id, archived_at FROM tracked_currencies WHERE symbol = $1 FOR UPDATE;`)
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		id := uuid.New()
+		archivedAt := time.Unix(1700000000, 0)
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs("BTC").
+			WillReturnRows(pgxmock.NewRows([]string{"id", "archived_at"}).AddRow(id, &archivedAt))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM price_history WHERE currency_id = $1;`)).WithArgs(id).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1440))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM tracked_currencies WHERE id = $1;`)).WithArgs(id).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectCommit()
+
+		deleted, appErr := repo.Purge(ctx, "BTC")
+
+		assert.Nil(t, appErr)
+		assert.Equal(t, int64(1440), deleted)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not_archived", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs("ETH").
+			WillReturnRows(pgxmock.NewRows([]string{"id", "archived_at"}).AddRow(uuid.New(), (*time.Time)(nil)))
+		mock.ExpectRollback()
+
+		_, appErr := repo.Purge(ctx, "ETH")
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusConflict, appErr.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not_tracked", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs("DOGE").WillReturnError(pgx.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, appErr := repo.Purge(ctx, "DOGE")
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCurrencyRepository_FillMetadata(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	query := `UPDATE tracked_currencies SET\s+name = \$2,\s+provider_ids = provider_ids \|\| jsonb_build_object\('coingecko', \$3::text\)\s+` +
+		`WHERE symbol = \$1\s+AND \(name IS NULL OR name = ''\)\s+AND COALESCE\(provider_ids->>'coingecko', ''\) IN \('', \$3\);`
+
+	t.Run("filled", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewCurrencyRepository(mock, nopLogger)
+		mock.ExpectExec(query).WithArgs("SOL", "Solana", "solana").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		filled, appErr := repo.FillMetadata(ctx, "SOL", "Solana", "solana")
+
+		assert.Nil(t, appErr)
+		assert.True(t, filled)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("row_changed_after_list", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		// Имя успели задать через PATCH после List: условие UPDATE не выполняется, строка не меняется.
+		repo := NewCurrencyRepository(mock, nopLogger)
+		mock.ExpectExec(query).WithArgs("SOL", "Solana", "solana").WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		filled, appErr := repo.FillMetadata(ctx, "SOL", "Solana", "solana")
+
+		assert.Nil(t, appErr)
+		assert.False(t, filled)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	healthCheckInterval = 10 * time.Second
+	reconnectMinBackoff = 1 * time.Second
+	reconnectMaxBackoff = 30 * time.Second
+	pingTimeout         = 5 * time.Second
+)
+
+type HealthCheckerInterface interface {
+	Status() domain.HealthStatus
+}
+
+// DBHealth периодически пингует базу и хранит текущее состояние подключения.
+// Пока база недоступна, проверки идут с экспоненциальной задержкой.
+type DBHealth struct {
+	db     DB
+	logger logger.Logger
+
+	mu     sync.RWMutex
+	status domain.HealthStatus
+}
+
+func NewDBHealth(db DB, logger logger.Logger) *DBHealth {
+	return &DBHealth{
+		db:     db,
+		logger: logger,
+		status: domain.HealthStatus{
+			Reason: "database connection not established yet",
+			Since:  time.Now(),
+		},
+	}
+}
+
+// Run проверяет подключение до отмены контекста.
+func (h *DBHealth) Run(ctx context.Context) {
+	backoff := reconnectMinBackoff
+	for {
+		wait := healthCheckInterval
+		if !h.Check(ctx) {
+			wait = backoff
+			backoff *= 2
+			if backoff > reconnectMaxBackoff {
+				backoff = reconnectMaxBackoff
+			}
+		} else {
+			backoff = reconnectMinBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Check выполняет одну проверку и обновляет состояние. Возвращает true, если база доступна.
+func (h *DBHealth) Check(ctx context.Context) bool {
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	err := h.db.Ping(pingCtx)
+
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	wasHealthy := h.status.Healthy
+	h.status.LastCheck = now
+	if err != nil {
+		h.status.Healthy = false
+		h.status.Reason = "database unreachable: " + err.Error()
+		if wasHealthy {
+			h.status.Since = now
+			h.logger.Error("Database connection lost, switching to degraded mode", zap.Error(err))
+		} else {
+			h.logger.Debug("Database still unreachable", zap.Error(err))
+		}
+		return false
+	}
+
+	h.status.Healthy = true
+	h.status.Reason = ""
+	if !wasHealthy {
+		h.status.Since = now
+		h.logger.Info("Connected to the database successfully")
+	}
+	return true
+}
+
+func (h *DBHealth) Status() domain.HealthStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.status
+}
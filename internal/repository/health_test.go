@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBHealth_Check(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	t.Run("starts_degraded_and_recovers", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		health := NewDBHealth(mock, nopLogger)
+		assert.False(t, health.Status().Healthy)
+		assert.NotEmpty(t, health.Status().Reason)
+
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		assert.False(t, health.Check(ctx))
+		assert.Contains(t, health.Status().Reason, "connection refused")
+
+		mock.ExpectPing()
+		assert.True(t, health.Check(ctx))
+		status := health.Status()
+		assert.True(t, status.Healthy)
+		assert.Empty(t, status.Reason)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("connection_lost", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		health := NewDBHealth(mock, nopLogger)
+		mock.ExpectPing()
+		require.True(t, health.Check(ctx))
+		healthySince := health.Status().Since
+
+		mock.ExpectPing().WillReturnError(errors.New("server closed the connection"))
+		assert.False(t, health.Check(ctx))
+
+		status := health.Status()
+		assert.False(t, status.Healthy)
+		assert.False(t, status.Since.Before(healthySince))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
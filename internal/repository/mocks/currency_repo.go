@@ -7,6 +7,8 @@ import (
 
 	apperrors "github.com/adal4ik/crypto-service/pkg/apperrors"
 
+	domain "github.com/adal4ik/crypto-service/internal/domain"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -35,6 +37,86 @@ func (_m *CurrencyRepositoryInterface) Add(ctx context.Context, symbol string) *
 	return r0
 }
 
+// Archive provides a mock function with given fields: ctx, symbol
+func (_m *CurrencyRepositoryInterface) Archive(ctx context.Context, symbol string) *apperrors.AppError {
+	ret := _m.Called(ctx, symbol)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Archive")
+	}
+
+	var r0 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, string) *apperrors.AppError); ok {
+		r0 = rf(ctx, symbol)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apperrors.AppError)
+		}
+	}
+
+	return r0
+}
+
+// FillMetadata provides a mock function with given fields: ctx, symbol, name, coingeckoID
+func (_m *CurrencyRepositoryInterface) FillMetadata(ctx context.Context, symbol string, name string, coingeckoID string) (bool, *apperrors.AppError) {
+	ret := _m.Called(ctx, symbol, name, coingeckoID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FillMetadata")
+	}
+
+	var r0 bool
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (bool, *apperrors.AppError)); ok {
+		return rf(ctx, symbol, name, coingeckoID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) bool); ok {
+		r0 = rf(ctx, symbol, name, coingeckoID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) *apperrors.AppError); ok {
+		r1 = rf(ctx, symbol, name, coingeckoID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: ctx, symbol
+func (_m *CurrencyRepositoryInterface) Get(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError) {
+	ret := _m.Called(ctx, symbol)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 domain.Currency
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.Currency, *apperrors.AppError)); ok {
+		return rf(ctx, symbol)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Currency); ok {
+		r0 = rf(ctx, symbol)
+	} else {
+		r0 = ret.Get(0).(domain.Currency)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) *apperrors.AppError); ok {
+		r1 = rf(ctx, symbol)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // GetAll provides a mock function with given fields: ctx
 func (_m *CurrencyRepositoryInterface) GetAll(ctx context.Context) ([]string, *apperrors.AppError) {
 	ret := _m.Called(ctx)
@@ -67,24 +149,158 @@ func (_m *CurrencyRepositoryInterface) GetAll(ctx context.Context) ([]string, *a
 	return r0, r1
 }
 
-// Remove provides a mock function with given fields: ctx, symbol
-func (_m *CurrencyRepositoryInterface) Remove(ctx context.Context, symbol string) *apperrors.AppError {
+// List provides a mock function with given fields: ctx
+func (_m *CurrencyRepositoryInterface) List(ctx context.Context) ([]domain.Currency, *apperrors.AppError) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.Currency
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Currency, *apperrors.AppError)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.Currency); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Currency)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) *apperrors.AppError); ok {
+		r1 = rf(ctx)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// ListStatuses provides a mock function with given fields: ctx, withCounts
+func (_m *CurrencyRepositoryInterface) ListStatuses(ctx context.Context, withCounts bool) ([]domain.CurrencyStatus, *apperrors.AppError) {
+	ret := _m.Called(ctx, withCounts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListStatuses")
+	}
+
+	var r0 []domain.CurrencyStatus
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, bool) ([]domain.CurrencyStatus, *apperrors.AppError)); ok {
+		return rf(ctx, withCounts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bool) []domain.CurrencyStatus); ok {
+		r0 = rf(ctx, withCounts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.CurrencyStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bool) *apperrors.AppError); ok {
+		r1 = rf(ctx, withCounts)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, symbol
+func (_m *CurrencyRepositoryInterface) Purge(ctx context.Context, symbol string) (int64, *apperrors.AppError) {
 	ret := _m.Called(ctx, symbol)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Remove")
+		panic("no return value specified for Purge")
 	}
 
-	var r0 *apperrors.AppError
-	if rf, ok := ret.Get(0).(func(context.Context, string) *apperrors.AppError); ok {
+	var r0 int64
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, *apperrors.AppError)); ok {
+		return rf(ctx, symbol)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
 		r0 = rf(ctx, symbol)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*apperrors.AppError)
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) *apperrors.AppError); ok {
+		r1 = rf(ctx, symbol)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
 		}
 	}
 
-	return r0
+	return r0, r1
+}
+
+// Resume provides a mock function with given fields: ctx, symbol
+func (_m *CurrencyRepositoryInterface) Resume(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError) {
+	ret := _m.Called(ctx, symbol)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Resume")
+	}
+
+	var r0 domain.Currency
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.Currency, *apperrors.AppError)); ok {
+		return rf(ctx, symbol)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Currency); ok {
+		r0 = rf(ctx, symbol)
+	} else {
+		r0 = ret.Get(0).(domain.Currency)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) *apperrors.AppError); ok {
+		r1 = rf(ctx, symbol)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, symbol, patch
+func (_m *CurrencyRepositoryInterface) Update(ctx context.Context, symbol string, patch domain.CurrencyPatch) (domain.Currency, *apperrors.AppError) {
+	ret := _m.Called(ctx, symbol, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 domain.Currency
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.CurrencyPatch) (domain.Currency, *apperrors.AppError)); ok {
+		return rf(ctx, symbol, patch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.CurrencyPatch) domain.Currency); ok {
+		r0 = rf(ctx, symbol, patch)
+	} else {
+		r0 = ret.Get(0).(domain.Currency)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.CurrencyPatch) *apperrors.AppError); ok {
+		r1 = rf(ctx, symbol, patch)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
 }
 
 // NewCurrencyRepositoryInterface creates a new instance of CurrencyRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -7,6 +7,8 @@ import (
 
 	apperrors "github.com/adal4ik/crypto-service/pkg/apperrors"
 
+	domain "github.com/adal4ik/crypto-service/internal/domain"
+
 	decimal "github.com/shopspring/decimal"
 
 	mock "github.com/stretchr/testify/mock"
@@ -39,6 +41,143 @@ func (_m *PriceRepositoryInterface) Add(ctx context.Context, symbol string, pric
 	return r0
 }
 
+// GetBracketing provides a mock function with given fields: ctx, symbol, timestamp
+func (_m *PriceRepositoryInterface) GetBracketing(ctx context.Context, symbol string, timestamp time.Time) (*domain.PriceSample, *domain.PriceSample, *apperrors.AppError) {
+	ret := _m.Called(ctx, symbol, timestamp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBracketing")
+	}
+
+	var r0 *domain.PriceSample
+	var r1 *domain.PriceSample
+	var r2 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (*domain.PriceSample, *domain.PriceSample, *apperrors.AppError)); ok {
+		return rf(ctx, symbol, timestamp)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) *domain.PriceSample); ok {
+		r0 = rf(ctx, symbol, timestamp)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PriceSample)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) *domain.PriceSample); ok {
+		r1 = rf(ctx, symbol, timestamp)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*domain.PriceSample)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, time.Time) *apperrors.AppError); ok {
+		r2 = rf(ctx, symbol, timestamp)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// GetBucketEdges provides a mock function with given fields: ctx, query
+func (_m *PriceRepositoryInterface) GetBucketEdges(ctx context.Context, query domain.BucketQuery) ([]domain.PriceBucket, *apperrors.AppError) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBucketEdges")
+	}
+
+	var r0 []domain.PriceBucket
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BucketQuery) ([]domain.PriceBucket, *apperrors.AppError)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BucketQuery) []domain.PriceBucket); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PriceBucket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.BucketQuery) *apperrors.AppError); ok {
+		r1 = rf(ctx, query)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetCandles provides a mock function with given fields: ctx, query
+func (_m *PriceRepositoryInterface) GetCandles(ctx context.Context, query domain.CandleQuery) ([]domain.Candle, *apperrors.AppError) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCandles")
+	}
+
+	var r0 []domain.Candle
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CandleQuery) ([]domain.Candle, *apperrors.AppError)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CandleQuery) []domain.Candle); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Candle)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CandleQuery) *apperrors.AppError); ok {
+		r1 = rf(ctx, query)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetLatest provides a mock function with given fields: ctx, symbols
+func (_m *PriceRepositoryInterface) GetLatest(ctx context.Context, symbols []string) ([]domain.LatestPrice, *apperrors.AppError) {
+	ret := _m.Called(ctx, symbols)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatest")
+	}
+
+	var r0 []domain.LatestPrice
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]domain.LatestPrice, *apperrors.AppError)); ok {
+		return rf(ctx, symbols)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []domain.LatestPrice); ok {
+		r0 = rf(ctx, symbols)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.LatestPrice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) *apperrors.AppError); ok {
+		r1 = rf(ctx, symbols)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // GetNearest provides a mock function with given fields: ctx, symbol, timestamp
 func (_m *PriceRepositoryInterface) GetNearest(ctx context.Context, symbol string, timestamp time.Time) (decimal.Decimal, time.Time, *apperrors.AppError) {
 	ret := _m.Called(ctx, symbol, timestamp)
@@ -76,6 +215,184 @@ func (_m *PriceRepositoryInterface) GetNearest(ctx context.Context, symbol strin
 	return r0, r1, r2
 }
 
+// GetNearestBatch provides a mock function with given fields: ctx, lookups
+func (_m *PriceRepositoryInterface) GetNearestBatch(ctx context.Context, lookups []domain.PriceLookup) ([]domain.PriceLookupResult, *apperrors.AppError) {
+	ret := _m.Called(ctx, lookups)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNearestBatch")
+	}
+
+	var r0 []domain.PriceLookupResult
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.PriceLookup) ([]domain.PriceLookupResult, *apperrors.AppError)); ok {
+		return rf(ctx, lookups)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.PriceLookup) []domain.PriceLookupResult); ok {
+		r0 = rf(ctx, lookups)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PriceLookupResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []domain.PriceLookup) *apperrors.AppError); ok {
+		r1 = rf(ctx, lookups)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetRange provides a mock function with given fields: ctx, query
+func (_m *PriceRepositoryInterface) GetRange(ctx context.Context, query domain.PriceRangeQuery) ([]domain.PriceSample, *apperrors.AppError) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRange")
+	}
+
+	var r0 []domain.PriceSample
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PriceRangeQuery) ([]domain.PriceSample, *apperrors.AppError)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PriceRangeQuery) []domain.PriceSample); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PriceSample)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.PriceRangeQuery) *apperrors.AppError); ok {
+		r1 = rf(ctx, query)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetStats provides a mock function with given fields: ctx, req
+func (_m *PriceRepositoryInterface) GetStats(ctx context.Context, req domain.StatsRequest) (domain.PriceStats, *apperrors.AppError) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStats")
+	}
+
+	var r0 domain.PriceStats
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, domain.StatsRequest) (domain.PriceStats, *apperrors.AppError)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.StatsRequest) domain.PriceStats); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.PriceStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.StatsRequest) *apperrors.AppError); ok {
+		r1 = rf(ctx, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetWindowAnchors provides a mock function with given fields: ctx, symbols, starts
+func (_m *PriceRepositoryInterface) GetWindowAnchors(ctx context.Context, symbols []string, starts []time.Time) ([]domain.PerformanceAnchors, *apperrors.AppError) {
+	ret := _m.Called(ctx, symbols, starts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWindowAnchors")
+	}
+
+	var r0 []domain.PerformanceAnchors
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, []string, []time.Time) ([]domain.PerformanceAnchors, *apperrors.AppError)); ok {
+		return rf(ctx, symbols, starts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, []time.Time) []domain.PerformanceAnchors); ok {
+		r0 = rf(ctx, symbols, starts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PerformanceAnchors)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, []time.Time) *apperrors.AppError); ok {
+		r1 = rf(ctx, symbols, starts)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// InsertBatch provides a mock function with given fields: ctx, rows
+func (_m *PriceRepositoryInterface) InsertBatch(ctx context.Context, rows []domain.ImportRow) ([]bool, *apperrors.AppError) {
+	ret := _m.Called(ctx, rows)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertBatch")
+	}
+
+	var r0 []bool
+	var r1 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.ImportRow) ([]bool, *apperrors.AppError)); ok {
+		return rf(ctx, rows)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.ImportRow) []bool); ok {
+		r0 = rf(ctx, rows)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bool)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []domain.ImportRow) *apperrors.AppError); ok {
+		r1 = rf(ctx, rows)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*apperrors.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// StreamRange provides a mock function with given fields: ctx, query, fn
+func (_m *PriceRepositoryInterface) StreamRange(ctx context.Context, query domain.ExportQuery, fn func(domain.PriceSample) error) *apperrors.AppError {
+	ret := _m.Called(ctx, query, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamRange")
+	}
+
+	var r0 *apperrors.AppError
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ExportQuery, func(domain.PriceSample) error) *apperrors.AppError); ok {
+		r0 = rf(ctx, query, fn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apperrors.AppError)
+		}
+	}
+
+	return r0
+}
+
 // NewPriceRepositoryInterface creates a new instance of PriceRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPriceRepositoryInterface(t interface {
@@ -2,11 +2,17 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
@@ -14,14 +20,24 @@ import (
 type PriceRepositoryInterface interface {
 	Add(ctx context.Context, symbol string, price decimal.Decimal, timestamp time.Time) *apperrors.AppError
 	GetNearest(ctx context.Context, symbol string, timestamp time.Time) (decimal.Decimal, time.Time, *apperrors.AppError)
+	GetRange(ctx context.Context, query domain.PriceRangeQuery) ([]domain.PriceSample, *apperrors.AppError)
+	GetCandles(ctx context.Context, query domain.CandleQuery) ([]domain.Candle, *apperrors.AppError)
+	GetBucketEdges(ctx context.Context, query domain.BucketQuery) ([]domain.PriceBucket, *apperrors.AppError)
+	GetBracketing(ctx context.Context, symbol string, timestamp time.Time) (*domain.PriceSample, *domain.PriceSample, *apperrors.AppError)
+	GetLatest(ctx context.Context, symbols []string) ([]domain.LatestPrice, *apperrors.AppError)
+	GetNearestBatch(ctx context.Context, lookups []domain.PriceLookup) ([]domain.PriceLookupResult, *apperrors.AppError)
+	GetStats(ctx context.Context, req domain.StatsRequest) (domain.PriceStats, *apperrors.AppError)
+	GetWindowAnchors(ctx context.Context, symbols []string, starts []time.Time) ([]domain.PerformanceAnchors, *apperrors.AppError)
+	StreamRange(ctx context.Context, query domain.ExportQuery, fn func(domain.PriceSample) error) *apperrors.AppError
+	InsertBatch(ctx context.Context, rows []domain.ImportRow) ([]bool, *apperrors.AppError)
 }
 
 type priceRepo struct {
-	db     *sql.DB
+	db     DB
 	logger logger.Logger
 }
 
-func NewPriceRepository(db *sql.DB, logger logger.Logger) PriceRepositoryInterface {
+func NewPriceRepository(db DB, logger logger.Logger) PriceRepositoryInterface {
 	return &priceRepo{db: db, logger: logger}
 }
 
@@ -30,9 +46,9 @@ func (r *priceRepo) Add(ctx context.Context, symbol string, price decimal.Decima
 	l.Info("Adding price to DB")
 
 	var currencyID string
-	err := r.db.QueryRowContext(ctx, "SELECT id FROM tracked_currencies WHERE symbol = $1", symbol).Scan(&currencyID)
+	err := r.db.QueryRow(ctx, "SELECT id FROM tracked_currencies WHERE symbol = $1", symbol).Scan(&currencyID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			l.Warn("currency not found, skipping price add")
 			return nil
 		}
@@ -41,33 +57,62 @@ func (r *priceRepo) Add(ctx context.Context, symbol string, price decimal.Decima
 	}
 
 	query := `INSERT INTO price_history (currency_id, price, timestamp) VALUES ($1, $2, $3);`
-	_, err = r.db.ExecContext(ctx, query, currencyID, price, timestamp)
+	_, err = r.db.Exec(ctx, query, currencyID, price, timestamp)
 	if err != nil {
 		l.Error("DB error on price add", zap.Error(err))
+		if isDataError(err) {
+			return apperrors.New(http.StatusUnprocessableEntity, "price sample rejected by database", err)
+		}
 		return apperrors.NewInternalServerError("database error", err)
 	}
 
 	return nil
 }
 
+// isDataError сообщает, что база отвергла сами данные (классы SQLSTATE 22 - ошибка данных,
+// например переполнение NUMERIC, и 23 - нарушение ограничения): повтор не поможет.
+func isDataError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+}
+
+// GetNearest возвращает сэмпл, ближайший к timestamp. Вместо сортировки всей истории валюты
+// делаются два прохода по индексу (currency_id, timestamp) с LIMIT 1 - последний сэмпл не позже
+// и первый не раньше момента, - и берётся ближайший из них (при равенстве - более ранний).
+// Время запроса не зависит от размера истории.
 func (r *priceRepo) GetNearest(ctx context.Context, symbol string, timestamp time.Time) (decimal.Decimal, time.Time, *apperrors.AppError) {
 	l := r.logger.With(zap.String("symbol", symbol), zap.Time("timestamp", timestamp), zap.String("layer", "price_repo"))
 	l.Info("Getting nearest price from DB")
 
 	query := `
-		SELECT p.price, p.timestamp
-		FROM price_history p
-		JOIN tracked_currencies c ON p.currency_id = c.id
+		SELECT n.price, n.timestamp
+		FROM tracked_currencies c
+		CROSS JOIN LATERAL (
+			(SELECT price, timestamp
+			FROM price_history
+			WHERE currency_id = c.id AND timestamp <= $2
+			ORDER BY timestamp DESC
+			LIMIT 1)
+			UNION ALL
+			(SELECT price, timestamp
+			FROM price_history
+			WHERE currency_id = c.id AND timestamp >= $2
+			ORDER BY timestamp ASC
+			LIMIT 1)
+		) n
 		WHERE c.symbol = $1
-		ORDER BY abs(extract(epoch from p.timestamp) - extract(epoch from $2::timestamptz))
+		ORDER BY abs(extract(epoch FROM n.timestamp - $2::timestamptz)), n.timestamp
 		LIMIT 1;
 	`
 	var price decimal.Decimal
 	var foundTimestamp time.Time
 
-	err := r.db.QueryRowContext(ctx, query, symbol, timestamp).Scan(&price, &foundTimestamp)
+	err := r.db.QueryRow(ctx, query, symbol, timestamp).Scan(&price, &foundTimestamp)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			l.Warn("no price history found for symbol")
 			return decimal.Zero, time.Time{}, apperrors.NewNotFound("no price history found for this currency", err)
 		}
@@ -77,3 +122,663 @@ func (r *priceRepo) GetNearest(ctx context.Context, symbol string, timestamp tim
 
 	return price, foundTimestamp, nil
 }
+
+// GetRange возвращает до query.Limit записей истории (все при Limit <= 0), идя по индексу (currency_id, timestamp DESC, id DESC)
+// в нужном направлении. Пагинация по курсору - keyset по паре (timestamp, id), чтобы записи
+// с одинаковым timestamp не терялись на границе страниц.
+func (r *priceRepo) GetRange(ctx context.Context, query domain.PriceRangeQuery) ([]domain.PriceSample, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", query.Symbol), zap.String("layer", "price_repo"))
+	l.Info("Getting price range from DB")
+
+	currencyID, appErr := r.currencyID(ctx, l, query.Symbol)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	conditions := []string{"currency_id = $1"}
+	args := []any{currencyID}
+	addCondition := func(cond string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+	if !query.From.IsZero() {
+		addCondition("timestamp >= $%d", query.From)
+	}
+	if !query.To.IsZero() {
+		addCondition("timestamp <= $%d", query.To)
+	}
+	direction, op := "DESC", "<"
+	if query.Order == domain.SortAsc {
+		direction, op = "ASC", ">"
+	}
+	if !query.After.IsZero() {
+		args = append(args, query.After, query.AfterID)
+		conditions = append(conditions, fmt.Sprintf("(timestamp, id) %s ($%d, $%d)", op, len(args)-1, len(args)))
+	}
+	limit := ""
+	if query.Limit > 0 {
+		args = append(args, query.Limit)
+		limit = fmt.Sprintf("LIMIT $%d", len(args))
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT id, price, timestamp
+		FROM price_history
+		WHERE %s
+		ORDER BY timestamp %s, id %s
+		%s;
+	`, strings.Join(conditions, " AND "), direction, direction, limit)
+
+	rows, err := r.db.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		l.Error("DB error on get price range", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	var samples []domain.PriceSample
+	for rows.Next() {
+		sample := domain.PriceSample{Symbol: query.Symbol}
+		if err := rows.Scan(&sample.ID, &sample.Price, &sample.Timestamp); err != nil {
+			l.Error("DB error on scan price", zap.Error(err))
+			return nil, apperrors.NewInternalServerError("database error", err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate price range", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	return samples, nil
+}
+
+// GetCandles агрегирует историю в OHLC-свечи на стороне БД. Бакеты короче суток считаются через date_bin
+// по абсолютному времени от местной полуночи 2000-01-01 и не склеиваются при переводе часов; суточные -
+// по местному времени query.Location, поэтому дневные свечи начинаются в местную полночь.
+// Пустые бакеты не возвращаются.
+func (r *priceRepo) GetCandles(ctx context.Context, query domain.CandleQuery) ([]domain.Candle, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", query.Symbol), zap.Duration("interval", query.Interval), zap.String("layer", "price_repo"))
+	l.Info("Getting candles from DB")
+
+	currencyID, appErr := r.currencyID(ctx, l, query.Symbol)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	sqlQuery := `
+		SELECT CASE WHEN $2 < 86400
+				THEN date_bin($2 * interval '1 second', timestamp, TIMESTAMP '2000-01-01' AT TIME ZONE $3)
+				ELSE date_bin($2 * interval '1 second', timestamp AT TIME ZONE $3, TIMESTAMP '2000-01-01') AT TIME ZONE $3
+			END AS bucket,
+			(array_agg(price ORDER BY timestamp ASC))[1] AS open,
+			max(price) AS high,
+			min(price) AS low,
+			(array_agg(price ORDER BY timestamp DESC))[1] AS close,
+			count(*) AS samples
+		FROM price_history
+		WHERE currency_id = $1 AND timestamp >= $4 AND timestamp < $5
+		GROUP BY bucket
+		ORDER BY bucket;
+	`
+	rows, err := r.db.Query(ctx, sqlQuery, currencyID, query.Interval.Seconds(), query.Location.String(), query.From, query.To)
+	if err != nil {
+		l.Error("DB error on get candles", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	var candles []domain.Candle
+	for rows.Next() {
+		var c domain.Candle
+		if err := rows.Scan(&c.Start, &c.Open, &c.High, &c.Low, &c.Close, &c.Count); err != nil {
+			l.Error("DB error on scan candle", zap.Error(err))
+			return nil, apperrors.NewInternalServerError("database error", err)
+		}
+		candles = append(candles, c)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate candles", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	return candles, nil
+}
+
+// GetBucketEdges возвращает первый и последний сэмплы каждой непустой ячейки сетки query,
+// агрегируя на стороне БД, как GetCandles: объём ответа ограничен числом ячеек, а не сэмплов.
+// Ячейки закрыты справа, поэтому сэмпл ровно в точке сетки относится к ячейке, которая ею заканчивается.
+func (r *priceRepo) GetBucketEdges(ctx context.Context, query domain.BucketQuery) ([]domain.PriceBucket, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", query.Symbol), zap.Duration("step", query.Step), zap.String("layer", "price_repo"))
+	l.Info("Getting bucket edges from DB")
+
+	currencyID, appErr := r.currencyID(ctx, l, query.Symbol)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	sqlQuery := `
+		SELECT min(timestamp) AS first_ts,
+			(array_agg(price ORDER BY timestamp ASC))[1] AS first_price,
+			max(timestamp) AS last_ts,
+			(array_agg(price ORDER BY timestamp DESC))[1] AS last_price
+		FROM price_history
+		WHERE currency_id = $1 AND timestamp > $2 AND timestamp <= $3
+		GROUP BY ceil(extract(epoch FROM timestamp - $2::timestamptz) / $4::float8)
+		ORDER BY first_ts;
+	`
+	rows, err := r.db.Query(ctx, sqlQuery, currencyID, query.From, query.To, query.Step.Seconds())
+	if err != nil {
+		l.Error("DB error on get bucket edges", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	var buckets []domain.PriceBucket
+	for rows.Next() {
+		b := domain.PriceBucket{First: domain.PriceSample{Symbol: query.Symbol}, Last: domain.PriceSample{Symbol: query.Symbol}}
+		if err := rows.Scan(&b.First.Timestamp, &b.First.Price, &b.Last.Timestamp, &b.Last.Price); err != nil {
+			l.Error("DB error on scan bucket edges", zap.Error(err))
+			return nil, apperrors.NewInternalServerError("database error", err)
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate bucket edges", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	return buckets, nil
+}
+
+// GetBracketing возвращает последний сэмпл не позже timestamp и первый не раньше него.
+// Каждая часть - отдельный проход по индексу (currency_id, timestamp) с LIMIT 1.
+// Если сэмпл ровно в timestamp, он возвращается в обеих позициях; отсутствующая сторона - nil.
+func (r *priceRepo) GetBracketing(ctx context.Context, symbol string, timestamp time.Time) (*domain.PriceSample, *domain.PriceSample, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", symbol), zap.Time("timestamp", timestamp), zap.String("layer", "price_repo"))
+	l.Info("Getting bracketing prices from DB")
+
+	currencyID, appErr := r.currencyID(ctx, l, symbol)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	query := `
+		(SELECT price, timestamp, true AS is_before
+		FROM price_history
+		WHERE currency_id = $1 AND timestamp <= $2
+		ORDER BY timestamp DESC
+		LIMIT 1)
+		UNION ALL
+		(SELECT price, timestamp, false AS is_before
+		FROM price_history
+		WHERE currency_id = $1 AND timestamp >= $2
+		ORDER BY timestamp ASC
+		LIMIT 1);
+	`
+	rows, err := r.db.Query(ctx, query, currencyID, timestamp)
+	if err != nil {
+		l.Error("DB error on get bracketing prices", zap.Error(err))
+		return nil, nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	var before, after *domain.PriceSample
+	for rows.Next() {
+		sample := domain.PriceSample{Symbol: symbol}
+		var isBefore bool
+		if err := rows.Scan(&sample.Price, &sample.Timestamp, &isBefore); err != nil {
+			l.Error("DB error on scan bracketing price", zap.Error(err))
+			return nil, nil, apperrors.NewInternalServerError("database error", err)
+		}
+		if isBefore {
+			before = &sample
+		} else {
+			after = &sample
+		}
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate bracketing prices", zap.Error(err))
+		return nil, nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	return before, after, nil
+}
+
+// GetLatest одним запросом возвращает последний сэмпл и цену за 24 часа до него для всех
+// отслеживаемых валют (или только для symbols). Для каждой валюты это два LATERAL-прохода
+// по индексу с LIMIT 1, поэтому стоимость не зависит от длины истории. Сравнение отсчитывается
+// от самого сэмпла, а не от now(): у валюты, сбор которой остановился, изменение остаётся суточным.
+func (r *priceRepo) GetLatest(ctx context.Context, symbols []string) ([]domain.LatestPrice, *apperrors.AppError) {
+	l := r.logger.With(zap.Strings("symbols", symbols), zap.String("layer", "price_repo"))
+	l.Info("Getting latest prices from DB")
+
+	// Без списка символов архивные валюты не показываются; явно запрошенные отдаются и архивными.
+	filter := "WHERE c.archived_at IS NULL"
+	var args []any
+	if len(symbols) > 0 {
+		filter = "WHERE c.symbol = ANY($1)"
+		args = append(args, symbols)
+	}
+	query := fmt.Sprintf(`
+		SELECT c.symbol, latest.price, latest.timestamp, day_ago.price
+		FROM tracked_currencies c
+		JOIN LATERAL (
+			SELECT price, timestamp
+			FROM price_history
+			WHERE currency_id = c.id
+			ORDER BY timestamp DESC
+			LIMIT 1
+		) latest ON true
+		LEFT JOIN LATERAL (
+			SELECT price
+			FROM price_history
+			WHERE currency_id = c.id AND timestamp <= latest.timestamp - interval '24 hours'
+			ORDER BY timestamp DESC
+			LIMIT 1
+		) day_ago ON true
+		%s
+		ORDER BY c.symbol;
+	`, filter)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		l.Error("DB error on get latest prices", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	var prices []domain.LatestPrice
+	for rows.Next() {
+		var p domain.LatestPrice
+		if err := rows.Scan(&p.Symbol, &p.Price, &p.Timestamp, &p.Price24hAgo); err != nil {
+			l.Error("DB error on scan latest price", zap.Error(err))
+			return nil, apperrors.NewInternalServerError("database error", err)
+		}
+		prices = append(prices, p)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate latest prices", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	return prices, nil
+}
+
+// GetNearestBatch ищет ближайшие цены для всего пакета одним запросом. Для каждого элемента
+// выполняются два прохода по индексу (последний сэмпл не позже и первый не раньше момента),
+// из которых берётся ближайший. Результаты возвращаются в порядке lookups.
+func (r *priceRepo) GetNearestBatch(ctx context.Context, lookups []domain.PriceLookup) ([]domain.PriceLookupResult, *apperrors.AppError) {
+	l := r.logger.With(zap.Int("items", len(lookups)), zap.String("layer", "price_repo"))
+	l.Info("Getting nearest prices batch from DB")
+
+	symbols := make([]string, len(lookups))
+	timestamps := make([]time.Time, len(lookups))
+	for i, lookup := range lookups {
+		symbols[i] = lookup.Symbol
+		timestamps[i] = lookup.Timestamp
+	}
+
+	query := `
+		SELECT q.ord, c.id IS NOT NULL AS tracked, n.price, n.timestamp
+		FROM unnest($1::text[], $2::timestamptz[]) WITH ORDINALITY AS q(symbol, ts, ord)
+		LEFT JOIN tracked_currencies c ON c.symbol = q.symbol
+		LEFT JOIN LATERAL (
+			SELECT candidates.price, candidates.timestamp
+			FROM (
+				(SELECT price, timestamp
+				FROM price_history
+				WHERE currency_id = c.id AND timestamp <= q.ts
+				ORDER BY timestamp DESC
+				LIMIT 1)
+				UNION ALL
+				(SELECT price, timestamp
+				FROM price_history
+				WHERE currency_id = c.id AND timestamp >= q.ts
+				ORDER BY timestamp ASC
+				LIMIT 1)
+			) candidates
+			ORDER BY abs(extract(epoch FROM candidates.timestamp - q.ts)), candidates.timestamp
+			LIMIT 1
+		) n ON true
+		ORDER BY q.ord;
+	`
+	rows, err := r.db.Query(ctx, query, symbols, timestamps)
+	if err != nil {
+		l.Error("DB error on get nearest prices batch", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	results := make([]domain.PriceLookupResult, len(lookups))
+	for rows.Next() {
+		var (
+			ord       int64
+			tracked   bool
+			price     *decimal.Decimal
+			timestamp *time.Time
+		)
+		if err := rows.Scan(&ord, &tracked, &price, &timestamp); err != nil {
+			l.Error("DB error on scan nearest price", zap.Error(err))
+			return nil, apperrors.NewInternalServerError("database error", err)
+		}
+		if ord < 1 || int(ord) > len(lookups) {
+			continue
+		}
+		res := domain.PriceLookupResult{
+			Symbol:      lookups[ord-1].Symbol,
+			RequestedAt: lookups[ord-1].Timestamp,
+			Tracked:     tracked,
+		}
+		if price != nil && timestamp != nil {
+			res.Found, res.Price, res.Timestamp = true, *price, *timestamp
+		}
+		results[ord-1] = res
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate nearest prices batch", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	return results, nil
+}
+
+// GetStats считает описательную статистику окна одним запросом. Медиана - среднее нижнего и
+// верхнего среднего элемента (percentile_disc по возрастанию и убыванию), чтобы остаться в numeric.
+// Экстремумы при равных ценах берутся по самому раннему сэмплу.
+func (r *priceRepo) GetStats(ctx context.Context, req domain.StatsRequest) (domain.PriceStats, *apperrors.AppError) {
+	l := r.logger.With(zap.String("symbol", req.Symbol), zap.String("layer", "price_repo"))
+	l.Info("Getting price stats from DB")
+
+	currencyID, appErr := r.currencyID(ctx, l, req.Symbol)
+	if appErr != nil {
+		return domain.PriceStats{}, appErr
+	}
+
+	query := `
+		WITH w AS (
+			SELECT price, timestamp
+			FROM price_history
+			WHERE currency_id = $1 AND timestamp >= $2 AND timestamp <= $3
+		),
+		agg AS (
+			SELECT count(*) AS n,
+				min(timestamp) AS first_ts,
+				max(timestamp) AS last_ts,
+				round(avg(price), 8) AS mean,
+				(percentile_disc(0.5) WITHIN GROUP (ORDER BY price ASC)
+					+ percentile_disc(0.5) WITHIN GROUP (ORDER BY price DESC)) / 2 AS median,
+				round(stddev_samp(price), 8) AS stddev
+			FROM w
+		),
+		lo AS (SELECT price, timestamp FROM w ORDER BY price ASC, timestamp ASC LIMIT 1),
+		hi AS (SELECT price, timestamp FROM w ORDER BY price DESC, timestamp ASC LIMIT 1),
+		rets AS (
+			SELECT ln(price / lag(price) OVER (ORDER BY timestamp)) AS lr
+			FROM w
+			WHERE price > 0
+		)
+		SELECT agg.n, agg.first_ts, agg.last_ts, lo.price, lo.timestamp, hi.price, hi.timestamp,
+			agg.mean, agg.median, agg.stddev,
+			(SELECT sum(lr * lr) FROM rets)
+		FROM agg
+		LEFT JOIN lo ON true
+		LEFT JOIN hi ON true;
+	`
+	var (
+		stats        = domain.PriceStats{Symbol: req.Symbol, From: req.From, To: req.To}
+		minPrice     *decimal.Decimal
+		maxPrice     *decimal.Decimal
+		minTs, maxTs *time.Time
+	)
+	err := r.db.QueryRow(ctx, query, currencyID, req.From, req.To).Scan(
+		&stats.Count, &stats.FirstSample, &stats.LastSample,
+		&minPrice, &minTs, &maxPrice, &maxTs,
+		&stats.Mean, &stats.Median, &stats.StdDev, &stats.LogReturnsSumSq,
+	)
+	if err != nil {
+		l.Error("DB error on get price stats", zap.Error(err))
+		return domain.PriceStats{}, apperrors.NewInternalServerError("database error", err)
+	}
+	if minPrice != nil && minTs != nil {
+		stats.Min = &domain.PriceSample{Symbol: req.Symbol, Price: *minPrice, Timestamp: *minTs}
+	}
+	if maxPrice != nil && maxTs != nil {
+		stats.Max = &domain.PriceSample{Symbol: req.Symbol, Price: *maxPrice, Timestamp: *maxTs}
+	}
+
+	return stats, nil
+}
+
+// GetWindowAnchors для каждой валюты с историей (всех или из symbols) находит последний и самый
+// ранний сэмпл, а для каждого момента из starts - последний сэмпл не позже него. Все проходы -
+// LIMIT 1 по индексу (currency_id, timestamp). Bases идут в порядке starts.
+func (r *priceRepo) GetWindowAnchors(ctx context.Context, symbols []string, starts []time.Time) ([]domain.PerformanceAnchors, *apperrors.AppError) {
+	l := r.logger.With(zap.Strings("symbols", symbols), zap.Int("windows", len(starts)), zap.String("layer", "price_repo"))
+	l.Info("Getting window anchors from DB")
+
+	// Без списка символов архивные валюты не показываются; явно запрошенные отдаются и архивными.
+	filter := "WHERE c.archived_at IS NULL"
+	args := []any{starts}
+	if len(symbols) > 0 {
+		filter = "WHERE c.symbol = ANY($2)"
+		args = append(args, symbols)
+	}
+	query := fmt.Sprintf(`
+		SELECT c.symbol, latest.price, latest.timestamp, earliest.price, earliest.timestamp,
+			w.ord, base.price, base.timestamp
+		FROM tracked_currencies c
+		JOIN LATERAL (
+			SELECT price, timestamp
+			FROM price_history
+			WHERE currency_id = c.id
+			ORDER BY timestamp DESC
+			LIMIT 1
+		) latest ON true
+		JOIN LATERAL (
+			SELECT price, timestamp
+			FROM price_history
+			WHERE currency_id = c.id
+			ORDER BY timestamp ASC
+			LIMIT 1
+		) earliest ON true
+		CROSS JOIN unnest($1::timestamptz[]) WITH ORDINALITY AS w(start, ord)
+		LEFT JOIN LATERAL (
+			SELECT price, timestamp
+			FROM price_history
+			WHERE currency_id = c.id AND timestamp <= w.start
+			ORDER BY timestamp DESC
+			LIMIT 1
+		) base ON true
+		%s
+		ORDER BY c.symbol, w.ord;
+	`, filter)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		l.Error("DB error on get window anchors", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	var result []domain.PerformanceAnchors
+	for rows.Next() {
+		var (
+			latest, earliest domain.PriceSample
+			symbol           string
+			ord              int64
+			basePrice        *decimal.Decimal
+			baseTs           *time.Time
+		)
+		if err := rows.Scan(&symbol, &latest.Price, &latest.Timestamp, &earliest.Price, &earliest.Timestamp, &ord, &basePrice, &baseTs); err != nil {
+			l.Error("DB error on scan window anchor", zap.Error(err))
+			return nil, apperrors.NewInternalServerError("database error", err)
+		}
+		if len(result) == 0 || result[len(result)-1].Symbol != symbol {
+			latest.Symbol, earliest.Symbol = symbol, symbol
+			result = append(result, domain.PerformanceAnchors{
+				Symbol:   symbol,
+				Latest:   latest,
+				Earliest: earliest,
+				Bases:    make([]*domain.PriceSample, len(starts)),
+			})
+		}
+		if ord < 1 || int(ord) > len(starts) || basePrice == nil || baseTs == nil {
+			continue
+		}
+		result[len(result)-1].Bases[ord-1] = &domain.PriceSample{Symbol: symbol, Price: *basePrice, Timestamp: *baseTs}
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate window anchors", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	return result, nil
+}
+
+// StreamRange передаёт сэмплы выборки в fn по одному, по возрастанию времени (при равенстве - по символу).
+// Строки читаются из соединения по мере обхода курсора, и результат целиком в памяти не держится.
+// Ошибка fn или отмена ctx прерывает запрос.
+func (r *priceRepo) StreamRange(ctx context.Context, query domain.ExportQuery, fn func(domain.PriceSample) error) *apperrors.AppError {
+	l := r.logger.With(zap.Strings("symbols", query.Symbols), zap.String("layer", "price_repo"))
+	l.Info("Streaming price range from DB")
+
+	conditions := []string{"c.symbol = ANY($1)"}
+	args := []any{query.Symbols}
+	if !query.From.IsZero() {
+		args = append(args, query.From)
+		conditions = append(conditions, fmt.Sprintf("p.timestamp >= $%d", len(args)))
+	}
+	if !query.To.IsZero() {
+		args = append(args, query.To)
+		conditions = append(conditions, fmt.Sprintf("p.timestamp <= $%d", len(args)))
+	}
+	sqlQuery := fmt.Sprintf(`
+		SELECT c.symbol, p.price, p.timestamp
+		FROM price_history p
+		JOIN tracked_currencies c ON c.id = p.currency_id
+		WHERE %s
+		ORDER BY p.timestamp, c.symbol;
+	`, strings.Join(conditions, " AND "))
+
+	rows, err := r.db.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		l.Error("DB error on stream price range", zap.Error(err))
+		return apperrors.NewInternalServerError("database error", err)
+	}
+	defer rows.Close()
+
+	var sample domain.PriceSample
+	for rows.Next() {
+		if err := rows.Scan(&sample.Symbol, &sample.Price, &sample.Timestamp); err != nil {
+			l.Error("DB error on scan price", zap.Error(err))
+			return apperrors.NewInternalServerError("database error", err)
+		}
+		if err := fn(sample); err != nil {
+			l.Warn("price stream aborted", zap.Error(err))
+			return apperrors.NewInternalServerError("failed to write export", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("DB error on iterate price stream", zap.Error(err))
+		return apperrors.NewInternalServerError("database error", err)
+	}
+
+	return nil
+}
+
+// importLockKey - ключ advisory-блокировки, под которой пакеты импорта проверяют дубликаты,
+// чтобы параллельные импорты не записали один и тот же сэмпл дважды.
+const importLockKey = 0x1397_0a7e
+
+// InsertBatch записывает пакет строк импорта одной транзакцией. Строка пропускается как дубликат,
+// если сэмпл валюты с тем же временем уже есть в истории или раньше в этом же пакете.
+// Возвращает для каждой строки rows, была ли она записана.
+func (r *priceRepo) InsertBatch(ctx context.Context, rows []domain.ImportRow) ([]bool, *apperrors.AppError) {
+	l := r.logger.With(zap.Int("rows", len(rows)), zap.String("layer", "price_repo"))
+	l.Info("Inserting import batch into DB")
+
+	symbols := make([]string, len(rows))
+	prices := make([]decimal.Decimal, len(rows))
+	timestamps := make([]time.Time, len(rows))
+	for i, row := range rows {
+		symbols[i], prices[i], timestamps[i] = row.Symbol, row.Price, row.Timestamp
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		l.Error("DB error on begin import batch", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1);", importLockKey); err != nil {
+		l.Error("DB error on import lock", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	query := `
+		WITH input AS (
+			SELECT *
+			FROM unnest($1::text[], $2::numeric[], $3::timestamptz[]) WITH ORDINALITY AS t(symbol, price, ts, ord)
+		), firsts AS (
+			SELECT DISTINCT ON (symbol, ts) symbol, price, ts, ord
+			FROM input
+			ORDER BY symbol, ts, ord
+		), inserted AS (
+			INSERT INTO price_history (currency_id, price, timestamp)
+			SELECT c.id, f.price, f.ts
+			FROM firsts f
+			JOIN tracked_currencies c ON c.symbol = f.symbol
+			WHERE NOT EXISTS (
+				SELECT 1 FROM price_history p WHERE p.currency_id = c.id AND p.timestamp = f.ts
+			)
+			RETURNING currency_id, timestamp
+		)
+		SELECT f.ord
+		FROM firsts f
+		JOIN tracked_currencies c ON c.symbol = f.symbol
+		JOIN inserted i ON i.currency_id = c.id AND i.timestamp = f.ts;
+	`
+	result, err := tx.Query(ctx, query, symbols, prices, timestamps)
+	if err != nil {
+		l.Error("DB error on insert import batch", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	inserted := make([]bool, len(rows))
+	for result.Next() {
+		var ord int64
+		if err := result.Scan(&ord); err != nil {
+			result.Close()
+			l.Error("DB error on scan inserted row", zap.Error(err))
+			return nil, apperrors.NewInternalServerError("database error", err)
+		}
+		inserted[ord-1] = true
+	}
+	result.Close()
+	if err := result.Err(); err != nil {
+		l.Error("DB error on iterate inserted rows", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		l.Error("DB error on commit import batch", zap.Error(err))
+		return nil, apperrors.NewInternalServerError("database error", err)
+	}
+	return inserted, nil
+}
+
+// currencyID находит id отслеживаемой валюты; 404, если символ не отслеживается.
+func (r *priceRepo) currencyID(ctx context.Context, l logger.Logger, symbol string) (string, *apperrors.AppError) {
+	var currencyID string
+	err := r.db.QueryRow(ctx, "SELECT id FROM tracked_currencies WHERE symbol = $1", symbol).Scan(&currencyID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			l.Warn("currency not found")
+			return "", apperrors.NewNotFound("currency is not tracked", err)
+		}
+		l.Error("failed to get currency id", zap.Error(err))
+		return "", apperrors.NewInternalServerError("database error", err)
+	}
+	return currencyID, nil
+}
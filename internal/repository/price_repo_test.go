@@ -2,14 +2,18 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"errors"
+	"net/http"
 	"regexp"
 	"testing"
 	"time"
 
-	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/pkg/logger"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v3"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,11 +24,11 @@ func TestPriceRepository_Add(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
-		defer db.Close()
+		defer mock.Close()
 
-		repo := NewPriceRepository(db, nopLogger)
+		repo := NewPriceRepository(mock, nopLogger)
 
 		symbol := "BTC"
 		currencyID := uuid.New()
@@ -32,11 +36,11 @@ func TestPriceRepository_Add(t *testing.T) {
 		timestamp := time.Now()
 
 		selectQuery := regexp.QuoteMeta(`SELECT id FROM tracked_currencies WHERE symbol = $1`)
-		rows := sqlmock.NewRows([]string{"id"}).AddRow(currencyID.String())
+		rows := pgxmock.NewRows([]string{"id"}).AddRow(currencyID.String())
 		mock.ExpectQuery(selectQuery).WithArgs(symbol).WillReturnRows(rows)
 
 		insertQuery := regexp.QuoteMeta(`INSERT INTO price_history (currency_id, price, timestamp) VALUES ($1, $2, $3);`)
-		mock.ExpectExec(insertQuery).WithArgs(currencyID.String(), price, timestamp).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(insertQuery).WithArgs(currencyID.String(), price, timestamp).WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		appErr := repo.Add(ctx, symbol, price, timestamp)
 
@@ -44,16 +48,36 @@ func TestPriceRepository_Add(t *testing.T) {
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("numeric_overflow_is_unprocessable", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		currencyID := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM tracked_currencies WHERE symbol = $1`)).WithArgs("BTC").
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(currencyID.String()))
+		price, ts := decimal.RequireFromString("1e15"), time.Now()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO price_history`)).WithArgs(currencyID.String(), price, ts).
+			WillReturnError(&pgconn.PgError{Code: "22003", Message: "numeric field overflow"})
+
+		appErr := repo.Add(ctx, "BTC", price, ts)
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusUnprocessableEntity, appErr.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("success_currency_not_found", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
-		defer db.Close()
+		defer mock.Close()
 
-		repo := NewPriceRepository(db, nopLogger)
+		repo := NewPriceRepository(mock, nopLogger)
 		symbol := "UNKNOWN"
 		selectQuery := regexp.QuoteMeta(`SELECT id FROM tracked_currencies WHERE symbol = $1`)
 
-		mock.ExpectQuery(selectQuery).WithArgs(symbol).WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(selectQuery).WithArgs(symbol).WillReturnError(pgx.ErrNoRows)
 
 		appErr := repo.Add(ctx, symbol, decimal.Zero, time.Now())
 
@@ -67,20 +91,20 @@ func TestPriceRepository_GetNearest(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
-		defer db.Close()
+		defer mock.Close()
 
-		repo := NewPriceRepository(db, nopLogger)
+		repo := NewPriceRepository(mock, nopLogger)
 
 		symbol := "BTC"
 		timestamp := time.Now()
 		expectedPrice := decimal.NewFromFloat(65123.45)
 		expectedTimestamp := timestamp.Add(-5 * time.Second)
 
-		query := `^SELECT p.price, p.timestamp FROM price_history p JOIN tracked_currencies c ON p.currency_id = c.id WHERE c.symbol = \$1`
+		query := `^SELECT n.price, n.timestamp FROM tracked_currencies c CROSS JOIN LATERAL .* WHERE c.symbol = \$1`
 
-		rows := sqlmock.NewRows([]string{"price", "timestamp"}).AddRow(expectedPrice, expectedTimestamp)
+		rows := pgxmock.NewRows([]string{"price", "timestamp"}).AddRow(expectedPrice, expectedTimestamp)
 		mock.ExpectQuery(query).WithArgs(symbol, timestamp).WillReturnRows(rows)
 
 		price, foundTime, appErr := repo.GetNearest(ctx, symbol, timestamp)
@@ -92,17 +116,17 @@ func TestPriceRepository_GetNearest(t *testing.T) {
 	})
 
 	t.Run("failure_not_found", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
-		defer db.Close()
+		defer mock.Close()
 
-		repo := NewPriceRepository(db, nopLogger)
+		repo := NewPriceRepository(mock, nopLogger)
 
 		symbol := "NONEXISTENT"
 		timestamp := time.Now()
-		query := `^SELECT p.price, p.timestamp FROM price_history p JOIN tracked_currencies c ON p.currency_id = c.id WHERE c.symbol = \$1`
+		query := `^SELECT n.price, n.timestamp FROM tracked_currencies c CROSS JOIN LATERAL .* WHERE c.symbol = \$1`
 
-		mock.ExpectQuery(query).WithArgs(symbol, timestamp).WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(query).WithArgs(symbol, timestamp).WillReturnError(pgx.ErrNoRows)
 
 		_, _, appErr := repo.GetNearest(ctx, symbol, timestamp)
 
@@ -111,3 +135,514 @@ func TestPriceRepository_GetNearest(t *testing.T) {
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestPriceRepository_GetRange(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	currencyID := uuid.New().String()
+	selectID := regexp.QuoteMeta(`SELECT id FROM tracked_currencies WHERE symbol = $1`)
+
+	t.Run("success_desc_with_cursor", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		from := time.Unix(1700000000, 0)
+		after := time.Unix(1700000600, 0)
+
+		mock.ExpectQuery(selectID).WithArgs("BTC").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(currencyID))
+		rangeQuery := `SELECT id, price, timestamp\s+FROM price_history\s+WHERE currency_id = \$1 AND timestamp >= \$2 AND \(timestamp, id\) < \(\$3, \$4\)\s+ORDER BY timestamp DESC, id DESC\s+LIMIT \$5`
+		rows := pgxmock.NewRows([]string{"id", "price", "timestamp"}).
+			AddRow(int64(41), decimal.NewFromInt(2), after).
+			AddRow(int64(40), decimal.NewFromInt(1), time.Unix(1700000480, 0))
+		mock.ExpectQuery(rangeQuery).WithArgs(currencyID, from, after, int64(42), 3).WillReturnRows(rows)
+
+		samples, appErr := repo.GetRange(ctx, domain.PriceRangeQuery{
+			Symbol: "BTC", From: from, Order: domain.SortDesc, Limit: 3, After: after, AfterID: 42,
+		})
+
+		assert.Nil(t, appErr)
+		require.Len(t, samples, 2)
+		assert.Equal(t, "BTC", samples[0].Symbol)
+		assert.Equal(t, int64(41), samples[0].ID)
+		assert.Equal(t, decimal.NewFromInt(2), samples[0].Price)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success_asc", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		to := time.Unix(1700000000, 0)
+
+		mock.ExpectQuery(selectID).WithArgs("BTC").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(currencyID))
+		rangeQuery := `WHERE currency_id = \$1 AND timestamp <= \$2\s+ORDER BY timestamp ASC, id ASC\s+LIMIT \$3`
+		mock.ExpectQuery(rangeQuery).WithArgs(currencyID, to, 10).WillReturnRows(pgxmock.NewRows([]string{"id", "price", "timestamp"}))
+
+		samples, appErr := repo.GetRange(ctx, domain.PriceRangeQuery{Symbol: "BTC", To: to, Order: domain.SortAsc, Limit: 10})
+
+		assert.Nil(t, appErr)
+		assert.Empty(t, samples)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failure_currency_not_tracked", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		mock.ExpectQuery(selectID).WithArgs("NOPE").WillReturnError(pgx.ErrNoRows)
+
+		_, appErr := repo.GetRange(ctx, domain.PriceRangeQuery{Symbol: "NOPE", Limit: 10})
+
+		require.Error(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPriceRepository_GetCandles(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPriceRepository(mock, nopLogger)
+	currencyID := uuid.New().String()
+	from := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	to := from.Add(2 * time.Hour)
+	bucket := from.Add(time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM tracked_currencies WHERE symbol = $1`)).
+		WithArgs("BTC").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(currencyID))
+	rows := pgxmock.NewRows([]string{"bucket", "open", "high", "low", "close", "samples"}).
+		AddRow(bucket, decimal.NewFromInt(10), decimal.NewFromInt(12), decimal.NewFromInt(9), decimal.NewFromInt(11), 60)
+	mock.ExpectQuery(`SELECT CASE WHEN \$2 < 86400\s+THEN date_bin\(\$2 \* interval '1 second', timestamp, TIMESTAMP '2000-01-01' AT TIME ZONE \$3\)\s+`+
+		`ELSE date_bin\(\$2 \* interval '1 second', timestamp AT TIME ZONE \$3, TIMESTAMP '2000-01-01'\) AT TIME ZONE \$3\s+END AS bucket`).
+		WithArgs(currencyID, float64(3600), "UTC", from, to).WillReturnRows(rows)
+
+	candles, appErr := repo.GetCandles(ctx, domain.CandleQuery{Symbol: "BTC", Interval: time.Hour, From: from, To: to, Location: time.UTC})
+
+	assert.Nil(t, appErr)
+	require.Len(t, candles, 1)
+	assert.Equal(t, bucket, candles[0].Start)
+	assert.Equal(t, decimal.NewFromInt(12), candles[0].High)
+	assert.Equal(t, 60, candles[0].Count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPriceRepository_GetBucketEdges(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPriceRepository(mock, nopLogger)
+	currencyID := uuid.New().String()
+	from := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	to := from.Add(2 * time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM tracked_currencies WHERE symbol = $1`)).
+		WithArgs("BTC").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(currencyID))
+	rows := pgxmock.NewRows([]string{"first_ts", "first_price", "last_ts", "last_price"}).
+		AddRow(from.Add(time.Minute), decimal.NewFromInt(10), from.Add(59*time.Minute), decimal.NewFromInt(11))
+	mock.ExpectQuery(`WHERE currency_id = \$1 AND timestamp > \$2 AND timestamp <= \$3\s+GROUP BY ceil\(extract\(epoch FROM timestamp - \$2::timestamptz\) / \$4::float8\)`).
+		WithArgs(currencyID, from, to, float64(3600)).WillReturnRows(rows)
+
+	buckets, appErr := repo.GetBucketEdges(ctx, domain.BucketQuery{Symbol: "BTC", From: from, To: to, Step: time.Hour})
+
+	assert.Nil(t, appErr)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, "BTC", buckets[0].First.Symbol)
+	assert.Equal(t, decimal.NewFromInt(10), buckets[0].First.Price)
+	assert.Equal(t, from.Add(59*time.Minute), buckets[0].Last.Timestamp)
+	assert.Equal(t, decimal.NewFromInt(11), buckets[0].Last.Price)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPriceRepository_GetBracketing(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPriceRepository(mock, nopLogger)
+	currencyID := uuid.New().String()
+	ts := time.Unix(1700000000, 0)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM tracked_currencies WHERE symbol = $1`)).
+		WithArgs("BTC").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(currencyID))
+	rows := pgxmock.NewRows([]string{"price", "timestamp", "is_before"}).
+		AddRow(decimal.NewFromInt(100), ts.Add(-30*time.Second), true)
+	mock.ExpectQuery(`timestamp <= \$2\s+ORDER BY timestamp DESC\s+LIMIT 1\)\s+UNION ALL`).
+		WithArgs(currencyID, ts).WillReturnRows(rows)
+
+	before, after, appErr := repo.GetBracketing(ctx, "BTC", ts)
+
+	assert.Nil(t, appErr)
+	require.NotNil(t, before)
+	assert.Equal(t, decimal.NewFromInt(100), before.Price)
+	assert.Equal(t, ts.Add(-30*time.Second), before.Timestamp)
+	assert.Nil(t, after)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPriceRepository_GetLatest(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	t.Run("filtered_by_symbols", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		dayAgo := decimal.NewFromInt(50000)
+		rows := pgxmock.NewRows([]string{"symbol", "price", "timestamp", "price"}).
+			AddRow("BTC", decimal.NewFromInt(55000), time.Unix(1700000000, 0), &dayAgo)
+		mock.ExpectQuery(`JOIN LATERAL .* WHERE c.symbol = ANY\(\$1\)\s+ORDER BY c.symbol`).
+			WithArgs([]string{"BTC"}).WillReturnRows(rows)
+
+		prices, appErr := repo.GetLatest(ctx, []string{"BTC"})
+
+		assert.Nil(t, appErr)
+		require.Len(t, prices, 1)
+		assert.Equal(t, "BTC", prices[0].Symbol)
+		require.NotNil(t, prices[0].Price24hAgo)
+		assert.Equal(t, dayAgo, *prices[0].Price24hAgo)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("day_ago_anchored_at_stale_latest_sample", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		// Сбор остановился три дня назад: база сравнивает последний сэмпл с ценой за сутки до него,
+		// а не с ценой на now()-24h, которая совпала бы с самим сэмплом.
+		stale := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+		dayBefore := decimal.NewFromInt(60000)
+		rows := pgxmock.NewRows([]string{"symbol", "price", "timestamp", "price"}).
+			AddRow("BTC", decimal.NewFromInt(55000), stale, &dayBefore)
+		mock.ExpectQuery(`WHERE currency_id = c.id AND timestamp <= latest.timestamp - interval '24 hours'`).
+			WithArgs([]string{"BTC"}).WillReturnRows(rows)
+
+		prices, appErr := repo.GetLatest(ctx, []string{"BTC"})
+
+		assert.Nil(t, appErr)
+		require.Len(t, prices, 1)
+		assert.Equal(t, stale, prices[0].Timestamp)
+		require.NotNil(t, prices[0].Price24hAgo)
+		assert.Equal(t, dayBefore, *prices[0].Price24hAgo)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("all_tracked_skips_archived", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		mock.ExpectQuery(`LEFT JOIN LATERAL .* day_ago ON true\s+WHERE c.archived_at IS NULL\s+ORDER BY c.symbol`).
+			WithArgs().WillReturnRows(pgxmock.NewRows([]string{"symbol", "price", "timestamp", "price"}))
+
+		prices, appErr := repo.GetLatest(ctx, nil)
+
+		assert.Nil(t, appErr)
+		assert.Empty(t, prices)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPriceRepository_GetNearestBatch(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		ts := time.Unix(1700000000, 0)
+		lookups := []domain.PriceLookup{
+			{Symbol: "BTC", Timestamp: ts},
+			{Symbol: "DOGE", Timestamp: ts},
+			{Symbol: "ETH", Timestamp: ts},
+		}
+		price := decimal.NewFromInt(55000)
+		found := ts.Add(-5 * time.Second)
+		rows := pgxmock.NewRows([]string{"ord", "tracked", "price", "timestamp"}).
+			AddRow(int64(1), true, &price, &found).
+			AddRow(int64(2), false, nil, nil).
+			AddRow(int64(3), true, nil, nil)
+		mock.ExpectQuery(`unnest\(\$1::text\[\], \$2::timestamptz\[\]\) WITH ORDINALITY`).
+			WithArgs([]string{"BTC", "DOGE", "ETH"}, []time.Time{ts, ts, ts}).
+			WillReturnRows(rows)
+
+		results, appErr := repo.GetNearestBatch(ctx, lookups)
+
+		assert.Nil(t, appErr)
+		require.Len(t, results, 3)
+		assert.True(t, results[0].Found)
+		assert.Equal(t, price, results[0].Price)
+		assert.Equal(t, found, results[0].Timestamp)
+		assert.False(t, results[1].Tracked)
+		assert.True(t, results[2].Tracked)
+		assert.False(t, results[2].Found)
+		assert.Equal(t, "ETH", results[2].Symbol)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("equidistant_prefers_earlier", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		// Сэмплы за 5 секунд до и после запроса равноудалены: как и GetNearest, берётся более ранний.
+		repo := NewPriceRepository(mock, nopLogger)
+		ts := time.Unix(1700000000, 0)
+		price := decimal.NewFromInt(55000)
+		earlier := ts.Add(-5 * time.Second)
+		mock.ExpectQuery(`ORDER BY abs\(extract\(epoch FROM candidates.timestamp - q.ts\)\), candidates.timestamp\s+LIMIT 1`).
+			WithArgs([]string{"BTC"}, []time.Time{ts}).
+			WillReturnRows(pgxmock.NewRows([]string{"ord", "tracked", "price", "timestamp"}).AddRow(int64(1), true, &price, &earlier))
+
+		results, appErr := repo.GetNearestBatch(ctx, []domain.PriceLookup{{Symbol: "BTC", Timestamp: ts}})
+
+		assert.Nil(t, appErr)
+		require.Len(t, results, 1)
+		assert.Equal(t, earlier, results[0].Timestamp)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPriceRepository_GetStats(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	from := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		currencyID := uuid.New().String()
+		first, last := from.Add(time.Minute), to.Add(-time.Minute)
+		minPrice, maxPrice := decimal.NewFromInt(90), decimal.NewFromInt(110)
+		minTs, maxTs := from.Add(time.Hour), from.Add(2*time.Hour)
+		mean, median, stddev, sumSq := decimal.NewFromInt(100), decimal.NewFromInt(101), decimal.NewFromInt(5), decimal.RequireFromString("0.0004")
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM tracked_currencies WHERE symbol = $1`)).
+			WithArgs("BTC").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(currencyID))
+		rows := pgxmock.NewRows([]string{"n", "first_ts", "last_ts", "price", "timestamp", "price", "timestamp", "mean", "median", "stddev", "sum"}).
+			AddRow(int64(1440), &first, &last, &minPrice, &minTs, &maxPrice, &maxTs, &mean, &median, &stddev, &sumSq)
+		mock.ExpectQuery(`WITH w AS .* percentile_disc\(0.5\)`).
+			WithArgs(currencyID, from, to).WillReturnRows(rows)
+
+		stats, appErr := repo.GetStats(ctx, domain.StatsRequest{Symbol: "BTC", From: from, To: to})
+
+		assert.Nil(t, appErr)
+		assert.Equal(t, int64(1440), stats.Count)
+		require.NotNil(t, stats.Min)
+		assert.Equal(t, minTs, stats.Min.Timestamp)
+		require.NotNil(t, stats.Max)
+		assert.Equal(t, maxPrice, stats.Max.Price)
+		assert.Equal(t, &median, stats.Median)
+		assert.Equal(t, &sumSq, stats.LogReturnsSumSq)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty_window", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		currencyID := uuid.New().String()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM tracked_currencies WHERE symbol = $1`)).
+			WithArgs("BTC").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(currencyID))
+		rows := pgxmock.NewRows([]string{"n", "first_ts", "last_ts", "price", "timestamp", "price", "timestamp", "mean", "median", "stddev", "sum"}).
+			AddRow(int64(0), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		mock.ExpectQuery(`WITH w AS`).WithArgs(currencyID, from, to).WillReturnRows(rows)
+
+		stats, appErr := repo.GetStats(ctx, domain.StatsRequest{Symbol: "BTC", From: from, To: to})
+
+		assert.Nil(t, appErr)
+		assert.Zero(t, stats.Count)
+		assert.Nil(t, stats.Min)
+		assert.Nil(t, stats.Mean)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPriceRepository_GetWindowAnchors(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	starts := []time.Time{now.Add(-time.Hour), now.Add(-30 * 24 * time.Hour)}
+	columns := []string{"symbol", "price", "timestamp", "price", "timestamp", "ord", "price", "timestamp"}
+
+	t.Run("filtered_by_symbols", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		latestTs, earliestTs, baseTs := now.Add(-time.Minute), now.Add(-7*24*time.Hour), now.Add(-61*time.Minute)
+		basePrice := decimal.NewFromInt(100)
+
+		rows := pgxmock.NewRows(columns).
+			AddRow("BTC", decimal.NewFromInt(110), latestTs, decimal.NewFromInt(80), earliestTs, int64(1), &basePrice, &baseTs).
+			AddRow("BTC", decimal.NewFromInt(110), latestTs, decimal.NewFromInt(80), earliestTs, int64(2), nil, nil)
+		mock.ExpectQuery(`unnest\(\$1::timestamptz\[\]\) WITH ORDINALITY .* WHERE c.symbol = ANY\(\$2\)\s+ORDER BY c.symbol, w.ord`).
+			WithArgs(starts, []string{"BTC"}).WillReturnRows(rows)
+
+		anchors, appErr := repo.GetWindowAnchors(ctx, []string{"BTC"}, starts)
+
+		assert.Nil(t, appErr)
+		require.Len(t, anchors, 1)
+		assert.Equal(t, "BTC", anchors[0].Latest.Symbol)
+		assert.Equal(t, earliestTs, anchors[0].Earliest.Timestamp)
+		require.Len(t, anchors[0].Bases, 2)
+		require.NotNil(t, anchors[0].Bases[0])
+		assert.Equal(t, basePrice, anchors[0].Bases[0].Price)
+		assert.Nil(t, anchors[0].Bases[1])
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("all_tracked_skips_archived", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		mock.ExpectQuery(`\) base ON true\s+WHERE c.archived_at IS NULL\s+ORDER BY c.symbol, w.ord`).
+			WithArgs(starts).WillReturnRows(pgxmock.NewRows(columns))
+
+		anchors, appErr := repo.GetWindowAnchors(ctx, nil, starts)
+
+		assert.Nil(t, appErr)
+		assert.Empty(t, anchors)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPriceRepository_StreamRange(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	from := time.Unix(1700000000, 0)
+	query := domain.ExportQuery{Symbols: []string{"BTC", "ETH"}, From: from}
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		rows := pgxmock.NewRows([]string{"symbol", "price", "timestamp"}).
+			AddRow("BTC", decimal.NewFromInt(42000), from).
+			AddRow("ETH", decimal.NewFromInt(2200), from)
+		mock.ExpectQuery(`WHERE c.symbol = ANY\(\$1\) AND p.timestamp >= \$2\s+ORDER BY p.timestamp, c.symbol`).
+			WithArgs([]string{"BTC", "ETH"}, from).WillReturnRows(rows)
+
+		var got []domain.PriceSample
+		appErr := repo.StreamRange(ctx, query, func(s domain.PriceSample) error {
+			got = append(got, s)
+			return nil
+		})
+
+		assert.Nil(t, appErr)
+		require.Len(t, got, 2)
+		assert.Equal(t, "ETH", got[1].Symbol)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("callback_error_stops", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		rows := pgxmock.NewRows([]string{"symbol", "price", "timestamp"}).
+			AddRow("BTC", decimal.NewFromInt(42000), from).
+			AddRow("ETH", decimal.NewFromInt(2200), from)
+		mock.ExpectQuery(`ORDER BY p.timestamp, c.symbol`).WithArgs([]string{"BTC", "ETH"}, from).WillReturnRows(rows)
+
+		calls := 0
+		appErr := repo.StreamRange(ctx, query, func(domain.PriceSample) error {
+			calls++
+			return errors.New("broken pipe")
+		})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusInternalServerError, appErr.Code)
+	})
+}
+
+func TestPriceRepository_InsertBatch(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	ts := time.Unix(1700000000, 0)
+	rows := []domain.ImportRow{
+		{Line: 2, Symbol: "BTC", Price: decimal.NewFromInt(42000), Timestamp: ts},
+		{Line: 3, Symbol: "BTC", Price: decimal.NewFromInt(42000), Timestamp: ts},
+		{Line: 4, Symbol: "ETH", Price: decimal.NewFromInt(2200), Timestamp: ts},
+	}
+	args := []any{
+		[]string{"BTC", "BTC", "ETH"},
+		[]decimal.Decimal{rows[0].Price, rows[1].Price, rows[2].Price},
+		[]time.Time{ts, ts, ts},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(importLockKey).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery(`DISTINCT ON \(symbol, ts\) .* INSERT INTO price_history .* WHERE NOT EXISTS`).
+			WithArgs(args...).WillReturnRows(pgxmock.NewRows([]string{"ord"}).AddRow(int64(1)))
+		mock.ExpectCommit()
+
+		inserted, appErr := repo.InsertBatch(ctx, rows)
+
+		assert.Nil(t, appErr)
+		assert.Equal(t, []bool{true, false, false}, inserted)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("db_error_rolls_back", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewPriceRepository(mock, nopLogger)
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(importLockKey).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery(`INSERT INTO price_history`).WithArgs(args...).WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		_, appErr := repo.InsertBatch(ctx, rows)
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusInternalServerError, appErr.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,19 +1,19 @@
 package repository
 
 import (
-	"database/sql"
-
 	"github.com/adal4ik/crypto-service/pkg/logger"
 )
 
 type Repository struct {
 	CurrencyRepository CurrencyRepositoryInterface
 	Price              PriceRepositoryInterface
+	Health             *DBHealth
 }
 
-func NewRepository(db *sql.DB, logger logger.Logger) *Repository {
+func NewRepository(db DB, logger logger.Logger) *Repository {
 	return &Repository{
 		CurrencyRepository: NewCurrencyRepository(db, logger),
 		Price:              NewPriceRepository(db, logger),
+		Health:             NewDBHealth(db, logger),
 	}
 }
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
+)
+
+// adaptiveScheduler хранит для каждого символа последние лог-доходности и
+// вычисляет из них интервал опроса: чем выше волатильность, тем чаще опрос.
+// Доходности приводятся к базовому интервалу делением на sqrt(dt/Interval), иначе
+// длинный интервал давал бы крупные доходности и следующий интервал раскачивался бы.
+type adaptiveScheduler struct {
+	mu      sync.Mutex
+	cfg     config.CollectorConfig
+	symbols map[string]*symbolState
+}
+
+type symbolState struct {
+	lastPrice  float64
+	lastAt     time.Time
+	returns    []float64
+	volatility float64
+	interval   time.Duration
+	nextDue    time.Time
+}
+
+func newAdaptiveScheduler(cfg config.CollectorConfig) *adaptiveScheduler {
+	return &adaptiveScheduler{
+		cfg:     cfg,
+		symbols: make(map[string]*symbolState),
+	}
+}
+
+// due возвращает символы, которые пора опрашивать, и забывает те, что больше не отслеживаются.
+func (s *adaptiveScheduler) due(symbols []string, now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tracked := make(map[string]struct{}, len(symbols))
+	var due []string
+	for _, symbol := range symbols {
+		tracked[symbol] = struct{}{}
+		st, ok := s.symbols[symbol]
+		if !ok {
+			st = &symbolState{interval: s.clamp(s.cfg.Interval)}
+			s.symbols[symbol] = st
+		}
+		if !now.Before(st.nextDue) {
+			due = append(due, symbol)
+		}
+	}
+	for symbol := range s.symbols {
+		if _, ok := tracked[symbol]; !ok {
+			delete(s.symbols, symbol)
+		}
+	}
+	return due
+}
+
+// observe учитывает новую цену и пересчитывает интервал символа.
+func (s *adaptiveScheduler) observe(symbol string, price float64, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.symbols[symbol]
+	if !ok {
+		st = &symbolState{interval: s.clamp(s.cfg.Interval)}
+		s.symbols[symbol] = st
+	}
+
+	if dt := now.Sub(st.lastAt); st.lastPrice > 0 && price > 0 && dt > 0 {
+		st.returns = append(st.returns, math.Log(price/st.lastPrice)/math.Sqrt(s.ticks(dt)))
+		if window := s.cfg.VolatilityWindow; window > 0 && len(st.returns) > window {
+			st.returns = st.returns[len(st.returns)-window:]
+		}
+	}
+	st.lastPrice = price
+	st.lastAt = now
+
+	if len(st.returns) >= 2 {
+		st.volatility = stddev(st.returns)
+		st.interval = s.intervalFor(st.volatility)
+	}
+	st.nextDue = now.Add(st.interval)
+}
+
+func (s *adaptiveScheduler) schedules() []domain.SymbolSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]domain.SymbolSchedule, 0, len(s.symbols))
+	for symbol, st := range s.symbols {
+		result = append(result, domain.SymbolSchedule{
+			Symbol:     symbol,
+			Interval:   st.interval,
+			Volatility: st.volatility,
+			NextDue:    st.nextDue,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Symbol < result[j].Symbol })
+	return result
+}
+
+// ticks выражает промежуток между сэмплами в базовых интервалах.
+func (s *adaptiveScheduler) ticks(dt time.Duration) float64 {
+	if s.cfg.Interval <= 0 {
+		return dt.Minutes()
+	}
+	return float64(dt) / float64(s.cfg.Interval)
+}
+
+// intervalFor масштабирует базовый интервал обратно пропорционально волатильности.
+func (s *adaptiveScheduler) intervalFor(volatility float64) time.Duration {
+	if volatility <= 0 || s.cfg.VolatilityTarget <= 0 {
+		return s.clamp(s.cfg.MaxInterval)
+	}
+	scaled := float64(s.cfg.Interval) * s.cfg.VolatilityTarget / volatility
+	if scaled > float64(math.MaxInt64) {
+		return s.clamp(s.cfg.MaxInterval)
+	}
+	return s.clamp(time.Duration(scaled))
+}
+
+func (s *adaptiveScheduler) clamp(d time.Duration) time.Duration {
+	if s.cfg.MinInterval > 0 && d < s.cfg.MinInterval {
+		return s.cfg.MinInterval
+	}
+	if s.cfg.MaxInterval > 0 && d > s.cfg.MaxInterval {
+		return s.cfg.MaxInterval
+	}
+	return d
+}
+
+func stddev(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
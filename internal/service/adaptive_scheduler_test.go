@@ -0,0 +1,113 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveScheduler(t *testing.T) {
+	cfg := config.CollectorConfig{
+		Interval:         60 * time.Second,
+		MinInterval:      10 * time.Second,
+		MaxInterval:      300 * time.Second,
+		VolatilityWindow: 5,
+		VolatilityTarget: 0.001,
+	}
+	start := time.Unix(1700000000, 0)
+
+	t.Run("new_symbols_are_due_immediately", func(t *testing.T) {
+		s := newAdaptiveScheduler(cfg)
+
+		due := s.due([]string{"BTC", "ETH"}, start)
+
+		assert.ElementsMatch(t, []string{"BTC", "ETH"}, due)
+	})
+
+	t.Run("flat_market_grows_to_max_interval", func(t *testing.T) {
+		s := newAdaptiveScheduler(cfg)
+		s.due([]string{"BTC"}, start)
+
+		now := start
+		for i := 0; i < 5; i++ {
+			s.observe("BTC", 100, now)
+			now = now.Add(time.Minute)
+		}
+
+		schedules := s.schedules()
+		require.Len(t, schedules, 1)
+		assert.Equal(t, cfg.MaxInterval, schedules[0].Interval)
+		assert.Empty(t, s.due([]string{"BTC"}, now.Add(-time.Minute).Add(cfg.MaxInterval-time.Second)))
+	})
+
+	t.Run("volatile_market_shrinks_to_min_interval", func(t *testing.T) {
+		s := newAdaptiveScheduler(cfg)
+		s.due([]string{"BTC"}, start)
+
+		now := start
+		for i, p := range []float64{100, 110, 95, 120, 90} {
+			s.observe("BTC", p, now.Add(time.Duration(i)*time.Minute))
+		}
+
+		schedules := s.schedules()
+		require.Len(t, schedules, 1)
+		assert.Equal(t, cfg.MinInterval, schedules[0].Interval)
+		assert.Greater(t, schedules[0].Volatility, cfg.VolatilityTarget)
+	})
+
+	t.Run("calming_market_grows_back", func(t *testing.T) {
+		s := newAdaptiveScheduler(cfg)
+		s.due([]string{"BTC"}, start)
+
+		now := start
+		for _, p := range []float64{100, 110, 95, 120, 90} {
+			s.observe("BTC", p, now)
+			now = now.Add(time.Minute)
+		}
+		for i := 0; i < cfg.VolatilityWindow+1; i++ {
+			s.observe("BTC", 90, now)
+			now = now.Add(time.Minute)
+		}
+
+		assert.Equal(t, cfg.MaxInterval, s.schedules()[0].Interval)
+	})
+
+	t.Run("interval_does_not_depend_on_sampling_interval", func(t *testing.T) {
+		// Одно и то же случайное блуждание с волатильностью 0.0005 за минуту,
+		// снятое с шагом 30 секунд и 2 минуты: доходность шага растёт как sqrt(dt).
+		signs := []float64{1, -1, -1, 1, 1, -1, 1}
+		walk := func(step time.Duration) time.Duration {
+			s := newAdaptiveScheduler(cfg)
+			s.due([]string{"BTC"}, start)
+			price, now := 100.0, start
+			s.observe("BTC", price, now)
+			for _, sign := range signs {
+				price *= math.Exp(sign * 0.0005 * math.Sqrt(step.Minutes()))
+				now = now.Add(step)
+				s.observe("BTC", price, now)
+			}
+			return s.schedules()[0].Interval
+		}
+
+		fast, slow := walk(30*time.Second), walk(2*time.Minute)
+
+		assert.InDelta(t, float64(fast), float64(slow), float64(time.Millisecond))
+		assert.Greater(t, fast, cfg.MinInterval)
+		assert.Less(t, fast, cfg.MaxInterval)
+	})
+
+	t.Run("untracked_symbols_are_forgotten", func(t *testing.T) {
+		s := newAdaptiveScheduler(cfg)
+		s.due([]string{"BTC", "ETH"}, start)
+
+		s.due([]string{"BTC"}, start)
+
+		schedules := s.schedules()
+		require.Len(t, schedules, 1)
+		assert.Equal(t, "BTC", schedules[0].Symbol)
+	})
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository"
+	"github.com/adal4ik/crypto-service/internal/timeseries"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+const (
+	maxSeriesSymbols = 20
+	maxSeriesPoints  = 100000
+
+	defaultStatsWindow = 24 * time.Hour
+	secondsPerYear     = 365.25 * 24 * 60 * 60
+	volatilityPlaces   = 8
+
+	maxPerformanceWindows = 10
+	ytdWindow             = "ytd"
+
+	defaultCorrelationInterval = time.Hour
+	defaultCorrelationWindow   = 30 * 24 * time.Hour
+	minCorrelationObservations = 3
+	correlationPlaces          = 6
+)
+
+// defaultPerformanceWindows - окна, если в запросе они не заданы.
+var defaultPerformanceWindows = []string{"1h", "24h", "7d", "30d", ytdWindow}
+
+type AnalyticsServiceInterface interface {
+	Resample(ctx context.Context, req domain.ResampleRequest) ([]domain.Series, *apperrors.AppError)
+	Stats(ctx context.Context, req domain.StatsRequest) (domain.PriceStats, *apperrors.AppError)
+	Performance(ctx context.Context, req domain.PerformanceRequest) ([]domain.Performance, *apperrors.AppError)
+	Correlation(ctx context.Context, req domain.CorrelationRequest) (domain.CorrelationMatrix, *apperrors.AppError)
+	Indicators(ctx context.Context, req domain.IndicatorRequest) (domain.IndicatorSeries, *apperrors.AppError)
+	Drawdown(ctx context.Context, req domain.DrawdownRequest) (domain.DrawdownReport, *apperrors.AppError)
+}
+
+type analyticsService struct {
+	repo   repository.PriceRepositoryInterface
+	logger logger.Logger
+	now    func() time.Time
+}
+
+func NewAnalyticsService(repo repository.PriceRepositoryInterface, logger logger.Logger) AnalyticsServiceInterface {
+	return &analyticsService{repo: repo, logger: logger, now: time.Now}
+}
+
+// Resample возвращает по одному значению на точку сетки [From, To] с шагом Step для каждой валюты.
+func (s *analyticsService) Resample(ctx context.Context, req domain.ResampleRequest) ([]domain.Series, *apperrors.AppError) {
+	l := s.logger.With(zap.Strings("symbols", req.Symbols), zap.String("step", req.Step), zap.String("layer", "analytics_service"))
+	l.Info("Resampling price series")
+
+	symbols, appErr := normalizeSymbols(req.Symbols)
+	if appErr != nil {
+		return nil, appErr
+	}
+	step, err := parseStep(req.Step)
+	if err != nil {
+		return nil, apperrors.NewBadRequest("step must be a positive duration like 15m, 1h or 1d", err)
+	}
+	fill, err := timeseries.ParseFillStrategy(req.Fill)
+	if err != nil {
+		return nil, apperrors.NewBadRequest("fill must be one of previous, linear, null", err)
+	}
+	if req.From.IsZero() || req.To.IsZero() {
+		return nil, apperrors.NewBadRequest("'from' and 'to' are required", nil)
+	}
+	if req.From.After(req.To) {
+		return nil, apperrors.NewBadRequest("'from' must not be after 'to'", nil)
+	}
+	points := int(req.To.Sub(req.From)/step) + 1
+	if points*len(symbols) > maxSeriesPoints {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("request spans %d points, at most %d allowed", points*len(symbols), maxSeriesPoints), nil)
+	}
+
+	grid := timeseries.Grid(req.From, req.To, step)
+	result := make([]domain.Series, 0, len(symbols))
+	for _, symbol := range symbols {
+		series, appErr := s.resampleSymbol(ctx, symbol, grid, step, fill)
+		if appErr != nil {
+			return nil, appErr
+		}
+		result = append(result, series)
+	}
+	return result, nil
+}
+
+func (s *analyticsService) resampleSymbol(ctx context.Context, symbol string, grid []time.Time, step time.Duration, fill timeseries.FillStrategy) (domain.Series, *apperrors.AppError) {
+	points, appErr := s.loadPoints(ctx, symbol, grid[0].Add(-step), grid[len(grid)-1], step, fill == timeseries.FillLinear)
+	if appErr != nil {
+		return domain.Series{}, appErr
+	}
+
+	values := timeseries.Resample(points, grid, step, fill)
+	series := domain.Series{Symbol: symbol, Points: make([]domain.SeriesPoint, 0, len(values))}
+	for _, v := range values {
+		series.Points = append(series.Points, domain.SeriesPoint{Time: v.Time, Value: v.Value, Valid: v.Valid, Observed: v.Observed})
+	}
+	return series, nil
+}
+
+// loadPoints читает первый и последний сэмплы каждой ячейки сетки (from, to] с шагом step вместе
+// с последним сэмплом не позже from и, если нужно для интерполяции, первым сэмплом не раньше to.
+// Для Resample этого достаточно: внутри ячейки важен только последний сэмпл, а для интерполяции
+// пустой ячейки - первый сэмпл следующей непустой. Поэтому объём не зависит от частоты сбора.
+func (s *analyticsService) loadPoints(ctx context.Context, symbol string, from, to time.Time, step time.Duration, withNext bool) ([]timeseries.Point, *apperrors.AppError) {
+	before, _, appErr := s.repo.GetBracketing(ctx, symbol, from)
+	if appErr != nil {
+		return nil, appErr
+	}
+	buckets, appErr := s.repo.GetBucketEdges(ctx, domain.BucketQuery{Symbol: symbol, From: from, To: to, Step: step})
+	if appErr != nil {
+		return nil, appErr
+	}
+	var next *domain.PriceSample
+	if withNext {
+		if _, next, appErr = s.repo.GetBracketing(ctx, symbol, to); appErr != nil {
+			return nil, appErr
+		}
+	}
+
+	points := make([]timeseries.Point, 0, 2*len(buckets)+2)
+	appendPoint := func(sample domain.PriceSample) {
+		if n := len(points); n > 0 && !sample.Timestamp.After(points[n-1].Time) {
+			return
+		}
+		points = append(points, timeseries.Point{Time: sample.Timestamp, Value: sample.Price})
+	}
+	if before != nil {
+		appendPoint(*before)
+	}
+	for _, bucket := range buckets {
+		appendPoint(bucket.First)
+		appendPoint(bucket.Last)
+	}
+	if next != nil {
+		appendPoint(*next)
+	}
+	return points, nil
+}
+
+// normalizeSymbols приводит символы к верхнему регистру и убирает дубликаты.
+func normalizeSymbols(symbols []string) ([]string, *apperrors.AppError) {
+	seen := make(map[string]struct{}, len(symbols))
+	var result []string
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" {
+			continue
+		}
+		if _, ok := seen[symbol]; ok {
+			continue
+		}
+		seen[symbol] = struct{}{}
+		result = append(result, symbol)
+	}
+	if len(result) == 0 {
+		return nil, apperrors.NewBadRequest("at least one currency symbol is required", nil)
+	}
+	if len(result) > maxSeriesSymbols {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("at most %d symbols allowed", maxSeriesSymbols), nil)
+	}
+	return result, nil
+}
+
+// parseStep разбирает длительность в формате time.ParseDuration, дополнительно понимая дни ("1d").
+func parseStep(raw string) (time.Duration, error) {
+	var step time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		step = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if step, err = time.ParseDuration(raw); err != nil {
+			return 0, err
+		}
+	}
+	if step <= 0 {
+		return 0, fmt.Errorf("step must be positive, got %s", raw)
+	}
+	return step, nil
+}
+
+// Stats считает описательную статистику цен в окне [From, To] (по умолчанию - последние сутки).
+// Волатильность - корень из суммы квадратов лог-доходностей, делённой на длину окна сэмплов
+// в годах, поэтому не зависит от частоты сбора.
+func (s *analyticsService) Stats(ctx context.Context, req domain.StatsRequest) (domain.PriceStats, *apperrors.AppError) {
+	l := s.logger.With(zap.String("symbol", req.Symbol), zap.String("layer", "analytics_service"))
+	l.Info("Getting price stats")
+
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if req.Symbol == "" {
+		return domain.PriceStats{}, apperrors.NewBadRequest("currency symbol cannot be empty", nil)
+	}
+	if req.To.IsZero() {
+		req.To = s.now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-defaultStatsWindow)
+	}
+	if req.From.After(req.To) {
+		return domain.PriceStats{}, apperrors.NewBadRequest("'from' must not be after 'to'", nil)
+	}
+
+	stats, appErr := s.repo.GetStats(ctx, req)
+	if appErr != nil {
+		return domain.PriceStats{}, appErr
+	}
+	stats.Volatility = annualizedVolatility(stats)
+	return stats, nil
+}
+
+func annualizedVolatility(stats domain.PriceStats) *decimal.Decimal {
+	if stats.Count < 2 || stats.LogReturnsSumSq == nil || stats.FirstSample == nil || stats.LastSample == nil {
+		return nil
+	}
+	years := stats.LastSample.Sub(*stats.FirstSample).Seconds() / secondsPerYear
+	if years <= 0 {
+		return nil
+	}
+	vol := decimal.NewFromFloat(math.Sqrt(stats.LogReturnsSumSq.InexactFloat64() / years)).Round(volatilityPlaces)
+	return &vol
+}
+
+// Performance считает абсолютное и процентное изменение последней цены относительно начала
+// каждого окна. База окна - последний сэмпл не позже его начала; если история начинается позже,
+// берётся самый ранний сэмпл, и окно помечается HistoryIncomplete.
+func (s *analyticsService) Performance(ctx context.Context, req domain.PerformanceRequest) ([]domain.Performance, *apperrors.AppError) {
+	l := s.logger.With(zap.Strings("symbols", req.Symbols), zap.Strings("windows", req.Windows), zap.String("layer", "analytics_service"))
+	l.Info("Getting price performance")
+
+	var symbols []string
+	if len(req.Symbols) > 0 {
+		var appErr *apperrors.AppError
+		if symbols, appErr = normalizeSymbols(req.Symbols); appErr != nil {
+			return nil, appErr
+		}
+	}
+	names := req.Windows
+	if len(names) == 0 {
+		names = defaultPerformanceWindows
+	}
+	if len(names) > maxPerformanceWindows {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("at most %d windows allowed", maxPerformanceWindows), nil)
+	}
+	now := s.now()
+	starts := make([]time.Time, len(names))
+	for i, name := range names {
+		start, err := windowStart(name, now)
+		if err != nil {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("invalid window %q: use a duration like 1h, 7d or 'ytd'", name), err)
+		}
+		starts[i] = start
+	}
+
+	anchors, appErr := s.repo.GetWindowAnchors(ctx, symbols, starts)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	result := make([]domain.Performance, 0, len(anchors))
+	for _, a := range anchors {
+		perf := domain.Performance{Symbol: a.Symbol, Latest: a.Latest, Windows: make([]domain.WindowChange, 0, len(names))}
+		for i, name := range names {
+			change := domain.WindowChange{Window: name, Start: starts[i], Base: a.Earliest, HistoryIncomplete: true}
+			if base := a.Bases[i]; base != nil {
+				change.Base, change.HistoryIncomplete = *base, false
+			}
+			change.Change = a.Latest.Price.Sub(change.Base.Price)
+			if !change.Base.Price.IsZero() {
+				percent := change.Change.Div(change.Base.Price).Mul(decimal.NewFromInt(100)).Round(4)
+				change.ChangePercent = &percent
+			}
+			perf.Windows = append(perf.Windows, change)
+		}
+		result = append(result, perf)
+	}
+	return result, nil
+}
+
+// windowStart возвращает начало окна: now минус длительность или 1 января текущего года (UTC) для ytd.
+func windowStart(name string, now time.Time) (time.Time, error) {
+	if strings.EqualFold(name, ytdWindow) {
+		return time.Date(now.UTC().Year(), time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	d, err := parseStep(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(-d), nil
+}
+
+// Correlation выравнивает ряды валют на общую сетку с шагом Interval без заполнения пропусков,
+// считает лог-доходности между соседними наблюдёнными ячейками и по каждой паре - корреляцию
+// на общих наблюдениях.
+func (s *analyticsService) Correlation(ctx context.Context, req domain.CorrelationRequest) (domain.CorrelationMatrix, *apperrors.AppError) {
+	l := s.logger.With(zap.Strings("symbols", req.Symbols), zap.String("interval", req.Interval), zap.String("layer", "analytics_service"))
+	l.Info("Computing correlation matrix")
+
+	symbols, appErr := normalizeSymbols(req.Symbols)
+	if appErr != nil {
+		return domain.CorrelationMatrix{}, appErr
+	}
+	if len(symbols) < 2 {
+		return domain.CorrelationMatrix{}, apperrors.NewBadRequest("at least two currency symbols are required", nil)
+	}
+	step := defaultCorrelationInterval
+	if req.Interval != "" {
+		var err error
+		if step, err = parseStep(req.Interval); err != nil {
+			return domain.CorrelationMatrix{}, apperrors.NewBadRequest("interval must be a positive duration like 15m, 1h or 1d", err)
+		}
+	}
+	if req.To.IsZero() {
+		req.To = s.now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-defaultCorrelationWindow)
+	}
+	if !req.From.Before(req.To) {
+		return domain.CorrelationMatrix{}, apperrors.NewBadRequest("'from' must be before 'to'", nil)
+	}
+	points := int(req.To.Sub(req.From)/step) + 1
+	if points*len(symbols) > maxSeriesPoints {
+		return domain.CorrelationMatrix{}, apperrors.NewBadRequest(fmt.Sprintf("request spans %d points, at most %d allowed", points*len(symbols), maxSeriesPoints), nil)
+	}
+
+	grid := timeseries.Grid(req.From, req.To, step)
+	returns := make([][]float64, len(symbols))
+	for i, symbol := range symbols {
+		// Без заполнения: протянутая вперёд цена дала бы нулевые доходности и занизила корреляцию.
+		// Доходности, задевающие пустую ячейку, становятся NaN и выпадают из пересечения рядов.
+		series, appErr := s.resampleSymbol(ctx, symbol, grid, step, timeseries.FillNull)
+		if appErr != nil {
+			return domain.CorrelationMatrix{}, appErr
+		}
+		values := make([]timeseries.GridValue, 0, len(series.Points))
+		for _, p := range series.Points {
+			values = append(values, timeseries.GridValue{Time: p.Time, Value: p.Value, Valid: p.Valid, Observed: p.Observed})
+		}
+		returns[i] = timeseries.LogReturns(values)
+	}
+
+	n := len(symbols)
+	matrix := domain.CorrelationMatrix{
+		Symbols:      symbols,
+		From:         req.From,
+		To:           req.To,
+		Interval:     step,
+		Pearson:      squareMatrix[*float64](n),
+		Observations: squareMatrix[int](n),
+	}
+	if req.Spearman {
+		matrix.Spearman = squareMatrix[*float64](n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			x, y := timeseries.Overlap(returns[i], returns[j])
+			matrix.Observations[i][j], matrix.Observations[j][i] = len(x), len(x)
+			if len(x) < minCorrelationObservations {
+				continue
+			}
+			matrix.Pearson[i][j] = roundedCoefficient(timeseries.Pearson(x, y))
+			matrix.Pearson[j][i] = matrix.Pearson[i][j]
+			if req.Spearman {
+				matrix.Spearman[i][j] = roundedCoefficient(timeseries.Spearman(x, y))
+				matrix.Spearman[j][i] = matrix.Spearman[i][j]
+			}
+		}
+	}
+	return matrix, nil
+}
+
+func squareMatrix[T any](n int) [][]T {
+	m := make([][]T, n)
+	for i := range m {
+		m[i] = make([]T, n)
+	}
+	return m
+}
+
+func roundedCoefficient(r float64, ok bool) *float64 {
+	if !ok {
+		return nil
+	}
+	scale := math.Pow(10, correlationPlaces)
+	r = math.Round(r*scale) / scale
+	return &r
+}
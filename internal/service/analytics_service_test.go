@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository/mocks"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func priceSample(symbol string, ts time.Time, price int64) domain.PriceSample {
+	return domain.PriceSample{Symbol: symbol, Price: decimal.NewFromInt(price), Timestamp: ts}
+}
+
+// singleSampleBuckets - ячейки сетки, в каждую из которых попал ровно один сэмпл.
+func singleSampleBuckets(samples ...domain.PriceSample) []domain.PriceBucket {
+	buckets := make([]domain.PriceBucket, 0, len(samples))
+	for _, sample := range samples {
+		buckets = append(buckets, domain.PriceBucket{First: sample, Last: sample})
+	}
+	return buckets
+}
+
+func TestAnalyticsService_Resample(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(30 * time.Minute)
+	step := 15 * time.Minute
+
+	t.Run("success_linear_fill", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		before := priceSample("BTC", from.Add(-20*time.Minute), 90)
+		after := priceSample("BTC", to.Add(10*time.Minute), 130)
+		mockRepo.On("GetBracketing", ctx, "BTC", from.Add(-step)).Return(&before, nil, nil)
+		mockRepo.On("GetBucketEdges", ctx, domain.BucketQuery{Symbol: "BTC", From: from.Add(-step), To: to, Step: step}).
+			Return(singleSampleBuckets(priceSample("BTC", from.Add(10*time.Minute), 100)), nil)
+		mockRepo.On("GetBracketing", ctx, "BTC", to).Return(nil, &after, nil)
+
+		series, appErr := analytics.Resample(ctx, domain.ResampleRequest{
+			Symbols: []string{"btc", "BTC"}, From: from, To: to, Step: "15m", Fill: "linear",
+		})
+
+		require.Nil(t, appErr)
+		require.Len(t, series, 1)
+		points := series[0].Points
+		require.Len(t, points, 3)
+		assert.False(t, points[0].Observed)
+		assert.Equal(t, "96.66666667", points[0].Value.String())
+		assert.True(t, points[1].Observed)
+		assert.Equal(t, "100", points[1].Value.String())
+		assert.False(t, points[2].Observed)
+		assert.Equal(t, "120", points[2].Value.String())
+	})
+
+	t.Run("bucket_edges", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		// В ячейке (from, from+15m] два сэмпла: точка сетки берёт последний, а интерполяция
+		// пустой ячейки перед ней опирается на первый.
+		before := priceSample("BTC", from.Add(-20*time.Minute), 90)
+		mockRepo.On("GetBracketing", ctx, "BTC", from.Add(-step)).Return(&before, nil, nil)
+		mockRepo.On("GetBucketEdges", ctx, domain.BucketQuery{Symbol: "BTC", From: from.Add(-step), To: to, Step: step}).
+			Return([]domain.PriceBucket{{
+				First: priceSample("BTC", from.Add(5*time.Minute), 94),
+				Last:  priceSample("BTC", from.Add(10*time.Minute), 100),
+			}}, nil)
+		mockRepo.On("GetBracketing", ctx, "BTC", to).Return(nil, nil, nil)
+
+		series, appErr := analytics.Resample(ctx, domain.ResampleRequest{
+			Symbols: []string{"BTC"}, From: from, To: to, Step: "15m", Fill: "linear",
+		})
+
+		require.Nil(t, appErr)
+		points := series[0].Points
+		require.Len(t, points, 3)
+		assert.Equal(t, "93.2", points[0].Value.String())
+		assert.True(t, points[1].Observed)
+		assert.Equal(t, "100", points[1].Value.String())
+		assert.False(t, points[2].Valid)
+	})
+
+	t.Run("failure_invalid_request", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		cases := []domain.ResampleRequest{
+			{Symbols: nil, From: from, To: to, Step: "15m"},
+			{Symbols: []string{"BTC"}, From: from, To: to, Step: "-1m"},
+			{Symbols: []string{"BTC"}, From: from, To: to, Step: "15m", Fill: "spline"},
+			{Symbols: []string{"BTC"}, To: to, Step: "15m"},
+			{Symbols: []string{"BTC"}, From: to, To: from, Step: "15m"},
+			{Symbols: []string{"BTC"}, From: from, To: from.AddDate(1, 0, 0), Step: "1m"},
+		}
+		for _, req := range cases {
+			_, appErr := analytics.Resample(ctx, req)
+			require.Error(t, appErr)
+			assert.Equal(t, http.StatusBadRequest, appErr.Code)
+		}
+	})
+}
+
+func TestParseStep(t *testing.T) {
+	step, err := parseStep("15m")
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, step)
+
+	step, err = parseStep("2d")
+	require.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, step)
+
+	_, err = parseStep("0s")
+	assert.Error(t, err)
+}
+
+func TestAnalyticsService_Stats(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	to := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+
+	t.Run("annualized_volatility", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		// Сумма квадратов доходностей 0.0001 за 1/365.25 года: sqrt(0.0001 * 365.25) = 0.19111514...
+		first, last := from, to
+		sumSq := decimal.RequireFromString("0.0001")
+		mockRepo.On("GetStats", ctx, domain.StatsRequest{Symbol: "BTC", From: from, To: to}).Return(domain.PriceStats{
+			Symbol: "BTC", From: from, To: to, Count: 1441,
+			FirstSample: &first, LastSample: &last, LogReturnsSumSq: &sumSq,
+		}, nil)
+
+		stats, appErr := analytics.Stats(ctx, domain.StatsRequest{Symbol: " btc", From: from, To: to})
+
+		require.Nil(t, appErr)
+		require.NotNil(t, stats.Volatility)
+		assert.Equal(t, "0.19111515", stats.Volatility.String())
+	})
+
+	t.Run("single_sample_has_no_volatility", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		mockRepo.On("GetStats", ctx, domain.StatsRequest{Symbol: "BTC", From: from, To: to}).Return(domain.PriceStats{
+			Symbol: "BTC", From: from, To: to, Count: 1, FirstSample: &from, LastSample: &from,
+		}, nil)
+
+		stats, appErr := analytics.Stats(ctx, domain.StatsRequest{Symbol: "BTC", From: from, To: to})
+
+		require.Nil(t, appErr)
+		assert.Nil(t, stats.Volatility)
+	})
+
+	t.Run("default_window", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		mockRepo.On("GetStats", ctx, domain.StatsRequest{Symbol: "BTC", From: from, To: to}).Return(domain.PriceStats{Symbol: "BTC"}, nil)
+
+		_, appErr := analytics.Stats(ctx, domain.StatsRequest{Symbol: "BTC", To: to})
+
+		require.Nil(t, appErr)
+	})
+
+	t.Run("invalid_window", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		_, appErr := analytics.Stats(ctx, domain.StatsRequest{Symbol: "BTC", From: to, To: from})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
+
+func TestAnalyticsService_Performance(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("windows_and_incomplete_history", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := &analyticsService{repo: mockRepo, logger: nopLogger, now: func() time.Time { return now }}
+
+		starts := []time.Time{now.Add(-24 * time.Hour), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		dayAgo := priceSample("BTC", starts[0].Add(-time.Minute), 100)
+		mockRepo.On("GetWindowAnchors", ctx, []string{"BTC"}, starts).Return([]domain.PerformanceAnchors{{
+			Symbol:   "BTC",
+			Latest:   priceSample("BTC", now, 110),
+			Earliest: priceSample("BTC", now.Add(-10*24*time.Hour), 88),
+			Bases:    []*domain.PriceSample{&dayAgo, nil},
+		}}, nil)
+
+		perf, appErr := analytics.Performance(ctx, domain.PerformanceRequest{Symbols: []string{"btc"}, Windows: []string{"1d", "YTD"}})
+
+		require.Nil(t, appErr)
+		require.Len(t, perf, 1)
+		require.Len(t, perf[0].Windows, 2)
+		day, ytd := perf[0].Windows[0], perf[0].Windows[1]
+		assert.False(t, day.HistoryIncomplete)
+		assert.Equal(t, "10", day.Change.String())
+		assert.Equal(t, "10", day.ChangePercent.String())
+		assert.True(t, ytd.HistoryIncomplete)
+		assert.Equal(t, "22", ytd.Change.String())
+		assert.Equal(t, "25", ytd.ChangePercent.String())
+	})
+
+	t.Run("default_windows_for_all_currencies", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := &analyticsService{repo: mockRepo, logger: nopLogger, now: func() time.Time { return now }}
+
+		mockRepo.On("GetWindowAnchors", ctx, []string(nil), mock.MatchedBy(func(starts []time.Time) bool {
+			return len(starts) == len(defaultPerformanceWindows)
+		})).Return(nil, nil)
+
+		perf, appErr := analytics.Performance(ctx, domain.PerformanceRequest{})
+
+		require.Nil(t, appErr)
+		assert.Empty(t, perf)
+	})
+
+	t.Run("invalid_window", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		_, appErr := analytics.Performance(ctx, domain.PerformanceRequest{Windows: []string{"week"}})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
+
+func TestAnalyticsService_Correlation(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Hour)
+
+	history := func(symbol string, prices ...int64) []domain.PriceBucket {
+		samples := make([]domain.PriceSample, 0, len(prices))
+		for i, p := range prices {
+			samples = append(samples, priceSample(symbol, from.Add(time.Duration(i)*time.Hour), p))
+		}
+		return singleSampleBuckets(samples...)
+	}
+
+	t.Run("pearson_and_spearman", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		mockRepo.On("GetBracketing", ctx, mock.Anything, from.Add(-time.Hour)).Return(nil, nil, nil)
+		// SOL движется против BTC.
+		mockRepo.On("GetBucketEdges", ctx, mock.MatchedBy(func(q domain.BucketQuery) bool { return q.Symbol == "BTC" })).
+			Return(history("BTC", 100, 110, 99, 120, 118, 130), nil)
+		mockRepo.On("GetBucketEdges", ctx, mock.MatchedBy(func(q domain.BucketQuery) bool { return q.Symbol == "SOL" })).
+			Return(history("SOL", 50, 45, 52, 40, 41, 35), nil)
+
+		matrix, appErr := analytics.Correlation(ctx, domain.CorrelationRequest{
+			Symbols: []string{"btc", "sol"}, From: from, To: to, Interval: "1h", Spearman: true,
+		})
+
+		require.Nil(t, appErr)
+		assert.Equal(t, []string{"BTC", "SOL"}, matrix.Symbols)
+		assert.Equal(t, [][]int{{5, 5}, {5, 5}}, matrix.Observations)
+		require.NotNil(t, matrix.Pearson[0][0])
+		assert.Equal(t, 1.0, *matrix.Pearson[0][0])
+		require.NotNil(t, matrix.Pearson[0][1])
+		assert.Less(t, *matrix.Pearson[0][1], -0.9)
+		assert.Equal(t, matrix.Pearson[0][1], matrix.Pearson[1][0])
+		require.NotNil(t, matrix.Spearman[0][1])
+		assert.Equal(t, -1.0, *matrix.Spearman[0][1])
+	})
+
+	t.Run("gaps_are_not_forward_filled", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		mockRepo.On("GetBracketing", ctx, mock.Anything, from.Add(-time.Hour)).Return(nil, nil, nil)
+		mockRepo.On("GetBucketEdges", ctx, mock.MatchedBy(func(q domain.BucketQuery) bool { return q.Symbol == "BTC" })).
+			Return(history("BTC", 100, 110, 99, 120, 118, 130), nil)
+		// У ETH нет сэмпла в 02:00: обе доходности вокруг пропуска выпадают, а не становятся нулями.
+		eth := history("ETH", 10, 11, 0, 12, 11, 13)
+		mockRepo.On("GetBucketEdges", ctx, mock.MatchedBy(func(q domain.BucketQuery) bool { return q.Symbol == "ETH" })).
+			Return(append(eth[:2:2], eth[3:]...), nil)
+
+		matrix, appErr := analytics.Correlation(ctx, domain.CorrelationRequest{
+			Symbols: []string{"BTC", "ETH"}, From: from, To: to, Interval: "1h",
+		})
+
+		require.Nil(t, appErr)
+		assert.Equal(t, time.Hour, matrix.Interval)
+		assert.Equal(t, [][]int{{5, 3}, {3, 3}}, matrix.Observations)
+		require.NotNil(t, matrix.Pearson[0][1])
+	})
+
+	t.Run("no_overlap", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		mockRepo.On("GetBracketing", ctx, mock.Anything, from.Add(-time.Hour)).Return(nil, nil, nil)
+		mockRepo.On("GetBucketEdges", ctx, mock.MatchedBy(func(q domain.BucketQuery) bool { return q.Symbol == "BTC" })).
+			Return(history("BTC", 100, 110, 99, 120, 118, 130), nil)
+		mockRepo.On("GetBucketEdges", ctx, mock.MatchedBy(func(q domain.BucketQuery) bool { return q.Symbol == "ETH" })).
+			Return(nil, nil)
+
+		matrix, appErr := analytics.Correlation(ctx, domain.CorrelationRequest{
+			Symbols: []string{"BTC", "ETH"}, From: from, To: to, Interval: "1h",
+		})
+
+		require.Nil(t, appErr)
+		assert.Equal(t, 0, matrix.Observations[0][1])
+		assert.Equal(t, 5, matrix.Observations[0][0])
+		assert.Nil(t, matrix.Pearson[0][1])
+		assert.Nil(t, matrix.Spearman)
+	})
+
+	t.Run("single_symbol", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		_, appErr := analytics.Correlation(ctx, domain.CorrelationRequest{Symbols: []string{"BTC"}})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultCandleCount = 100
+	maxCandleCount     = 5000
+)
+
+var candleIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// bucketOrigin совпадает с origin в date_bin запроса свечей.
+var bucketOrigin = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// GetCandles строит OHLC-свечи за период. Границы периода расширяются до границ бакетов,
+// пустые бакеты по запросу добавляются с флагом Empty.
+func (s *priceService) GetCandles(ctx context.Context, req domain.CandleRequest) ([]domain.Candle, *apperrors.AppError) {
+	l := s.logger.With(zap.String("symbol", req.Symbol), zap.String("interval", req.Interval), zap.String("layer", "price_service"))
+	l.Info("Getting candles")
+
+	query, appErr := newCandleQuery(req, time.Now())
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	candles, appErr := s.repo.GetCandles(ctx, query)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if !req.IncludeEmpty {
+		return candles, nil
+	}
+	return fillEmptyCandles(candles, query), nil
+}
+
+func newCandleQuery(req domain.CandleRequest, now time.Time) (domain.CandleQuery, *apperrors.AppError) {
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if symbol == "" {
+		return domain.CandleQuery{}, apperrors.NewBadRequest("currency symbol cannot be empty", nil)
+	}
+	interval, ok := candleIntervals[req.Interval]
+	if !ok {
+		return domain.CandleQuery{}, apperrors.NewBadRequest("interval must be one of 1m, 5m, 1h, 1d", nil)
+	}
+	loc := time.UTC
+	if req.Timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(req.Timezone)
+		if err != nil || loc == time.Local {
+			return domain.CandleQuery{}, apperrors.NewBadRequest(fmt.Sprintf("unknown timezone %q", req.Timezone), err)
+		}
+	}
+
+	to := req.To
+	if to.IsZero() {
+		to = now
+	}
+	from := req.From
+	if from.IsZero() {
+		from = to.Add(-defaultCandleCount * interval)
+	}
+	if !from.Before(to) {
+		return domain.CandleQuery{}, apperrors.NewBadRequest("'from' must be before 'to'", nil)
+	}
+
+	from = alignBucket(from, interval, loc)
+	if aligned := alignBucket(to, interval, loc); !aligned.Equal(to) {
+		to = nextBucket(aligned, interval, loc)
+	}
+	if buckets := countBuckets(from, to, interval, loc); buckets > maxCandleCount {
+		return domain.CandleQuery{}, apperrors.NewBadRequest(fmt.Sprintf("range spans %d candles, at most %d allowed", buckets, maxCandleCount), nil)
+	}
+
+	return domain.CandleQuery{Symbol: symbol, Interval: interval, From: from, To: to, Location: loc}, nil
+}
+
+// alignBucket возвращает начало бакета, в который попадает t, так же, как запрос свечей.
+// Бакеты короче суток отсчитываются по абсолютному времени от местной полуночи bucketOrigin,
+// поэтому в дни перевода часов каждый бакет длится ровно interval; loc задаёт только их сдвиг
+// (часовые свечи в зоне +05:30 начинаются в :30 по UTC). Суточные бакеты считаются по местному
+// времени loc - как date_bin(interval, t AT TIME ZONE loc, '2000-01-01') - и начинаются в полночь.
+func alignBucket(t time.Time, interval time.Duration, loc *time.Location) time.Time {
+	if interval < 24*time.Hour {
+		origin := fromWallClock(bucketOrigin, loc)
+		return origin.Add(floorDuration(t.Sub(origin), interval))
+	}
+	return fromWallClock(bucketOrigin.Add(floorDuration(wallClock(t, loc).Sub(bucketOrigin), interval)), loc)
+}
+
+func nextBucket(start time.Time, interval time.Duration, loc *time.Location) time.Time {
+	if interval < 24*time.Hour {
+		return start.Add(interval)
+	}
+	return fromWallClock(wallClock(start, loc).Add(interval), loc)
+}
+
+func countBuckets(from, to time.Time, interval time.Duration, loc *time.Location) int {
+	if interval < 24*time.Hour {
+		return int(to.Sub(from) / interval)
+	}
+	return int(wallClock(to, loc).Sub(wallClock(from, loc)) / interval)
+}
+
+// floorDuration округляет d вниз до кратного step, в том числе для отрицательных d.
+func floorDuration(d, step time.Duration) time.Duration {
+	bucket := d - d%step
+	if d%step < 0 {
+		bucket -= step
+	}
+	return bucket
+}
+
+// wallClock переносит местное время t в loc на шкалу UTC, чтобы считать суточные бакеты без учёта DST.
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	lt := t.In(loc)
+	return time.Date(lt.Year(), lt.Month(), lt.Day(), lt.Hour(), lt.Minute(), lt.Second(), lt.Nanosecond(), time.UTC)
+}
+
+func fromWallClock(w time.Time, loc *time.Location) time.Time {
+	return time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), w.Nanosecond(), loc)
+}
+
+// fillEmptyCandles вставляет пустые свечи для бакетов без сэмплов.
+func fillEmptyCandles(candles []domain.Candle, query domain.CandleQuery) []domain.Candle {
+	filled := make([]domain.Candle, 0, countBuckets(query.From, query.To, query.Interval, query.Location))
+	i := 0
+	for start := query.From; start.Before(query.To); start = nextBucket(start, query.Interval, query.Location) {
+		for i < len(candles) && candles[i].Start.Before(start) {
+			filled = append(filled, candles[i])
+			i++
+		}
+		if i < len(candles) && candles[i].Start.Equal(start) {
+			filled = append(filled, candles[i])
+			i++
+			continue
+		}
+		filled = append(filled, domain.Candle{Start: start, Empty: true})
+	}
+	return append(filled, candles[i:]...)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository/mocks"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlignBucket(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	ts := time.Date(2024, 3, 15, 13, 47, 31, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 3, 15, 13, 45, 0, 0, time.UTC), alignBucket(ts, 5*time.Minute, time.UTC))
+	assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), alignBucket(ts, 24*time.Hour, time.UTC))
+	// Полночь по Берлину (UTC+1 в марте до перехода на летнее время).
+	assert.True(t, time.Date(2024, 3, 14, 23, 0, 0, 0, time.UTC).Equal(alignBucket(ts, 24*time.Hour, berlin)))
+	// Часовые свечи в зоне со смещением +05:30 начинаются в :30 по UTC.
+	assert.True(t, time.Date(2024, 3, 15, 13, 30, 0, 0, time.UTC).Equal(alignBucket(ts, time.Hour, kolkata)))
+}
+
+func TestCandleBuckets_DSTFallBack(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 27.10.2024 в Берлине часы переводятся с 03:00 CEST на 02:00 CET: местный час 02:00-03:00 проходит дважды.
+	dayStart := time.Date(2024, 10, 27, 0, 0, 0, 0, berlin)
+	dayEnd := time.Date(2024, 10, 28, 0, 0, 0, 0, berlin)
+	firstTwo := time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC)  // 02:30 CEST
+	secondTwo := time.Date(2024, 10, 27, 1, 30, 0, 0, time.UTC) // 02:30 CET
+
+	t.Run("hourly_buckets_stay_one_hour", func(t *testing.T) {
+		first, second := alignBucket(firstTwo, time.Hour, berlin), alignBucket(secondTwo, time.Hour, berlin)
+		assert.True(t, time.Date(2024, 10, 27, 0, 0, 0, 0, time.UTC).Equal(first))
+		assert.True(t, time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC).Equal(second))
+		assert.True(t, second.Equal(nextBucket(first, time.Hour, berlin)))
+		assert.Equal(t, 25, countBuckets(dayStart, dayEnd, time.Hour, berlin), "the day has 25 hours")
+	})
+
+	t.Run("daily_bucket_starts_at_local_midnight", func(t *testing.T) {
+		assert.True(t, dayStart.Equal(alignBucket(secondTwo, 24*time.Hour, berlin)))
+		assert.True(t, dayEnd.Equal(nextBucket(dayStart, 24*time.Hour, berlin)))
+		assert.Equal(t, 1, countBuckets(dayStart, dayEnd, 24*time.Hour, berlin))
+	})
+
+	t.Run("empty_hourly_candles_cover_both_repeated_hours", func(t *testing.T) {
+		query := domain.CandleQuery{Symbol: "BTC", Interval: time.Hour, From: dayStart, To: dayEnd, Location: berlin}
+
+		filled := fillEmptyCandles(nil, query)
+
+		require.Len(t, filled, 25)
+		assert.True(t, time.Date(2024, 10, 27, 0, 0, 0, 0, time.UTC).Equal(filled[2].Start), "02:00 CEST")
+		assert.True(t, time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC).Equal(filled[3].Start), "02:00 CET")
+	})
+}
+
+func TestPriceService_GetCandles(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	from := time.Date(2024, 3, 15, 10, 7, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 15, 12, 30, 0, 0, time.UTC)
+
+	t.Run("aligns_range_and_flags_empty_buckets", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		expectedQuery := domain.CandleQuery{
+			Symbol:   "BTC",
+			Interval: time.Hour,
+			From:     time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC),
+			To:       time.Date(2024, 3, 15, 13, 0, 0, 0, time.UTC),
+			Location: time.UTC,
+		}
+		candle := domain.Candle{
+			Start: time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC),
+			Open:  decimal.NewFromInt(1), High: decimal.NewFromInt(3), Low: decimal.NewFromInt(1), Close: decimal.NewFromInt(2),
+			Count: 60,
+		}
+		mockRepo.On("GetCandles", ctx, expectedQuery).Return([]domain.Candle{candle}, nil)
+
+		candles, appErr := priceService.GetCandles(ctx, domain.CandleRequest{
+			Symbol: "btc", Interval: "1h", From: from, To: to, IncludeEmpty: true,
+		})
+
+		require.Nil(t, appErr)
+		require.Len(t, candles, 3)
+		assert.True(t, candles[0].Empty)
+		assert.Equal(t, candle, candles[1])
+		assert.True(t, candles[2].Empty)
+		assert.True(t, candles[2].Start.Equal(time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("omits_empty_buckets_by_default", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		mockRepo.On("GetCandles", ctx, mock.AnythingOfType("domain.CandleQuery")).Return([]domain.Candle{}, nil)
+
+		candles, appErr := priceService.GetCandles(ctx, domain.CandleRequest{Symbol: "BTC", Interval: "5m", From: from, To: to})
+
+		require.Nil(t, appErr)
+		assert.Empty(t, candles)
+	})
+
+	t.Run("failure_invalid_request", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		cases := []domain.CandleRequest{
+			{Symbol: "BTC", Interval: "2h", From: from, To: to},
+			{Symbol: "BTC", Interval: "1h", From: from, To: to, Timezone: "Mars/Olympus"},
+			{Symbol: "BTC", Interval: "1h", From: to, To: from},
+			{Symbol: "BTC", Interval: "1m", From: from.AddDate(-1, 0, 0), To: to},
+		}
+		for _, req := range cases {
+			_, appErr := priceService.GetCandles(ctx, req)
+			require.Error(t, appErr)
+			assert.Equal(t, http.StatusBadRequest, appErr.Code)
+		}
+	})
+}
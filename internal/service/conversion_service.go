@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// conversionPlaces - точность кросс-курса и результата пересчёта.
+const conversionPlaces = 12
+
+type ConversionServiceInterface interface {
+	Convert(ctx context.Context, req domain.ConversionRequest) (domain.Conversion, *apperrors.AppError)
+}
+
+type conversionService struct {
+	prices PriceServiceInterface
+	logger logger.Logger
+	now    func() time.Time
+}
+
+func NewConversionService(prices PriceServiceInterface, logger logger.Logger) ConversionServiceInterface {
+	return &conversionService{prices: prices, logger: logger, now: time.Now}
+}
+
+// Convert пересчитывает сумму через USD: берёт ближайшие к моменту цены обеих монет
+// и делит одну на другую. Вся арифметика - в decimal, округление только в конце.
+func (s *conversionService) Convert(ctx context.Context, req domain.ConversionRequest) (domain.Conversion, *apperrors.AppError) {
+	l := s.logger.With(zap.String("from", req.From), zap.String("to", req.To), zap.String("layer", "conversion_service"))
+	l.Info("Converting amount")
+
+	req.From = strings.ToUpper(strings.TrimSpace(req.From))
+	req.To = strings.ToUpper(strings.TrimSpace(req.To))
+	if req.From == "" || req.To == "" {
+		return domain.Conversion{}, apperrors.NewBadRequest("fields 'from' and 'to' are required", nil)
+	}
+	if !req.Amount.IsPositive() {
+		return domain.Conversion{}, apperrors.NewBadRequest("amount must be positive", nil)
+	}
+	if req.Timestamp.IsZero() {
+		req.Timestamp = s.now()
+	}
+
+	from, appErr := s.usdSample(ctx, req.From, req.Timestamp)
+	if appErr != nil {
+		return domain.Conversion{}, appErr
+	}
+	to, appErr := s.usdSample(ctx, req.To, req.Timestamp)
+	if appErr != nil {
+		return domain.Conversion{}, appErr
+	}
+	if to.Price.IsZero() {
+		l.Warn("target currency has zero price", zap.Time("sample", to.Timestamp))
+		return domain.Conversion{}, apperrors.New(http.StatusUnprocessableEntity, "price of '"+req.To+"' is zero, cannot convert", nil)
+	}
+
+	return domain.Conversion{
+		From:        from,
+		To:          to,
+		Amount:      req.Amount,
+		Result:      req.Amount.Mul(from.Price).DivRound(to.Price, conversionPlaces),
+		Rate:        from.Price.DivRound(to.Price, conversionPlaces),
+		RequestedAt: req.Timestamp,
+	}, nil
+}
+
+func (s *conversionService) usdSample(ctx context.Context, symbol string, at time.Time) (domain.PriceSample, *apperrors.AppError) {
+	price, ts, appErr := s.prices.GetNearestPrice(ctx, symbol, at.Unix())
+	if appErr != nil {
+		return domain.PriceSample{}, appErr
+	}
+	return domain.PriceSample{Symbol: symbol, Price: price, Timestamp: ts}, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository/mocks"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversionService_Convert(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	at := time.Unix(1700000000, 0)
+
+	newService := func(t *testing.T) (*mocks.PriceRepositoryInterface, ConversionServiceInterface) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		return mockRepo, NewConversionService(NewPriceService(mockRepo, nopLogger, config.AppConfig{}), nopLogger)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo, conversions := newService(t)
+		mockRepo.On("GetNearest", ctx, "BTC", at).Return(decimal.RequireFromString("60000.5"), at.Add(-3*time.Second), nil)
+		mockRepo.On("GetNearest", ctx, "ETH", at).Return(decimal.RequireFromString("3000.1"), at.Add(2*time.Second), nil)
+
+		conv, appErr := conversions.Convert(ctx, domain.ConversionRequest{
+			From: "btc", To: " eth", Amount: decimal.RequireFromString("0.5"), Timestamp: at,
+		})
+
+		require.Nil(t, appErr)
+		assert.Equal(t, "BTC", conv.From.Symbol)
+		assert.Equal(t, at.Add(2*time.Second), conv.To.Timestamp)
+		assert.Equal(t, "19.999500016666", conv.Rate.String())
+		assert.Equal(t, "9.999750008333", conv.Result.String())
+	})
+
+	t.Run("defaults_to_now", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		conversions := &conversionService{
+			prices: NewPriceService(mockRepo, nopLogger, config.AppConfig{}),
+			logger: nopLogger,
+			now:    func() time.Time { return at },
+		}
+		mockRepo.On("GetNearest", ctx, "BTC", at).Return(decimal.NewFromInt(2), at, nil)
+		mockRepo.On("GetNearest", ctx, "ETH", at).Return(decimal.NewFromInt(4), at, nil)
+
+		conv, appErr := conversions.Convert(ctx, domain.ConversionRequest{From: "BTC", To: "ETH", Amount: decimal.NewFromInt(3)})
+
+		require.Nil(t, appErr)
+		assert.Equal(t, at, conv.RequestedAt)
+		assert.Equal(t, "1.5", conv.Result.String())
+	})
+
+	t.Run("failure_unknown_currency", func(t *testing.T) {
+		mockRepo, conversions := newService(t)
+		mockRepo.On("GetNearest", ctx, "BTC", at).Return(decimal.Zero, time.Time{}, apperrors.NewNotFound("no price history found for this currency", nil))
+
+		_, appErr := conversions.Convert(ctx, domain.ConversionRequest{From: "BTC", To: "ETH", Amount: decimal.NewFromInt(1), Timestamp: at})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.Code)
+	})
+
+	t.Run("failure_invalid_amount", func(t *testing.T) {
+		_, conversions := newService(t)
+
+		_, appErr := conversions.Convert(ctx, domain.ConversionRequest{From: "BTC", To: "ETH", Timestamp: at})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
@@ -2,8 +2,13 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/internal/repository"
 	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
@@ -12,20 +17,43 @@ import (
 
 type CurrencyServiceInterface interface {
 	AddCurrency(ctx context.Context, symbol string) *apperrors.AppError
-	RemoveCurrency(ctx context.Context, symbol string) *apperrors.AppError
+	ArchiveCurrency(ctx context.Context, symbol string) *apperrors.AppError
+	ResumeCurrency(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError)
+	PurgeCurrency(ctx context.Context, symbol, confirm string) (int64, *apperrors.AppError)
+	GetCurrency(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError)
+	ListStatuses(ctx context.Context, q domain.CurrencyStatusQuery) ([]domain.CurrencyStatus, *apperrors.AppError)
+	UpdateCurrency(ctx context.Context, symbol string, patch domain.CurrencyPatch) (domain.Currency, *apperrors.AppError)
 }
 
+// CollectionStatusSource - сведения сборщика цен о последних ошибках сбора. Его реализует PriceCollector.
+type CollectionStatusSource interface {
+	CollectionErrors() map[string]domain.CollectionError
+}
+
+const (
+	maxNameLength    = 100
+	maxTagLength     = 50
+	maxTags          = 32
+	maxNotesLength   = 2000
+	maxDisplayDigits = 18
+)
+
 type CurrencyService struct {
-	repo   repository.CurrencyRepositoryInterface
-	logger logger.Logger
+	repo       repository.CurrencyRepositoryInterface
+	collection CollectionStatusSource
+	logger     logger.Logger
 }
 
-func NewCurrencyService(repo repository.CurrencyRepositoryInterface, logger logger.Logger) *CurrencyService {
+func NewCurrencyService(repo repository.CurrencyRepositoryInterface, collection CollectionStatusSource, logger logger.Logger) *CurrencyService {
 	return &CurrencyService{
-		repo:   repo,
-		logger: logger,
+		repo:       repo,
+		collection: collection,
+		logger:     logger,
 	}
 }
+
+// AddCurrency начинает отслеживать валюту. Пустые метаданные дозаполняет сборщик цен
+// на следующих тиках (см. PriceCollector.populateMetadata), поэтому запрос не ждёт провайдера.
 func (s *CurrencyService) AddCurrency(ctx context.Context, symbol string) *apperrors.AppError {
 	l := s.logger.With(zap.String("symbol", symbol), zap.String("layer", "service"))
 	l.Info("Adding currency")
@@ -37,14 +65,226 @@ func (s *CurrencyService) AddCurrency(ctx context.Context, symbol string) *apper
 	return s.repo.Add(ctx, normalizedSymbol)
 }
 
-func (s *CurrencyService) RemoveCurrency(ctx context.Context, symbol string) *apperrors.AppError {
+// UpdateCurrency проверяет и нормализует изменение метаданных и применяет его.
+// Теги обрезаются по краям, пустые и повторяющиеся отбрасываются; имена провайдеров приводятся к нижнему регистру.
+func (s *CurrencyService) UpdateCurrency(ctx context.Context, symbol string, patch domain.CurrencyPatch) (domain.Currency, *apperrors.AppError) {
 	l := s.logger.With(zap.String("symbol", symbol), zap.String("layer", "service"))
-	l.Info("Removing currency")
+	l.Info("Updating currency metadata")
+
+	normalizedSymbol := strings.ToUpper(strings.TrimSpace(symbol))
+	if normalizedSymbol == "" {
+		return domain.Currency{}, apperrors.NewBadRequest("currency symbol cannot be empty", nil)
+	}
+	if patch.Name == nil && patch.ProviderIDs == nil && patch.DisplayDecimals == nil &&
+		patch.Enabled == nil && patch.Tags == nil && patch.Notes == nil {
+		return domain.Currency{}, apperrors.NewBadRequest("nothing to update", nil)
+	}
+
+	if patch.Name != nil {
+		name := strings.TrimSpace(*patch.Name)
+		if utf8.RuneCountInString(name) > maxNameLength {
+			return domain.Currency{}, apperrors.NewBadRequest(fmt.Sprintf("name must be at most %d characters", maxNameLength), nil)
+		}
+		patch.Name = &name
+	}
+	if patch.ProviderIDs != nil {
+		ids := make(map[string]string, len(patch.ProviderIDs))
+		for provider, id := range patch.ProviderIDs {
+			provider, id = strings.ToLower(strings.TrimSpace(provider)), strings.TrimSpace(id)
+			if provider == "" || id == "" {
+				return domain.Currency{}, apperrors.NewBadRequest("provider_ids must map non-empty provider names to non-empty IDs", nil)
+			}
+			ids[provider] = id
+		}
+		patch.ProviderIDs = ids
+	}
+	if patch.DisplayDecimals != nil && (*patch.DisplayDecimals < 0 || *patch.DisplayDecimals > maxDisplayDigits) {
+		return domain.Currency{}, apperrors.NewBadRequest(fmt.Sprintf("display_decimals must be between 0 and %d", maxDisplayDigits), nil)
+	}
+	if patch.Tags != nil {
+		tags := make([]string, 0, len(patch.Tags))
+		seen := make(map[string]struct{}, len(patch.Tags))
+		for _, tag := range patch.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			if utf8.RuneCountInString(tag) > maxTagLength {
+				return domain.Currency{}, apperrors.NewBadRequest(fmt.Sprintf("tags must be at most %d characters", maxTagLength), nil)
+			}
+			if _, dup := seen[tag]; dup {
+				continue
+			}
+			seen[tag] = struct{}{}
+			tags = append(tags, tag)
+		}
+		if len(tags) > maxTags {
+			return domain.Currency{}, apperrors.NewBadRequest(fmt.Sprintf("at most %d tags are allowed", maxTags), nil)
+		}
+		patch.Tags = tags
+	}
+	if patch.Notes != nil && utf8.RuneCountInString(*patch.Notes) > maxNotesLength {
+		return domain.Currency{}, apperrors.NewBadRequest(fmt.Sprintf("notes must be at most %d characters", maxNotesLength), nil)
+	}
+
+	return s.repo.Update(ctx, normalizedSymbol, patch)
+}
+
+// ArchiveCurrency прекращает отслеживание валюты; история остаётся доступной.
+func (s *CurrencyService) ArchiveCurrency(ctx context.Context, symbol string) *apperrors.AppError {
+	l := s.logger.With(zap.String("symbol", symbol), zap.String("layer", "service"))
+	l.Info("Archiving currency")
 
 	normalizedSymbol := strings.ToUpper(strings.TrimSpace(symbol))
 	if normalizedSymbol == "" {
 		return apperrors.NewBadRequest("currency symbol cannot be empty", nil)
 	}
 
-	return s.repo.Remove(ctx, normalizedSymbol)
+	return s.repo.Archive(ctx, normalizedSymbol)
+}
+
+// ResumeCurrency возобновляет отслеживание архивной валюты с сохранённой историей.
+func (s *CurrencyService) ResumeCurrency(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError) {
+	l := s.logger.With(zap.String("symbol", symbol), zap.String("layer", "service"))
+	l.Info("Resuming currency")
+
+	normalizedSymbol := strings.ToUpper(strings.TrimSpace(symbol))
+	if normalizedSymbol == "" {
+		return domain.Currency{}, apperrors.NewBadRequest("currency symbol cannot be empty", nil)
+	}
+
+	return s.repo.Resume(ctx, normalizedSymbol)
+}
+
+// PurgeCurrency безвозвратно удаляет архивную валюту и её историю. confirm должен
+// повторять символ валюты - защита от случайного вызова.
+func (s *CurrencyService) PurgeCurrency(ctx context.Context, symbol, confirm string) (int64, *apperrors.AppError) {
+	l := s.logger.With(zap.String("symbol", symbol), zap.String("layer", "service"))
+	l.Info("Purging currency")
+
+	normalizedSymbol := strings.ToUpper(strings.TrimSpace(symbol))
+	if normalizedSymbol == "" {
+		return 0, apperrors.NewBadRequest("currency symbol cannot be empty", nil)
+	}
+	if strings.ToUpper(strings.TrimSpace(confirm)) != normalizedSymbol {
+		return 0, apperrors.NewBadRequest("field 'confirm' must repeat the currency symbol", nil)
+	}
+
+	return s.repo.Purge(ctx, normalizedSymbol)
+}
+
+func (s *CurrencyService) GetCurrency(ctx context.Context, symbol string) (domain.Currency, *apperrors.AppError) {
+	l := s.logger.With(zap.String("symbol", symbol), zap.String("layer", "service"))
+	l.Info("Getting currency")
+
+	normalizedSymbol := strings.ToUpper(strings.TrimSpace(symbol))
+	if normalizedSymbol == "" {
+		return domain.Currency{}, apperrors.NewBadRequest("currency symbol cannot be empty", nil)
+	}
+
+	return s.repo.Get(ctx, normalizedSymbol)
+}
+
+// ListStatuses возвращает валюты с состоянием сбора: привязку к провайдеру, границы
+// истории (и её объём, если он запрошен) и последнюю ошибку сбора, затем фильтрует и сортирует список.
+// При равенстве поля сортировки порядок - по символу.
+func (s *CurrencyService) ListStatuses(ctx context.Context, q domain.CurrencyStatusQuery) ([]domain.CurrencyStatus, *apperrors.AppError) {
+	s.logger.With(zap.String("layer", "service")).Info("Listing currency statuses")
+
+	if q.Sort == "" {
+		q.Sort = domain.CurrencyStatusSortSymbol
+	}
+	less, ok := currencyStatusLess[q.Sort]
+	if !ok {
+		return nil, apperrors.NewBadRequest("sort must be one of 'symbol', 'created_at', 'first_sample', 'last_sample', 'sample_count'", nil)
+	}
+	switch q.Order {
+	case "":
+		q.Order = domain.SortAsc
+	case domain.SortAsc, domain.SortDesc:
+	default:
+		return nil, apperrors.NewBadRequest("order must be 'asc' or 'desc'", nil)
+	}
+	wanted := make(map[string]struct{}, len(q.Symbols))
+	for _, symbol := range q.Symbols {
+		wanted[strings.ToUpper(strings.TrimSpace(symbol))] = struct{}{}
+	}
+
+	// Сортировка по числу сэмплов без самих чисел невозможна, поэтому она включает их подсчёт.
+	statuses, appErr := s.repo.ListStatuses(ctx, q.WithCounts || q.Sort == domain.CurrencyStatusSortSampleCount)
+	if appErr != nil {
+		return nil, appErr
+	}
+	var errs map[string]domain.CollectionError
+	if s.collection != nil {
+		errs = s.collection.CollectionErrors()
+	}
+
+	result := make([]domain.CurrencyStatus, 0, len(statuses))
+	for _, st := range statuses {
+		if _, ok := wanted[st.Symbol]; len(wanted) > 0 && !ok {
+			continue
+		}
+		st.Provider = domain.ProviderCoinGecko
+		st.ProviderID, st.Mapped = providerID(st.Currency)
+		if e, ok := errs[st.Symbol]; ok {
+			st.LastError = &e
+		}
+		if q.Mapped != nil && st.Mapped != *q.Mapped {
+			continue
+		}
+		if q.Failing != nil && (st.LastError != nil) != *q.Failing {
+			continue
+		}
+		if q.Archived != nil && (st.ArchivedAt != nil) != *q.Archived {
+			continue
+		}
+		result = append(result, st)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if q.Order == domain.SortDesc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		// При равенстве порядок по символу следует направлению сортировки.
+		return a.Symbol < b.Symbol
+	})
+	return result, nil
+}
+
+// currencyStatusLess - сравнения для сортировки списка валют. Валюты без истории
+// считаются самыми ранними по first_sample и last_sample.
+var currencyStatusLess = map[domain.CurrencyStatusSort]func(a, b domain.CurrencyStatus) bool{
+	domain.CurrencyStatusSortSymbol:    func(a, b domain.CurrencyStatus) bool { return a.Symbol < b.Symbol },
+	domain.CurrencyStatusSortCreatedAt: func(a, b domain.CurrencyStatus) bool { return a.CreatedAt.Before(b.CreatedAt) },
+	domain.CurrencyStatusSortFirstSample: func(a, b domain.CurrencyStatus) bool {
+		return timeOrZero(a.FirstSample).Before(timeOrZero(b.FirstSample))
+	},
+	domain.CurrencyStatusSortLastSample: func(a, b domain.CurrencyStatus) bool {
+		return timeOrZero(a.LastSample).Before(timeOrZero(b.LastSample))
+	},
+	domain.CurrencyStatusSortSampleCount: func(a, b domain.CurrencyStatus) bool {
+		return intOrZero(a.SampleCount) < intOrZero(b.SampleCount)
+	},
+}
+
+func intOrZero(n *int64) int64 {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
 }
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/internal/repository/mocks"
 	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -23,7 +27,7 @@ func TestCurrencyService_AddCurrency(t *testing.T) {
 
 		mockRepo.On("Add", ctx, "BTC").Return(nil)
 
-		currencyService := NewCurrencyService(mockRepo, nopLogger)
+		currencyService := NewCurrencyService(mockRepo, nil, nopLogger)
 
 		appErr := currencyService.AddCurrency(ctx, "  btc  ")
 
@@ -33,7 +37,7 @@ func TestCurrencyService_AddCurrency(t *testing.T) {
 
 	t.Run("failure_empty_symbol", func(t *testing.T) {
 		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
-		currencyService := NewCurrencyService(mockRepo, nopLogger)
+		currencyService := NewCurrencyService(mockRepo, nil, nopLogger)
 
 		appErr := currencyService.AddCurrency(ctx, "   ")
 
@@ -49,7 +53,7 @@ func TestCurrencyService_AddCurrency(t *testing.T) {
 
 		mockRepo.On("Add", ctx, "ETH").Return(expectedError)
 
-		currencyService := NewCurrencyService(mockRepo, nopLogger)
+		currencyService := NewCurrencyService(mockRepo, nil, nopLogger)
 
 		appErr := currencyService.AddCurrency(ctx, "ETH")
 
@@ -58,30 +62,264 @@ func TestCurrencyService_AddCurrency(t *testing.T) {
 	})
 }
 
-func TestCurrencyService_RemoveCurrency(t *testing.T) {
+func TestCurrencyService_ArchiveCurrency(t *testing.T) {
 	nopLogger := logger.NewNopLogger()
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
 		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
 
-		mockRepo.On("Remove", ctx, "XRP").Return(nil)
+		mockRepo.On("Archive", ctx, "XRP").Return(nil)
 
-		currencyService := NewCurrencyService(mockRepo, nopLogger)
+		currencyService := NewCurrencyService(mockRepo, nil, nopLogger)
 
-		appErr := currencyService.RemoveCurrency(ctx, " xrp ")
+		appErr := currencyService.ArchiveCurrency(ctx, " xrp ")
 
 		assert.Nil(t, appErr)
 	})
 
 	t.Run("failure_empty_symbol", func(t *testing.T) {
 		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
-		currencyService := NewCurrencyService(mockRepo, nopLogger)
+		currencyService := NewCurrencyService(mockRepo, nil, nopLogger)
 
-		appErr := currencyService.RemoveCurrency(ctx, "")
+		appErr := currencyService.ArchiveCurrency(ctx, "")
 
 		require.Error(t, appErr)
 		assert.Equal(t, http.StatusBadRequest, appErr.Code)
-		mockRepo.AssertNotCalled(t, "Remove", ctx, "")
+		mockRepo.AssertNotCalled(t, "Archive", ctx, "")
+	})
+}
+
+func TestCurrencyService_GetCurrency(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+
+		mockRepo.On("Get", ctx, "SOL").Return(domain.Currency{Symbol: "SOL"}, nil)
+
+		currencyService := NewCurrencyService(mockRepo, nil, nopLogger)
+
+		currency, appErr := currencyService.GetCurrency(ctx, " sol")
+
+		assert.Nil(t, appErr)
+		assert.Equal(t, "SOL", currency.Symbol)
+	})
+
+	t.Run("failure_empty_symbol", func(t *testing.T) {
+		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+		currencyService := NewCurrencyService(mockRepo, nil, nopLogger)
+
+		_, appErr := currencyService.GetCurrency(ctx, " ")
+
+		require.Error(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
+
+type stubCollector struct {
+	errs map[string]domain.CollectionError
+}
+
+func (s stubCollector) CollectionErrors() map[string]domain.CollectionError { return s.errs }
+
+func TestCurrencyService_ListStatuses(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	at := func(sec int64) *time.Time { ts := time.Unix(sec, 0); return &ts }
+	count := func(n int64) *int64 { return &n }
+	rows := func() []domain.CurrencyStatus {
+		return []domain.CurrencyStatus{
+			{Currency: domain.Currency{Symbol: "BTC"}, FirstSample: at(100), LastSample: at(500), SampleCount: count(40)},
+			{Currency: domain.Currency{Symbol: "ETH"}, FirstSample: at(200), LastSample: at(900), SampleCount: count(70)},
+			{Currency: domain.Currency{Symbol: "FOO", ArchivedAt: at(1200)}},
+		}
+	}
+	errs := stubCollector{errs: map[string]domain.CollectionError{"FOO": {Message: "no coingecko mapping for symbol", At: time.Unix(1000, 0)}}}
+	symbols := func(statuses []domain.CurrencyStatus) []string {
+		result := make([]string, 0, len(statuses))
+		for _, s := range statuses {
+			result = append(result, s.Symbol)
+		}
+		return result
+	}
+
+	t.Run("provider_mapping_and_errors", func(t *testing.T) {
+		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockRepo.On("ListStatuses", ctx, false).Return(rows(), nil)
+
+		statuses, appErr := NewCurrencyService(mockRepo, errs, nopLogger).ListStatuses(ctx, domain.CurrencyStatusQuery{})
+
+		require.Nil(t, appErr)
+		require.Len(t, statuses, 3)
+		assert.Equal(t, domain.ProviderCoinGecko, statuses[0].Provider)
+		assert.Equal(t, "bitcoin", statuses[0].ProviderID)
+		assert.True(t, statuses[0].Mapped)
+		assert.Nil(t, statuses[0].LastError)
+		assert.False(t, statuses[2].Mapped)
+		assert.Empty(t, statuses[2].ProviderID)
+		require.NotNil(t, statuses[2].LastError)
+		assert.Equal(t, "no coingecko mapping for symbol", statuses[2].LastError.Message)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		yes, no := true, false
+		cases := []struct {
+			name  string
+			query domain.CurrencyStatusQuery
+			want  []string
+		}{
+			{"symbols", domain.CurrencyStatusQuery{Symbols: []string{"eth", "FOO"}}, []string{"ETH", "FOO"}},
+			{"mapped", domain.CurrencyStatusQuery{Mapped: &yes}, []string{"BTC", "ETH"}},
+			{"unmapped", domain.CurrencyStatusQuery{Mapped: &no}, []string{"FOO"}},
+			{"failing", domain.CurrencyStatusQuery{Failing: &yes}, []string{"FOO"}},
+			{"active", domain.CurrencyStatusQuery{Archived: &no}, []string{"BTC", "ETH"}},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+				mockRepo.On("ListStatuses", ctx, false).Return(rows(), nil)
+
+				statuses, appErr := NewCurrencyService(mockRepo, errs, nopLogger).ListStatuses(ctx, tc.query)
+
+				require.Nil(t, appErr)
+				assert.Equal(t, tc.want, symbols(statuses))
+			})
+		}
+	})
+
+	t.Run("sorting", func(t *testing.T) {
+		cases := []struct {
+			sort  domain.CurrencyStatusSort
+			order domain.SortOrder
+			want  []string
+		}{
+			{domain.CurrencyStatusSortSampleCount, domain.SortDesc, []string{"ETH", "BTC", "FOO"}},
+			{domain.CurrencyStatusSortLastSample, domain.SortAsc, []string{"FOO", "BTC", "ETH"}},
+			// created_at у всех одинаковый: при равенстве символы идут в направлении сортировки.
+			{domain.CurrencyStatusSortCreatedAt, domain.SortAsc, []string{"BTC", "ETH", "FOO"}},
+			{domain.CurrencyStatusSortCreatedAt, domain.SortDesc, []string{"FOO", "ETH", "BTC"}},
+		}
+		for _, tc := range cases {
+			t.Run(string(tc.sort)+"_"+string(tc.order), func(t *testing.T) {
+				mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+				// Сортировка по числу сэмплов сама включает их подсчёт.
+				mockRepo.On("ListStatuses", ctx, tc.sort == domain.CurrencyStatusSortSampleCount).Return(rows(), nil)
+
+				statuses, appErr := NewCurrencyService(mockRepo, nil, nopLogger).ListStatuses(ctx, domain.CurrencyStatusQuery{Sort: tc.sort, Order: tc.order})
+
+				require.Nil(t, appErr)
+				assert.Equal(t, tc.want, symbols(statuses))
+			})
+		}
+	})
+
+	t.Run("counts_on_request", func(t *testing.T) {
+		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockRepo.On("ListStatuses", ctx, true).Return(rows(), nil)
+
+		statuses, appErr := NewCurrencyService(mockRepo, nil, nopLogger).ListStatuses(ctx, domain.CurrencyStatusQuery{WithCounts: true})
+
+		require.Nil(t, appErr)
+		require.NotNil(t, statuses[0].SampleCount)
+		assert.Equal(t, int64(40), *statuses[0].SampleCount)
+	})
+
+	t.Run("failure_invalid_sort", func(t *testing.T) {
+		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+
+		_, appErr := NewCurrencyService(mockRepo, nil, nopLogger).ListStatuses(ctx, domain.CurrencyStatusQuery{Sort: "price"})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
+
+func TestCurrencyService_UpdateCurrency(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	str := func(v string) *string { return &v }
+	num := func(v int) *int { return &v }
+
+	t.Run("normalizes_patch", func(t *testing.T) {
+		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+
+		want := domain.CurrencyPatch{
+			Name:        str("Bitcoin"),
+			ProviderIDs: map[string]string{"coingecko": "bitcoin"},
+			Tags:        []string{"layer-1", "pow"},
+		}
+		mockRepo.On("Update", ctx, "BTC", want).Return(domain.Currency{Symbol: "BTC", Name: "Bitcoin"}, nil)
+
+		currency, appErr := NewCurrencyService(mockRepo, nil, nopLogger).UpdateCurrency(ctx, " btc", domain.CurrencyPatch{
+			Name:        str("  Bitcoin "),
+			ProviderIDs: map[string]string{" CoinGecko": "bitcoin "},
+			Tags:        []string{" layer-1", "pow", "", "layer-1"},
+		})
+
+		require.Nil(t, appErr)
+		assert.Equal(t, "Bitcoin", currency.Name)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		cases := map[string]domain.CurrencyPatch{
+			"empty_patch":        {},
+			"decimals_too_large": {DisplayDecimals: num(19)},
+			"negative_decimals":  {DisplayDecimals: num(-1)},
+			"empty_provider_id":  {ProviderIDs: map[string]string{"coingecko": " "}},
+			"long_name":          {Name: str(strings.Repeat("x", 101))},
+			"long_tag":           {Tags: []string{strings.Repeat("x", 51)}},
+		}
+		for name, patch := range cases {
+			t.Run(name, func(t *testing.T) {
+				mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+
+				_, appErr := NewCurrencyService(mockRepo, nil, nopLogger).UpdateCurrency(ctx, "BTC", patch)
+
+				require.NotNil(t, appErr)
+				assert.Equal(t, http.StatusBadRequest, appErr.Code)
+			})
+		}
+	})
+}
+
+func TestCurrencyService_ResumeCurrency(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+	mockRepo.On("Resume", ctx, "BTC").Return(domain.Currency{Symbol: "BTC", Enabled: true}, nil)
+
+	currency, appErr := NewCurrencyService(mockRepo, nil, nopLogger).ResumeCurrency(ctx, "btc ")
+
+	require.Nil(t, appErr)
+	assert.Nil(t, currency.ArchivedAt)
+}
+
+func TestCurrencyService_PurgeCurrency(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockRepo.On("Purge", ctx, "BTC").Return(int64(1440), nil)
+
+		deleted, appErr := NewCurrencyService(mockRepo, nil, nopLogger).PurgeCurrency(ctx, "btc", " BTC")
+
+		require.Nil(t, appErr)
+		assert.Equal(t, int64(1440), deleted)
+	})
+
+	t.Run("failure_confirmation_mismatch", func(t *testing.T) {
+		for _, confirm := range []string{"", "ETH"} {
+			mockRepo := mocks.NewCurrencyRepositoryInterface(t)
+
+			_, appErr := NewCurrencyService(mockRepo, nil, nopLogger).PurgeCurrency(ctx, "BTC", confirm)
+
+			require.NotNil(t, appErr)
+			assert.Equal(t, http.StatusBadRequest, appErr.Code)
+			mockRepo.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+		}
 	})
 }
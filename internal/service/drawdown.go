@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/timeseries"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"go.uber.org/zap"
+)
+
+const defaultDrawdownWindow = 30 * 24 * time.Hour
+
+// Drawdown считает максимальную просадку и ряд просадок в окне [From, To] (по умолчанию -
+// последние 30 дней). Без Interval используются все сэмплы окна, но не больше maxSeriesPoints.
+func (s *analyticsService) Drawdown(ctx context.Context, req domain.DrawdownRequest) (domain.DrawdownReport, *apperrors.AppError) {
+	l := s.logger.With(zap.String("symbol", req.Symbol), zap.String("interval", req.Interval), zap.String("layer", "analytics_service"))
+	l.Info("Computing drawdown")
+
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if req.Symbol == "" {
+		return domain.DrawdownReport{}, apperrors.NewBadRequest("currency symbol cannot be empty", nil)
+	}
+	if req.To.IsZero() {
+		req.To = s.now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-defaultDrawdownWindow)
+	}
+	if !req.From.Before(req.To) {
+		return domain.DrawdownReport{}, apperrors.NewBadRequest("'from' must be before 'to'", nil)
+	}
+
+	var (
+		points []timeseries.Point
+		appErr *apperrors.AppError
+	)
+	if req.Interval == "" {
+		points, appErr = s.samplePoints(ctx, req)
+	} else {
+		points, appErr = s.closePoints(ctx, req)
+	}
+	if appErr != nil {
+		return domain.DrawdownReport{}, appErr
+	}
+
+	dd := timeseries.Drawdown(points)
+	report := domain.DrawdownReport{
+		Symbol:      req.Symbol,
+		From:        req.From,
+		To:          req.To,
+		MaxDrawdown: dd.Max,
+		Peak:        pointSample(req.Symbol, dd.Peak),
+		Trough:      pointSample(req.Symbol, dd.Trough),
+		Recovery:    pointSample(req.Symbol, dd.Recovery),
+		Underwater:  make([]domain.UnderwaterPoint, 0, len(dd.Underwater)),
+	}
+	for _, u := range dd.Underwater {
+		report.Underwater = append(report.Underwater, domain.UnderwaterPoint{Time: u.Time, Price: u.Value, Drawdown: u.Drawdown})
+	}
+	return report, nil
+}
+
+// samplePoints читает сэмплы окна; если их больше maxSeriesPoints, просит задать интервал.
+func (s *analyticsService) samplePoints(ctx context.Context, req domain.DrawdownRequest) ([]timeseries.Point, *apperrors.AppError) {
+	samples, appErr := s.repo.GetRange(ctx, domain.PriceRangeQuery{
+		Symbol: req.Symbol,
+		From:   req.From,
+		To:     req.To,
+		Order:  domain.SortAsc,
+		Limit:  maxSeriesPoints + 1,
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+	if len(samples) > maxSeriesPoints {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("window holds more than %d samples, set 'interval' to use candle closes", maxSeriesPoints), nil)
+	}
+	points := make([]timeseries.Point, 0, len(samples))
+	for _, sample := range samples {
+		points = append(points, timeseries.Point{Time: sample.Timestamp, Value: sample.Price})
+	}
+	return points, nil
+}
+
+// closePoints возвращает цены закрытия непустых свечей окна.
+func (s *analyticsService) closePoints(ctx context.Context, req domain.DrawdownRequest) ([]timeseries.Point, *apperrors.AppError) {
+	query, appErr := newCandleQuery(domain.CandleRequest{Symbol: req.Symbol, Interval: req.Interval, From: req.From, To: req.To}, s.now())
+	if appErr != nil {
+		return nil, appErr
+	}
+	candles, appErr := s.repo.GetCandles(ctx, query)
+	if appErr != nil {
+		return nil, appErr
+	}
+	points := make([]timeseries.Point, 0, len(candles))
+	for _, c := range candles {
+		if c.Empty {
+			continue
+		}
+		points = append(points, timeseries.Point{Time: c.Start, Value: c.Close})
+	}
+	return points, nil
+}
+
+func pointSample(symbol string, p *timeseries.Point) *domain.PriceSample {
+	if p == nil {
+		return nil
+	}
+	return &domain.PriceSample{Symbol: symbol, Price: p.Value, Timestamp: p.Time}
+}
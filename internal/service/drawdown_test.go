@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository/mocks"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsService_Drawdown(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	sample := func(offset time.Duration, price int64) domain.PriceSample {
+		return domain.PriceSample{Symbol: "BTC", Price: decimal.NewFromInt(price), Timestamp: from.Add(offset)}
+	}
+
+	t.Run("raw_samples", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		query := domain.PriceRangeQuery{Symbol: "BTC", From: from, To: to, Order: domain.SortAsc, Limit: maxSeriesPoints + 1}
+		mockRepo.On("GetRange", ctx, query).Return([]domain.PriceSample{
+			sample(time.Hour, 100),
+			sample(2*time.Hour, 80),
+			sample(3*time.Hour, 120),
+			sample(4*time.Hour, 90),
+		}, nil)
+
+		report, appErr := analytics.Drawdown(ctx, domain.DrawdownRequest{Symbol: "btc", From: from, To: to})
+
+		require.Nil(t, appErr)
+		assert.Equal(t, "BTC", report.Symbol)
+		assert.True(t, decimal.RequireFromString("-0.25").Equal(report.MaxDrawdown), report.MaxDrawdown.String())
+		require.NotNil(t, report.Peak)
+		assert.Equal(t, from.Add(3*time.Hour), report.Peak.Timestamp)
+		require.NotNil(t, report.Trough)
+		assert.Equal(t, from.Add(4*time.Hour), report.Trough.Timestamp)
+		assert.Nil(t, report.Recovery)
+		require.Len(t, report.Underwater, 4)
+		assert.True(t, decimal.RequireFromString("-0.2").Equal(report.Underwater[1].Drawdown))
+		assert.True(t, report.Underwater[2].Drawdown.IsZero())
+	})
+
+	t.Run("candle_closes", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		closeAt := func(offset time.Duration, price int64) domain.Candle {
+			p := decimal.NewFromInt(price)
+			return domain.Candle{Start: from.Add(offset), Open: p, High: p, Low: p, Close: p, Count: 1}
+		}
+		query := domain.CandleQuery{Symbol: "BTC", Interval: time.Hour, From: from, To: to, Location: time.UTC}
+		mockRepo.On("GetCandles", ctx, query).Return([]domain.Candle{
+			closeAt(0, 100),
+			closeAt(time.Hour, 50),
+			closeAt(2*time.Hour, 100),
+		}, nil)
+
+		report, appErr := analytics.Drawdown(ctx, domain.DrawdownRequest{Symbol: "BTC", From: from, To: to, Interval: "1h"})
+
+		require.Nil(t, appErr)
+		assert.True(t, decimal.RequireFromString("-0.5").Equal(report.MaxDrawdown))
+		require.NotNil(t, report.Recovery)
+		assert.Equal(t, from.Add(2*time.Hour), report.Recovery.Timestamp)
+	})
+
+	t.Run("empty_window", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		query := domain.PriceRangeQuery{Symbol: "BTC", From: from, To: to, Order: domain.SortAsc, Limit: maxSeriesPoints + 1}
+		mockRepo.On("GetRange", ctx, query).Return([]domain.PriceSample{}, nil)
+
+		report, appErr := analytics.Drawdown(ctx, domain.DrawdownRequest{Symbol: "BTC", From: from, To: to})
+
+		require.Nil(t, appErr)
+		assert.True(t, report.MaxDrawdown.IsZero())
+		assert.Nil(t, report.Peak)
+		assert.Empty(t, report.Underwater)
+	})
+
+	t.Run("invalid_window", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		_, appErr := analytics.Drawdown(ctx, domain.DrawdownRequest{Symbol: "BTC", From: to, To: from})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/export"
+	"github.com/adal4ik/crypto-service/internal/repository"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type ExportServiceInterface interface {
+	Export(ctx context.Context, req domain.ExportRequest, w io.Writer) *apperrors.AppError
+}
+
+type exportService struct {
+	prices     repository.PriceRepositoryInterface
+	currencies repository.CurrencyRepositoryInterface
+	logger     logger.Logger
+}
+
+func NewExportService(prices repository.PriceRepositoryInterface, currencies repository.CurrencyRepositoryInterface, logger logger.Logger) ExportServiceInterface {
+	return &exportService{prices: prices, currencies: currencies, logger: logger}
+}
+
+// Export проверяет запрос и пишет выгрузку в w по мере чтения из БД. До первой записи в w
+// возвращаются только ошибки валидации и БД; ошибка записи означает, что клиент отключился.
+func (s *exportService) Export(ctx context.Context, req domain.ExportRequest, w io.Writer) *apperrors.AppError {
+	l := s.logger.With(zap.Strings("symbols", req.Symbols), zap.String("format", string(req.Format)), zap.String("layer", "export_service"))
+	l.Info("Exporting price history")
+
+	if req.Format == "" {
+		req.Format = domain.ExportFormatCSV
+	}
+	if req.Format != domain.ExportFormatCSV && req.Format != domain.ExportFormatNDJSON {
+		return apperrors.NewBadRequest(fmt.Sprintf("unknown format '%s', expected csv or ndjson", req.Format), nil)
+	}
+	if req.Layout == "" {
+		req.Layout = domain.ExportLayoutLong
+	}
+	if req.Layout != domain.ExportLayoutLong && req.Layout != domain.ExportLayoutWide {
+		return apperrors.NewBadRequest(fmt.Sprintf("unknown layout '%s', expected long or wide", req.Layout), nil)
+	}
+	if !req.From.IsZero() && !req.To.IsZero() && req.From.After(req.To) {
+		return apperrors.NewBadRequest("'from' must not be after 'to'", nil)
+	}
+
+	symbols, appErr := s.resolveSymbols(ctx, req.Symbols)
+	if appErr != nil {
+		return appErr
+	}
+
+	writer, err := export.NewWriter(w, req.Format, req.Layout, symbols)
+	if err != nil {
+		return apperrors.NewInternalServerError("failed to start export", err)
+	}
+	rows := 0
+	appErr = s.prices.StreamRange(ctx, domain.ExportQuery{Symbols: symbols, From: req.From, To: req.To}, func(sample domain.PriceSample) error {
+		rows++
+		return writer.Write(sample)
+	})
+	if appErr != nil {
+		return appErr
+	}
+	if err := writer.Close(); err != nil {
+		return apperrors.NewInternalServerError("failed to write export", err)
+	}
+
+	l.Info("Export finished", zap.Int("rows", rows))
+	return nil
+}
+
+// resolveSymbols нормализует символы и проверяет, что все они отслеживаются;
+// пустой список - все отслеживаемые валюты по алфавиту.
+func (s *exportService) resolveSymbols(ctx context.Context, requested []string) ([]string, *apperrors.AppError) {
+	tracked, appErr := s.currencies.GetAll(ctx)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if len(requested) == 0 {
+		slices.Sort(tracked)
+		return tracked, nil
+	}
+
+	symbols := make([]string, 0, len(requested))
+	var unknown []string
+	for _, symbol := range requested {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || slices.Contains(symbols, symbol) {
+			continue
+		}
+		if !slices.Contains(tracked, symbol) {
+			unknown = append(unknown, symbol)
+			continue
+		}
+		symbols = append(symbols, symbol)
+	}
+	if len(unknown) > 0 {
+		return nil, apperrors.NewNotFound("currency is not tracked", nil).WithDetails(map[string][]string{"symbols": unknown})
+	}
+	if len(symbols) == 0 {
+		return nil, apperrors.NewBadRequest("parameter 'symbols' contains no symbols", nil)
+	}
+	return symbols, nil
+}
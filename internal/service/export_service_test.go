@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository/mocks"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// streamSamples подставляет в мок StreamRange отдачу samples через переданный колбэк.
+func streamSamples(samples ...domain.PriceSample) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		fn := args.Get(2).(func(domain.PriceSample) error)
+		for _, s := range samples {
+			if fn(s) != nil {
+				return
+			}
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestExportService_Export(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("all_tracked_wide_csv", func(t *testing.T) {
+		prices := mocks.NewPriceRepositoryInterface(t)
+		currencies := mocks.NewCurrencyRepositoryInterface(t)
+		exporter := NewExportService(prices, currencies, nopLogger)
+
+		currencies.On("GetAll", ctx).Return([]string{"ETH", "BTC"}, nil)
+		query := domain.ExportQuery{Symbols: []string{"BTC", "ETH"}, From: ts}
+		prices.On("StreamRange", ctx, query, mock.Anything).Run(streamSamples(
+			domain.PriceSample{Symbol: "BTC", Price: decimal.NewFromInt(42000), Timestamp: ts},
+			domain.PriceSample{Symbol: "ETH", Price: decimal.NewFromInt(2200), Timestamp: ts},
+		)).Return(nil)
+
+		var buf bytes.Buffer
+		appErr := exporter.Export(ctx, domain.ExportRequest{From: ts, Layout: domain.ExportLayoutWide}, &buf)
+
+		require.Nil(t, appErr)
+		assert.Equal(t, "timestamp,BTC,ETH\n2024-01-01T00:00:00Z,42000,2200\n", buf.String())
+	})
+
+	t.Run("unknown_symbol", func(t *testing.T) {
+		prices := mocks.NewPriceRepositoryInterface(t)
+		currencies := mocks.NewCurrencyRepositoryInterface(t)
+		exporter := NewExportService(prices, currencies, nopLogger)
+
+		currencies.On("GetAll", ctx).Return([]string{"BTC"}, nil)
+
+		var buf bytes.Buffer
+		appErr := exporter.Export(ctx, domain.ExportRequest{Symbols: []string{"btc", "doge"}}, &buf)
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.Code)
+		assert.Equal(t, map[string][]string{"symbols": {"DOGE"}}, appErr.Details)
+		assert.Zero(t, buf.Len())
+	})
+
+	t.Run("invalid_format", func(t *testing.T) {
+		exporter := NewExportService(mocks.NewPriceRepositoryInterface(t), mocks.NewCurrencyRepositoryInterface(t), nopLogger)
+
+		appErr := exporter.Export(ctx, domain.ExportRequest{Format: "xlsx"}, &bytes.Buffer{})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+
+	t.Run("client_gone", func(t *testing.T) {
+		prices := mocks.NewPriceRepositoryInterface(t)
+		currencies := mocks.NewCurrencyRepositoryInterface(t)
+		exporter := NewExportService(prices, currencies, nopLogger)
+
+		currencies.On("GetAll", ctx).Return([]string{"BTC"}, nil)
+		query := domain.ExportQuery{Symbols: []string{"BTC"}}
+		prices.On("StreamRange", ctx, query, mock.Anything).Run(streamSamples(
+			domain.PriceSample{Symbol: "BTC", Price: decimal.NewFromInt(42000), Timestamp: ts},
+		)).Return(nil)
+
+		appErr := exporter.Export(ctx, domain.ExportRequest{Symbols: []string{"BTC"}, Format: domain.ExportFormatNDJSON}, failingWriter{})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusInternalServerError, appErr.Code)
+	})
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository"
+)
+
+type HealthServiceInterface interface {
+	Status() domain.HealthStatus
+}
+
+type healthService struct {
+	checker repository.HealthCheckerInterface
+}
+
+func NewHealthService(checker repository.HealthCheckerInterface) HealthServiceInterface {
+	return &healthService{checker: checker}
+}
+
+func (s *healthService) Status() domain.HealthStatus {
+	return s.checker.Status()
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/importer"
+	"github.com/adal4ik/crypto-service/internal/repository"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxReportedLines - сколько номеров строк попадает в списки дубликатов и отказов отчёта.
+const maxReportedLines = 1000
+
+type ImportServiceInterface interface {
+	Import(ctx context.Context, format domain.ExportFormat, r io.Reader) (domain.ImportReport, *apperrors.AppError)
+}
+
+type importService struct {
+	prices     repository.PriceRepositoryInterface
+	currencies repository.CurrencyRepositoryInterface
+	logger     logger.Logger
+	batchSize  int
+}
+
+func NewImportService(prices repository.PriceRepositoryInterface, currencies repository.CurrencyRepositoryInterface, logger logger.Logger, cfg config.AppConfig) ImportServiceInterface {
+	batchSize := cfg.ImportBatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	return &importService{prices: prices, currencies: currencies, logger: logger, batchSize: batchSize}
+}
+
+// Import читает файл построчно и пишет прошедшие проверку строки пакетами по batchSize,
+// каждый пакет - отдельной транзакцией. Ошибка на середине файла не отменяет уже записанные
+// пакеты: их итог возвращается в Details ошибки.
+func (s *importService) Import(ctx context.Context, format domain.ExportFormat, r io.Reader) (domain.ImportReport, *apperrors.AppError) {
+	l := s.logger.With(zap.String("format", string(format)), zap.String("layer", "import_service"))
+	l.Info("Importing price history")
+
+	if format == "" {
+		format = domain.ExportFormatCSV
+	}
+	reader, err := importer.NewReader(r, format)
+	if err != nil {
+		return domain.ImportReport{}, apperrors.NewBadRequest(err.Error(), err)
+	}
+	tracked, appErr := s.currencies.GetAll(ctx)
+	if appErr != nil {
+		return domain.ImportReport{}, appErr
+	}
+	known := make(map[string]bool, len(tracked))
+	for _, symbol := range tracked {
+		known[symbol] = true
+	}
+
+	var report domain.ImportReport
+	batch := make([]domain.ImportRow, 0, s.batchSize)
+	flush := func() *apperrors.AppError {
+		if len(batch) == 0 {
+			return nil
+		}
+		inserted, appErr := s.prices.InsertBatch(ctx, batch)
+		if appErr != nil {
+			return appErr
+		}
+		for i, ok := range inserted {
+			if ok {
+				report.Accepted++
+			} else {
+				addDuplicate(&report, batch[i].Line)
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if appErr := flush(); appErr != nil {
+				return report, partialImportError(appErr, report)
+			}
+			return report, partialImportError(apperrors.NewBadRequest(fmt.Sprintf("failed to read file: %v", err), err), report)
+		}
+
+		report.Total++
+		switch {
+		case record.Err != nil:
+			addRejection(&report, record.Row.Line, record.Err.Error())
+		case !known[record.Row.Symbol]:
+			addRejection(&report, record.Row.Line, fmt.Sprintf("currency %s is not tracked", record.Row.Symbol))
+		default:
+			batch = append(batch, record.Row)
+			if len(batch) == s.batchSize {
+				if appErr := flush(); appErr != nil {
+					return report, partialImportError(appErr, report)
+				}
+			}
+		}
+	}
+	if appErr := flush(); appErr != nil {
+		return report, partialImportError(appErr, report)
+	}
+
+	l.Info("Import finished", zap.Int("total", report.Total), zap.Int("accepted", report.Accepted),
+		zap.Int("duplicate", report.Duplicate), zap.Int("rejected", report.Rejected))
+	return report, nil
+}
+
+func addDuplicate(report *domain.ImportReport, line int) {
+	report.Duplicate++
+	if len(report.Duplicates) < maxReportedLines {
+		report.Duplicates = append(report.Duplicates, line)
+	} else {
+		report.Truncated = true
+	}
+}
+
+func addRejection(report *domain.ImportReport, line int, reason string) {
+	report.Rejected++
+	if len(report.Rejections) < maxReportedLines {
+		report.Rejections = append(report.Rejections, domain.ImportRejection{Line: line, Reason: reason})
+	} else {
+		report.Truncated = true
+	}
+}
+
+// partialImportError дополняет ошибку итогом уже записанных пакетов.
+func partialImportError(appErr *apperrors.AppError, report domain.ImportReport) *apperrors.AppError {
+	return appErr.WithDetails(map[string]int{
+		"rows_read": report.Total,
+		"accepted":  report.Accepted,
+		"duplicate": report.Duplicate,
+		"rejected":  report.Rejected,
+	})
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository/mocks"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportService_Import(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	ts := time.Unix(1704067200, 0).UTC()
+	row := func(line int, symbol string, price int64) domain.ImportRow {
+		return domain.ImportRow{Line: line, Symbol: symbol, Price: decimal.NewFromInt(price), Timestamp: ts}
+	}
+
+	t.Run("batches_and_report", func(t *testing.T) {
+		prices := mocks.NewPriceRepositoryInterface(t)
+		currencies := mocks.NewCurrencyRepositoryInterface(t)
+		importer := NewImportService(prices, currencies, nopLogger, config.AppConfig{ImportBatchSize: 2})
+
+		input := "timestamp,symbol,price\n" +
+			"1704067200,BTC,42000\n" +
+			"1704067200,ETH,2200\n" +
+			"1704067200,DOGE,1\n" +
+			"1704067200,BTC,0\n" +
+			"1704067200,BTC,42000\n"
+		currencies.On("GetAll", ctx).Return([]string{"BTC", "ETH"}, nil)
+		prices.On("InsertBatch", ctx, []domain.ImportRow{row(2, "BTC", 42000), row(3, "ETH", 2200)}).Return([]bool{true, true}, nil).Once()
+		prices.On("InsertBatch", ctx, []domain.ImportRow{row(6, "BTC", 42000)}).Return([]bool{false}, nil).Once()
+
+		report, appErr := importer.Import(ctx, domain.ExportFormatCSV, strings.NewReader(input))
+
+		require.Nil(t, appErr)
+		assert.Equal(t, 5, report.Total)
+		assert.Equal(t, 2, report.Accepted)
+		assert.Equal(t, 1, report.Duplicate)
+		assert.Equal(t, []int{6}, report.Duplicates)
+		assert.Equal(t, 2, report.Rejected)
+		assert.Equal(t, []domain.ImportRejection{
+			{Line: 4, Reason: "currency DOGE is not tracked"},
+			{Line: 5, Reason: "price 0 is not positive"},
+		}, report.Rejections)
+	})
+
+	t.Run("bad_header", func(t *testing.T) {
+		importer := NewImportService(mocks.NewPriceRepositoryInterface(t), mocks.NewCurrencyRepositoryInterface(t), nopLogger, config.AppConfig{})
+
+		_, appErr := importer.Import(ctx, domain.ExportFormatCSV, strings.NewReader("symbol,price\nBTC,1\n"))
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+
+	t.Run("db_error_keeps_progress", func(t *testing.T) {
+		prices := mocks.NewPriceRepositoryInterface(t)
+		currencies := mocks.NewCurrencyRepositoryInterface(t)
+		importer := NewImportService(prices, currencies, nopLogger, config.AppConfig{ImportBatchSize: 1})
+
+		input := `{"timestamp":1704067200,"symbol":"BTC","price":"42000"}` + "\n" +
+			`{"timestamp":1704067200,"symbol":"ETH","price":"2200"}` + "\n"
+		currencies.On("GetAll", ctx).Return([]string{"BTC", "ETH"}, nil)
+		prices.On("InsertBatch", ctx, []domain.ImportRow{row(1, "BTC", 42000)}).Return([]bool{true}, nil).Once()
+		prices.On("InsertBatch", ctx, []domain.ImportRow{row(2, "ETH", 2200)}).Return(nil, apperrors.NewInternalServerError("database error", nil)).Once()
+
+		_, appErr := importer.Import(ctx, domain.ExportFormatNDJSON, strings.NewReader(input))
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusInternalServerError, appErr.Code)
+		assert.Equal(t, 1, appErr.Details.(map[string]int)["accepted"])
+	})
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/indicators"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultIndicatorInterval = "1h"
+	indicatorPlaces          = 8
+)
+
+// Indicators считает технический индикатор по ценам закрытия свечей. Свечи запрашиваются
+// с запасом spec.Lookback() бакетов до начала периода, чтобы первые точки периода уже были
+// прогреты; в ответ попадают только свечи периода. Пустые бакеты берут цену закрытия
+// предыдущей свечи.
+func (s *analyticsService) Indicators(ctx context.Context, req domain.IndicatorRequest) (domain.IndicatorSeries, *apperrors.AppError) {
+	l := s.logger.With(zap.String("symbol", req.Symbol), zap.String("type", req.Type), zap.String("layer", "analytics_service"))
+	l.Info("Computing indicator")
+
+	spec, err := indicators.Spec{
+		Type:   indicators.Type(req.Type),
+		Period: req.Period,
+		Fast:   req.Fast,
+		Slow:   req.Slow,
+		Signal: req.Signal,
+		K:      req.K,
+	}.Normalize()
+	if err != nil {
+		return domain.IndicatorSeries{}, apperrors.NewBadRequest(err.Error(), err)
+	}
+	if req.Interval == "" {
+		req.Interval = defaultIndicatorInterval
+	}
+	query, appErr := newCandleQuery(domain.CandleRequest{
+		Symbol:   req.Symbol,
+		Interval: req.Interval,
+		From:     req.From,
+		To:       req.To,
+	}, s.now())
+	if appErr != nil {
+		return domain.IndicatorSeries{}, appErr
+	}
+	rangeStart := query.From
+	for i := 0; i < spec.Lookback(); i++ {
+		query.From = alignBucket(query.From.Add(-query.Interval), query.Interval, query.Location)
+	}
+
+	candles, appErr := s.repo.GetCandles(ctx, query)
+	if appErr != nil {
+		return domain.IndicatorSeries{}, appErr
+	}
+	candles = fillEmptyCandles(candles, query)
+
+	closes := make([]float64, len(candles))
+	last := math.NaN()
+	for i, c := range candles {
+		if !c.Empty {
+			last = c.Close.InexactFloat64()
+		}
+		closes[i] = last
+	}
+	lines := spec.Compute(closes)
+
+	series := domain.IndicatorSeries{
+		Symbol:   query.Symbol,
+		Type:     string(spec.Type),
+		Interval: req.Interval,
+		Params:   spec.Params(),
+		Lines:    spec.Lines(),
+	}
+	scale := math.Pow(10, indicatorPlaces)
+	for i, c := range candles {
+		if c.Start.Before(rangeStart) {
+			continue
+		}
+		point := domain.IndicatorPoint{Time: c.Start, Values: make([]*float64, len(lines))}
+		for j, line := range lines {
+			if v := line[i]; !math.IsNaN(v) {
+				v = math.Round(v*scale) / scale
+				point.Values[j] = &v
+			}
+		}
+		series.Points = append(series.Points, point)
+	}
+	return series, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adal4ik/crypto-service/internal/domain"
+	"github.com/adal4ik/crypto-service/internal/repository/mocks"
+	"github.com/adal4ik/crypto-service/pkg/logger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsService_Indicators(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	from := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	to := from.Add(3 * time.Hour)
+
+	closeAt := func(start time.Time, price int64) domain.Candle {
+		p := decimal.NewFromInt(price)
+		return domain.Candle{Start: start, Open: p, High: p, Low: p, Close: p, Count: 1}
+	}
+
+	t.Run("sma_with_warmup_and_gap", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		// Период 3 требует двух свечей до начала диапазона; свеча в 09:00 пустая.
+		query := domain.CandleQuery{Symbol: "BTC", Interval: time.Hour, From: from.Add(-2 * time.Hour), To: to, Location: time.UTC}
+		mockRepo.On("GetCandles", ctx, query).Return([]domain.Candle{
+			closeAt(from.Add(-2*time.Hour), 1),
+			closeAt(from, 3),
+			closeAt(from.Add(time.Hour), 5),
+			closeAt(from.Add(2*time.Hour), 7),
+		}, nil)
+
+		series, appErr := analytics.Indicators(ctx, domain.IndicatorRequest{Symbol: "btc", Type: "sma", Period: 3, From: from, To: to})
+
+		require.Nil(t, appErr)
+		assert.Equal(t, "BTC", series.Symbol)
+		assert.Equal(t, "1h", series.Interval)
+		assert.Equal(t, []string{"sma"}, series.Lines)
+		require.Len(t, series.Points, 3)
+		assert.Equal(t, from, series.Points[0].Time)
+		require.NotNil(t, series.Points[0].Values[0])
+		assert.Equal(t, 1.66666667, *series.Points[0].Values[0])
+		assert.Equal(t, 3.0, *series.Points[1].Values[0])
+		assert.Equal(t, 5.0, *series.Points[2].Values[0])
+	})
+
+	t.Run("insufficient_history", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		query := domain.CandleQuery{Symbol: "BTC", Interval: time.Hour, From: from.Add(-2 * time.Hour), To: to, Location: time.UTC}
+		mockRepo.On("GetCandles", ctx, query).Return([]domain.Candle{closeAt(from, 3), closeAt(from.Add(time.Hour), 5)}, nil)
+
+		series, appErr := analytics.Indicators(ctx, domain.IndicatorRequest{Symbol: "BTC", Type: "sma", Period: 3, From: from, To: to})
+
+		require.Nil(t, appErr)
+		require.Len(t, series.Points, 3)
+		assert.Nil(t, series.Points[0].Values[0])
+		assert.Nil(t, series.Points[1].Values[0])
+		require.NotNil(t, series.Points[2].Values[0])
+		assert.Equal(t, 4.33333333, *series.Points[2].Values[0])
+	})
+
+	t.Run("unknown_type", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		analytics := NewAnalyticsService(mockRepo, nopLogger)
+
+		_, appErr := analytics.Indicators(ctx, domain.IndicatorRequest{Symbol: "BTC", Type: "vwap"})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
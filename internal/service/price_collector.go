@@ -5,14 +5,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/adal4ik/crypto-service/internal/buffer"
 	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/internal/repository"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 var httpClient = &http.Client{Timeout: 15 * time.Second}
@@ -36,11 +42,46 @@ var symbolToIDMap = map[string]string{
 	"ATOM":  "cosmos",
 }
 
+type CollectorInterface interface {
+	Schedules(ctx context.Context) ([]domain.SymbolSchedule, *apperrors.AppError)
+	Adaptive() bool
+	BufferStats() (buffer.Stats, bool)
+	CollectionErrors() map[string]domain.CollectionError
+}
+
+const (
+	// metadataPerTick - сколько валют без имени дозаполняется за тик, чтобы справочник
+	// провайдера не отнимал у опроса цен заметную часть бюджета лимитера.
+	metadataPerTick = 1
+	// metadataMaxBackoff - предельная пауза между попытками заполнить метаданные одной валюты.
+	metadataMaxBackoff = 24 * time.Hour
+)
+
 type PriceCollector struct {
 	currencyRepo repository.CurrencyRepositoryInterface
 	priceRepo    repository.PriceRepositoryInterface
 	logger       logger.Logger
 	cfg          config.CollectorConfig
+	limiter      *rate.Limiter
+	scheduler    *adaptiveScheduler
+	buffer       *buffer.FileBuffer
+	// lastCurrencies - последний успешно прочитанный список валют; используется,
+	// пока база недоступна, чтобы продолжать сбор цен в буфер.
+	lastCurrencies []domain.Currency
+
+	// metadataRetry - когда снова пробовать заполнить метаданные валюты после неудачи.
+	// Как и lastCurrencies, используется только горутиной сбора.
+	metadataRetry map[string]metadataAttempt
+
+	errMu sync.Mutex
+	// lastErrors - последняя ошибка сбора по символу; запись удаляется после успешного сбора.
+	lastErrors map[string]domain.CollectionError
+}
+
+// metadataAttempt - состояние повторов заполнения метаданных одной валюты.
+type metadataAttempt struct {
+	next    time.Time
+	backoff time.Duration
 }
 
 func NewPriceCollector(
@@ -49,19 +90,40 @@ func NewPriceCollector(
 	logger logger.Logger,
 	cfg config.CollectorConfig,
 ) *PriceCollector {
-	return &PriceCollector{
-		currencyRepo: currencyRepo,
-		priceRepo:    priceRepo,
-		logger:       logger,
-		cfg:          cfg,
+	pc := &PriceCollector{
+		currencyRepo:  currencyRepo,
+		priceRepo:     priceRepo,
+		logger:        logger,
+		cfg:           cfg,
+		limiter:       newRateLimiter(cfg.RateLimitPerMinute, cfg.MaxConcurrency),
+		metadataRetry: make(map[string]metadataAttempt),
+		lastErrors:    make(map[string]domain.CollectionError),
+	}
+	if cfg.Adaptive {
+		pc.scheduler = newAdaptiveScheduler(cfg)
+	}
+	if cfg.BufferPath != "" {
+		b, err := buffer.Open(cfg.BufferPath)
+		if err != nil {
+			logger.Error("failed to open price buffer, unsaved prices will be lost", zap.Error(err), zap.String("path", cfg.BufferPath))
+		} else {
+			pc.buffer = b
+		}
 	}
+	return pc
 }
 
 func (pc *PriceCollector) Start(ctx context.Context) {
 	l := pc.logger.With(zap.String("service", "PriceCollector"))
-	l.Info("Starting price collector...", zap.Duration("interval", pc.cfg.Interval))
+	tick := pc.cfg.Interval
+	if pc.scheduler != nil && pc.cfg.MinInterval > 0 {
+		// В адаптивном режиме тикаем с минимальным интервалом, а какие символы
+		// опрашивать на каждом тике, решает планировщик.
+		tick = pc.cfg.MinInterval
+	}
+	l.Info("Starting price collector...", zap.Duration("interval", tick), zap.Bool("adaptive", pc.scheduler != nil))
 
-	ticker := time.NewTicker(pc.cfg.Interval)
+	ticker := time.NewTicker(tick)
 	defer ticker.Stop()
 
 	for {
@@ -78,24 +140,65 @@ func (pc *PriceCollector) Start(ctx context.Context) {
 func (pc *PriceCollector) collectPrices(ctx context.Context) {
 	l := pc.logger.With(zap.String("job", "collectPrices"))
 
-	symbols, appErr := pc.currencyRepo.GetAll(ctx)
+	pc.replayBuffer(ctx)
+
+	currencies, appErr := pc.currencyRepo.List(ctx)
 	if appErr != nil {
-		l.Error("failed to get tracked currencies", zap.Error(appErr))
+		if pc.buffer == nil || len(pc.lastCurrencies) == 0 {
+			l.Error("failed to get tracked currencies", zap.Error(appErr))
+			return
+		}
+		l.Warn("failed to get tracked currencies, using last known list", zap.Error(appErr))
+		currencies = pc.lastCurrencies
+	} else {
+		pc.lastCurrencies = currencies
+		// Метаданные дозаполняются после сбора цен, чтобы справочник провайдера их не задерживал.
+		defer pc.populateMetadata(ctx, currencies, time.Now())
+	}
+
+	// Выключенные и архивные валюты не опрашиваются; ID у провайдера берётся из метаданных валюты.
+	var symbols []string
+	ids := make(map[string]string, len(currencies))
+	for _, c := range currencies {
+		if !collectable(c) {
+			continue
+		}
+		symbols = append(symbols, c.Symbol)
+		if id, ok := providerID(c); ok {
+			ids[c.Symbol] = id
+		}
+	}
+	pc.forgetErrors(symbols)
+	tracked := len(symbols)
+	if pc.scheduler != nil {
+		// due вызывается и без собираемых валют: заодно он забывает выбывшие из сбора символы.
+		symbols = pc.scheduler.due(symbols, time.Now())
+	}
+	if tracked == 0 {
+		l.Info("no currencies to track, skipping collection")
 		return
 	}
 	if len(symbols) == 0 {
-		l.Info("no currencies to track, skipping collection")
+		l.Debug("no currencies due for collection on this tick")
 		return
 	}
-	l.Info("found currencies to track", zap.Strings("symbols", symbols))
+	l.Info("found currencies to track", zap.Int("count", len(symbols)))
 
+	now := time.Now()
 	var coingeckoIDs []string
+	seen := make(map[string]struct{}, len(symbols))
 	for _, s := range symbols {
-		if id, ok := symbolToIDMap[strings.ToUpper(s)]; ok {
-			coingeckoIDs = append(coingeckoIDs, id)
-		} else {
+		id, ok := ids[s]
+		if !ok {
 			l.Warn("no coingecko mapping for symbol", zap.String("symbol", s))
+			pc.recordError(s, "no coingecko mapping for symbol", now)
+			continue
 		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		coingeckoIDs = append(coingeckoIDs, id)
 	}
 
 	if len(coingeckoIDs) == 0 {
@@ -103,44 +206,402 @@ func (pc *PriceCollector) collectPrices(ctx context.Context) {
 		return
 	}
 
-	idsString := strings.Join(coingeckoIDs, ",")
-	url := fmt.Sprintf("%s?ids=%s&vs_currencies=usd", pc.cfg.ApiBaseURL, idsString)
+	prices, failed := pc.fetchPrices(ctx, coingeckoIDs)
+	l.Info("successfully fetched prices", zap.Int("requested", len(coingeckoIDs)), zap.Int("received", len(prices)))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	for _, symbol := range symbols {
+		coingeckoID, ok := ids[symbol]
+		if !ok {
+			continue
+		}
+		if err, ok := failed[coingeckoID]; ok {
+			pc.recordError(symbol, err.Error(), now)
+			continue
+		}
+
+		usdPriceFloat, ok := prices[coingeckoID]["usd"]
+		if !ok {
+			pc.recordError(symbol, "coingecko returned no usd price for "+coingeckoID, now)
+			continue
+		}
+		priceDecimal := decimal.NewFromFloat(usdPriceFloat)
+
+		if pc.savePrice(ctx, domain.PriceSample{Symbol: symbol, Price: priceDecimal, Timestamp: now}) {
+			pc.clearError(symbol)
+		}
+		if pc.scheduler != nil {
+			pc.scheduler.observe(symbol, usdPriceFloat, now)
+		}
+	}
+}
+
+// populateMetadata заполняет пустое имя и ID у CoinGecko неархивных валют из справочника провайдера,
+// не больше metadataPerTick за вызов. Имя сохраняется, только если символ монеты у провайдера совпадает
+// с символом валюты. После неудачи попытка повторяется с удваивающейся паузой, начиная с cfg.Interval.
+// Пустой cfg.CoinsURL выключает заполнение.
+func (pc *PriceCollector) populateMetadata(ctx context.Context, currencies []domain.Currency, now time.Time) {
+	if pc.cfg.CoinsURL == "" {
+		return
+	}
+	attempts := 0
+	for _, c := range currencies {
+		if c.Name != "" || c.ArchivedAt != nil {
+			delete(pc.metadataRetry, c.Symbol)
+			continue
+		}
+		id, ok := providerID(c)
+		if !ok {
+			continue
+		}
+		if a, ok := pc.metadataRetry[c.Symbol]; ok && now.Before(a.next) {
+			continue
+		}
+		if attempts == metadataPerTick {
+			return
+		}
+		attempts++
+
+		l := pc.logger.With(zap.String("job", "populateMetadata"), zap.String("symbol", c.Symbol), zap.String("provider_id", id))
+		details, err := pc.CoinDetails(ctx, id)
+		switch {
+		case err != nil:
+			l.Warn("failed to fetch coin details, will retry", zap.Error(err))
+			pc.deferMetadata(c.Symbol, now)
+			continue
+		case !strings.EqualFold(details.Symbol, c.Symbol):
+			l.Warn("provider coin symbol does not match currency, metadata left empty", zap.String("provider_symbol", details.Symbol))
+			pc.deferMetadata(c.Symbol, now)
+			continue
+		case details.Name == "":
+			l.Warn("provider returned empty coin name, will retry")
+			pc.deferMetadata(c.Symbol, now)
+			continue
+		}
+
+		// Список валют мог устареть: проверка пустого имени и ID повторяется в самом UPDATE,
+		// чтобы не затереть правку, сделанную через PATCH после List.
+		filled, appErr := pc.currencyRepo.FillMetadata(ctx, c.Symbol, details.Name, id)
+		if appErr != nil {
+			l.Warn("failed to save coin details, will retry", zap.Error(appErr))
+			pc.deferMetadata(c.Symbol, now)
+			continue
+		}
+		if !filled {
+			l.Info("currency metadata changed concurrently, coin details discarded")
+		}
+		delete(pc.metadataRetry, c.Symbol)
+	}
+}
+
+// deferMetadata откладывает следующую попытку заполнить метаданные валюты.
+func (pc *PriceCollector) deferMetadata(symbol string, now time.Time) {
+	backoff := pc.cfg.Interval
+	if a, ok := pc.metadataRetry[symbol]; ok {
+		backoff = a.backoff * 2
+	}
+	if backoff <= 0 {
+		backoff = time.Minute
+	}
+	backoff = min(backoff, metadataMaxBackoff)
+	pc.metadataRetry[symbol] = metadataAttempt{next: now.Add(backoff), backoff: backoff}
+}
+
+// collectable сообщает, нужно ли собирать цены валюты.
+func collectable(c domain.Currency) bool {
+	return c.Enabled && c.ArchivedAt == nil
+}
+
+// providerID возвращает ID валюты у CoinGecko: из метаданных валюты,
+// а если он там не задан - из встроенного справочника.
+func providerID(c domain.Currency) (string, bool) {
+	if id := c.ProviderIDs[domain.ProviderCoinGecko]; id != "" {
+		return id, true
+	}
+	id, ok := symbolToIDMap[strings.ToUpper(c.Symbol)]
+	return id, ok
+}
+
+func (pc *PriceCollector) recordError(symbol, message string, at time.Time) {
+	pc.errMu.Lock()
+	defer pc.errMu.Unlock()
+	pc.lastErrors[symbol] = domain.CollectionError{Message: message, At: at}
+}
+
+func (pc *PriceCollector) clearError(symbol string) {
+	pc.errMu.Lock()
+	defer pc.errMu.Unlock()
+	delete(pc.lastErrors, symbol)
+}
+
+// forgetErrors удаляет ошибки валют, которые больше не собираются.
+func (pc *PriceCollector) forgetErrors(symbols []string) {
+	keep := make(map[string]struct{}, len(symbols))
+	for _, symbol := range symbols {
+		keep[symbol] = struct{}{}
+	}
+	pc.errMu.Lock()
+	defer pc.errMu.Unlock()
+	for symbol := range pc.lastErrors {
+		if _, ok := keep[symbol]; !ok {
+			delete(pc.lastErrors, symbol)
+		}
+	}
+}
+
+// CollectionErrors возвращает копию последних ошибок сбора по символам.
+func (pc *PriceCollector) CollectionErrors() map[string]domain.CollectionError {
+	pc.errMu.Lock()
+	defer pc.errMu.Unlock()
+	result := make(map[string]domain.CollectionError, len(pc.lastErrors))
+	for symbol, e := range pc.lastErrors {
+		result[symbol] = e
+	}
+	return result
+}
+
+// savePrice пишет цену в БД, а при ошибке сохраняет её в локальный буфер.
+// Пока буфер не пуст, новые цены сразу идут в него, чтобы сохранить порядок записи.
+// Возвращает false, если цена потеряна; причина записывается в ошибки сбора.
+func (pc *PriceCollector) savePrice(ctx context.Context, sample domain.PriceSample) bool {
+	l := pc.logger.With(zap.String("symbol", sample.Symbol))
+
+	if pc.buffer != nil && pc.buffer.Stats().Depth > 0 {
+		return pc.spill(l, sample)
+	}
+
+	if addErr := pc.priceRepo.Add(ctx, sample.Symbol, sample.Price, sample.Timestamp); addErr != nil {
+		l.Error("failed to save price to db", zap.Error(addErr))
+		if pc.buffer != nil && !rejectedSample(addErr) {
+			return pc.spill(l, sample)
+		}
+		pc.recordError(sample.Symbol, "failed to save price: "+addErr.Error(), sample.Timestamp)
+		return false
+	}
+	return true
+}
+
+func (pc *PriceCollector) spill(l logger.Logger, sample domain.PriceSample) bool {
+	if err := pc.buffer.Append(sample); err != nil {
+		l.Error("failed to buffer price, sample lost", zap.Error(err))
+		pc.recordError(sample.Symbol, "failed to buffer price: "+err.Error(), sample.Timestamp)
+		return false
+	}
+	l.Warn("price buffered for later replay", zap.Int("buffer_depth", pc.buffer.Stats().Depth))
+	return true
+}
+
+// replayBuffer переносит накопленные в буфере цены в БД по порядку.
+func (pc *PriceCollector) replayBuffer(ctx context.Context) {
+	if pc.buffer == nil || pc.buffer.Stats().Depth == 0 {
+		return
+	}
+	l := pc.logger.With(zap.String("job", "replayBuffer"))
+
+	deadBefore := pc.buffer.Stats().DeadLettered
+	replayed, err := pc.buffer.Replay(ctx, func(s domain.PriceSample) error {
+		if appErr := pc.priceRepo.Add(ctx, s.Symbol, s.Price, s.Timestamp); appErr != nil {
+			if rejectedSample(appErr) {
+				l.Error("buffered price rejected by db, moved to dead letters", zap.Error(appErr), zap.String("symbol", s.Symbol))
+				return buffer.Permanent(appErr)
+			}
+			return appErr
+		}
+		return nil
+	})
+	stats := pc.buffer.Stats()
 	if err != nil {
-		l.Error("failed to create http request", zap.Error(err))
+		l.Warn("buffer replay interrupted", zap.Error(err), zap.Int("replayed", replayed), zap.Int("remaining", stats.Depth))
 		return
 	}
+	l.Info("buffer replayed", zap.Int("replayed", replayed), zap.Int("dead_lettered", stats.DeadLettered-deadBefore))
+}
+
+// rejectedSample сообщает, что база отвергла сам сэмпл: повтор записи не поможет.
+func rejectedSample(appErr *apperrors.AppError) bool {
+	return appErr.Code >= 400 && appErr.Code < 500
+}
+
+// BufferStats возвращает состояние буфера; второй результат false, если буфер выключен.
+func (pc *PriceCollector) BufferStats() (buffer.Stats, bool) {
+	if pc.buffer == nil {
+		return buffer.Stats{}, false
+	}
+	return pc.buffer.Stats(), true
+}
+
+// Schedules возвращает эффективный интервал опроса по каждой собираемой валюте.
+// В фиксированном режиме у всех валют интервал равен cfg.Interval.
+func (pc *PriceCollector) Schedules(ctx context.Context) ([]domain.SymbolSchedule, *apperrors.AppError) {
+	if pc.scheduler != nil {
+		return pc.scheduler.schedules(), nil
+	}
+
+	currencies, appErr := pc.currencyRepo.List(ctx)
+	if appErr != nil {
+		return nil, appErr
+	}
+	result := make([]domain.SymbolSchedule, 0, len(currencies))
+	for _, c := range currencies {
+		if collectable(c) {
+			result = append(result, domain.SymbolSchedule{Symbol: c.Symbol, Interval: pc.cfg.Interval})
+		}
+	}
+	return result, nil
+}
+
+// Adaptive сообщает, включён ли адаптивный режим опроса.
+func (pc *PriceCollector) Adaptive() bool {
+	return pc.scheduler != nil
+}
+
+// fetchPrices разбивает ID на чанки, запрашивает их параллельно ограниченным пулом воркеров
+// и сливает ответы в одну карту. Ошибка одного чанка не отменяет остальные:
+// она возвращается во второй карте для каждого ID чанка.
+func (pc *PriceCollector) fetchPrices(ctx context.Context, ids []string) (map[string]map[string]float64, map[string]error) {
+	l := pc.logger.With(zap.String("job", "fetchPrices"))
+
+	chunks := chunkIDs(ids, pc.cfg.ChunkSize)
+	workers := pc.cfg.MaxConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	merged := make(map[string]map[string]float64, len(ids))
+	failed := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan []string)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range jobs {
+				prices, err := pc.fetchChunk(ctx, chunk)
+				if err != nil {
+					l.Error("failed to fetch price chunk", zap.Error(err), zap.Int("chunk_size", len(chunk)))
+					mu.Lock()
+					for _, id := range chunk {
+						failed[id] = err
+					}
+					mu.Unlock()
+					continue
+				}
+				mu.Lock()
+				for id, data := range prices {
+					merged[id] = data
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for _, chunk := range chunks {
+		select {
+		case jobs <- chunk:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return merged, failed
+}
+
+// fetchChunk выполняет один запрос к CoinGecko, предварительно дожидаясь слота в лимитере.
+func (pc *PriceCollector) fetchChunk(ctx context.Context, ids []string) (map[string]map[string]float64, error) {
+	if err := pc.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter: %w", err)
+	}
+
+	url := fmt.Sprintf("%s?ids=%s&vs_currencies=usd", pc.cfg.ApiBaseURL, strings.Join(ids, ","))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		l.Error("failed to fetch prices from coingecko", zap.Error(err), zap.String("url", url))
-		return
+		return nil, fmt.Errorf("failed to fetch prices from coingecko: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coingecko responded with status %d", resp.StatusCode)
+	}
+
 	var prices map[string]map[string]float64
 	if err := json.NewDecoder(resp.Body).Decode(&prices); err != nil {
-		l.Error("failed to decode coingecko response", zap.Error(err))
-		return
+		return nil, fmt.Errorf("failed to decode coingecko response: %w", err)
 	}
-	l.Info("successfully fetched prices", zap.Any("prices", prices))
+	return prices, nil
+}
 
-	now := time.Now()
-	for _, symbol := range symbols {
-		coingeckoID, ok := symbolToIDMap[strings.ToUpper(symbol)]
-		if !ok {
-			continue
-		}
+// CoinDetails запрашивает у CoinGecko справочные данные монеты. Запрос расходует
+// тот же бюджет лимитера, что и опрос цен.
+func (pc *PriceCollector) CoinDetails(ctx context.Context, id string) (domain.CoinDetails, error) {
+	if err := pc.limiter.Wait(ctx); err != nil {
+		return domain.CoinDetails{}, fmt.Errorf("rate limiter: %w", err)
+	}
 
-		if priceData, ok := prices[coingeckoID]; ok {
-			if usdPriceFloat, ok := priceData["usd"]; ok {
-				priceDecimal := decimal.NewFromFloat(usdPriceFloat)
+	detailsURL := fmt.Sprintf("%s/%s?localization=false&tickers=false&market_data=false&community_data=false&developer_data=false&sparkline=false",
+		strings.TrimSuffix(pc.cfg.CoinsURL, "/"), url.PathEscape(id))
 
-				if addErr := pc.priceRepo.Add(ctx, symbol, priceDecimal, now); addErr != nil {
-					l.Error("failed to save price to db", zap.Error(addErr), zap.String("symbol", symbol))
-				}
-			}
+	req, err := http.NewRequestWithContext(ctx, "GET", detailsURL, nil)
+	if err != nil {
+		return domain.CoinDetails{}, fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return domain.CoinDetails{}, fmt.Errorf("failed to fetch coin details from coingecko: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return domain.CoinDetails{}, fmt.Errorf("coingecko responded with status %d", resp.StatusCode)
+	}
+
+	var details struct {
+		ID     string `json:"id"`
+		Symbol string `json:"symbol"`
+		Name   string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		return domain.CoinDetails{}, fmt.Errorf("failed to decode coingecko response: %w", err)
+	}
+	return domain.CoinDetails{ID: details.ID, Symbol: strings.ToUpper(details.Symbol), Name: details.Name}, nil
+}
+
+// chunkIDs делит список на части не длиннее size. При size <= 0 возвращает один чанк.
+func chunkIDs(ids []string, size int) [][]string {
+	if size <= 0 || size >= len(ids) {
+		return [][]string{ids}
+	}
+	chunks := make([][]string, 0, (len(ids)+size-1)/size)
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
 		}
+		chunks = append(chunks, ids[start:end])
+	}
+	return chunks
+}
+
+// newRateLimiter строит лимитер по бюджету запросов в минуту; 0 означает "без ограничений".
+func newRateLimiter(perMinute, burst int) *rate.Limiter {
+	if perMinute <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	if burst < 1 {
+		burst = 1
 	}
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), burst)
 }
@@ -3,19 +3,33 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/internal/repository/mocks"
+	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+func trackedCurrencies(symbols ...string) []domain.Currency {
+	currencies := make([]domain.Currency, 0, len(symbols))
+	for _, s := range symbols {
+		currencies = append(currencies, domain.Currency{Symbol: s, Enabled: true})
+	}
+	return currencies
+}
+
 func TestPriceCollector_collectPrices(t *testing.T) {
 	nopLogger := logger.NewNopLogger()
 	ctx := context.Background()
@@ -38,8 +52,7 @@ func TestPriceCollector_collectPrices(t *testing.T) {
 		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
 		mockPriceRepo := mocks.NewPriceRepositoryInterface(t)
 
-		trackedSymbols := []string{"BTC", "ETH"}
-		mockCurrencyRepo.On("GetAll", ctx).Return(trackedSymbols, nil)
+		mockCurrencyRepo.On("List", ctx).Return(trackedCurrencies("BTC", "ETH"), nil)
 
 		mockPriceRepo.On("Add", ctx, "BTC", decimal.NewFromFloat(65000.50), mock.AnythingOfType("time.Time")).Return(nil)
 		mockPriceRepo.On("Add", ctx, "ETH", decimal.NewFromFloat(3500.75), mock.AnythingOfType("time.Time")).Return(nil)
@@ -59,7 +72,7 @@ func TestPriceCollector_collectPrices(t *testing.T) {
 		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
 		mockPriceRepo := mocks.NewPriceRepositoryInterface(t)
 
-		mockCurrencyRepo.On("GetAll", ctx).Return([]string{}, nil)
+		mockCurrencyRepo.On("List", ctx).Return([]domain.Currency{}, nil)
 
 		cfg := config.CollectorConfig{}
 		collector := NewPriceCollector(mockCurrencyRepo, mockPriceRepo, nopLogger, cfg)
@@ -68,4 +81,357 @@ func TestPriceCollector_collectPrices(t *testing.T) {
 
 		mockPriceRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
+	t.Run("success_chunked_concurrent_fetch", func(t *testing.T) {
+		var requests int32
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			ids := strings.Split(r.URL.Query().Get("ids"), ",")
+			assert.LessOrEqual(t, len(ids), 2)
+
+			all := map[string]map[string]float64{
+				"bitcoin":  {"usd": 65000.50},
+				"ethereum": {"usd": 3500.75},
+				"solana":   {"usd": 150.25},
+			}
+			resp := make(map[string]map[string]float64)
+			for _, id := range ids {
+				resp[id] = all[id]
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(resp)
+		}))
+		defer mockServer.Close()
+
+		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockPriceRepo := mocks.NewPriceRepositoryInterface(t)
+
+		mockCurrencyRepo.On("List", ctx).Return(trackedCurrencies("BTC", "ETH", "SOL"), nil)
+		mockPriceRepo.On("Add", ctx, "BTC", decimal.NewFromFloat(65000.50), mock.AnythingOfType("time.Time")).Return(nil)
+		mockPriceRepo.On("Add", ctx, "ETH", decimal.NewFromFloat(3500.75), mock.AnythingOfType("time.Time")).Return(nil)
+		mockPriceRepo.On("Add", ctx, "SOL", decimal.NewFromFloat(150.25), mock.AnythingOfType("time.Time")).Return(nil)
+
+		cfg := config.CollectorConfig{
+			Interval:       1 * time.Minute,
+			ApiBaseURL:     mockServer.URL,
+			ChunkSize:      2,
+			MaxConcurrency: 2,
+		}
+		collector := NewPriceCollector(mockCurrencyRepo, mockPriceRepo, nopLogger, cfg)
+
+		collector.collectPrices(ctx)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("partial_failure_keeps_other_chunks", func(t *testing.T) {
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("ids") == "ethereum" {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]map[string]float64{
+				"bitcoin": {"usd": 65000.50},
+			})
+		}))
+		defer mockServer.Close()
+
+		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockPriceRepo := mocks.NewPriceRepositoryInterface(t)
+
+		mockCurrencyRepo.On("List", ctx).Return(trackedCurrencies("BTC", "ETH"), nil)
+		mockPriceRepo.On("Add", ctx, "BTC", decimal.NewFromFloat(65000.50), mock.AnythingOfType("time.Time")).Return(nil)
+
+		cfg := config.CollectorConfig{
+			ApiBaseURL:     mockServer.URL,
+			ChunkSize:      1,
+			MaxConcurrency: 2,
+		}
+		collector := NewPriceCollector(mockCurrencyRepo, mockPriceRepo, nopLogger, cfg)
+
+		collector.collectPrices(ctx)
+
+		mockPriceRepo.AssertNotCalled(t, "Add", ctx, "ETH", mock.Anything, mock.Anything)
+		errs := collector.CollectionErrors()
+		assert.Contains(t, errs["ETH"].Message, "status 429")
+		assert.NotContains(t, errs, "BTC")
+	})
+
+	t.Run("collection_errors_cleared_on_success", func(t *testing.T) {
+		var fail atomic.Bool
+		fail.Store(true)
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if fail.Load() {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]map[string]float64{"bitcoin": {"usd": 65000.50}})
+		}))
+		defer mockServer.Close()
+
+		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockPriceRepo := mocks.NewPriceRepositoryInterface(t)
+
+		mockCurrencyRepo.On("List", ctx).Return(trackedCurrencies("BTC", "FOO"), nil)
+		mockPriceRepo.On("Add", ctx, "BTC", decimal.NewFromFloat(65000.50), mock.AnythingOfType("time.Time")).Return(nil)
+
+		collector := NewPriceCollector(mockCurrencyRepo, mockPriceRepo, nopLogger, config.CollectorConfig{ApiBaseURL: mockServer.URL})
+
+		collector.collectPrices(ctx)
+		errs := collector.CollectionErrors()
+		assert.Contains(t, errs["BTC"].Message, "status 502")
+		assert.Equal(t, "no coingecko mapping for symbol", errs["FOO"].Message)
+
+		fail.Store(false)
+		collector.collectPrices(ctx)
+		errs = collector.CollectionErrors()
+		assert.NotContains(t, errs, "BTC")
+		assert.Contains(t, errs, "FOO")
+	})
+
+	t.Run("metadata_provider_id_disabled_and_archived", func(t *testing.T) {
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "wrapped-bitcoin", r.URL.Query().Get("ids"))
+			json.NewEncoder(w).Encode(map[string]map[string]float64{"wrapped-bitcoin": {"usd": 64990}})
+		}))
+		defer mockServer.Close()
+
+		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockPriceRepo := mocks.NewPriceRepositoryInterface(t)
+
+		archivedAt := time.Now().Add(-time.Hour)
+		mockCurrencyRepo.On("List", ctx).Return([]domain.Currency{
+			{Symbol: "ETH", Enabled: false},
+			{Symbol: "SOL", Enabled: true, ArchivedAt: &archivedAt},
+			{Symbol: "WBTC", Enabled: true, ProviderIDs: map[string]string{domain.ProviderCoinGecko: "wrapped-bitcoin"}},
+		}, nil)
+		mockPriceRepo.On("Add", ctx, "WBTC", decimal.NewFromFloat(64990), mock.AnythingOfType("time.Time")).Return(nil)
+
+		collector := NewPriceCollector(mockCurrencyRepo, mockPriceRepo, nopLogger, config.CollectorConfig{ApiBaseURL: mockServer.URL})
+
+		collector.collectPrices(ctx)
+
+		assert.Empty(t, collector.CollectionErrors())
+	})
+
+	t.Run("archiving_last_symbol_clears_schedule", func(t *testing.T) {
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]map[string]float64{"bitcoin": {"usd": 65000}})
+		}))
+		defer mockServer.Close()
+
+		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockPriceRepo := mocks.NewPriceRepositoryInterface(t)
+
+		archivedAt := time.Now()
+		mockCurrencyRepo.On("List", ctx).Return(trackedCurrencies("BTC"), nil).Once()
+		mockCurrencyRepo.On("List", ctx).Return([]domain.Currency{{Symbol: "BTC", Enabled: true, ArchivedAt: &archivedAt}}, nil).Once()
+		mockPriceRepo.On("Add", ctx, "BTC", decimal.NewFromFloat(65000), mock.AnythingOfType("time.Time")).Return(nil)
+
+		collector := NewPriceCollector(mockCurrencyRepo, mockPriceRepo, nopLogger, config.CollectorConfig{
+			ApiBaseURL: mockServer.URL, Adaptive: true, Interval: time.Minute, MinInterval: 10 * time.Second, MaxInterval: 5 * time.Minute,
+		})
+
+		collector.collectPrices(ctx)
+		schedules, _ := collector.Schedules(ctx)
+		assert.Len(t, schedules, 1)
+
+		// Валют для сбора не осталось, но архивный символ всё равно должен пропасть из расписания.
+		collector.collectPrices(ctx)
+		schedules, _ = collector.Schedules(ctx)
+		assert.Empty(t, schedules)
+	})
+}
+
+func TestPriceCollector_buffer(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]map[string]float64{
+			"bitcoin": {"usd": 65000.50},
+		})
+	}))
+	defer mockServer.Close()
+
+	mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+	mockPriceRepo := mocks.NewPriceRepositoryInterface(t)
+	mockCurrencyRepo.On("List", ctx).Return(trackedCurrencies("BTC"), nil)
+
+	cfg := config.CollectorConfig{
+		ApiBaseURL: mockServer.URL,
+		BufferPath: filepath.Join(t.TempDir(), "prices.ndjson"),
+	}
+	collector := NewPriceCollector(mockCurrencyRepo, mockPriceRepo, nopLogger, cfg)
+
+	// База недоступна: цена уходит в буфер.
+	dbDown := apperrors.NewInternalServerError("database error", errors.New("connection refused"))
+	mockPriceRepo.On("Add", ctx, "BTC", decimal.NewFromFloat(65000.50), mock.AnythingOfType("time.Time")).Return(dbDown).Once()
+
+	collector.collectPrices(ctx)
+
+	stats, enabled := collector.BufferStats()
+	assert.True(t, enabled)
+	assert.Equal(t, 1, stats.Depth)
+	firstTimestamp := stats.Oldest
+
+	// База вернулась: сначала воспроизводится буфер, затем пишется новая цена.
+	var order []time.Time
+	mockPriceRepo.On("Add", ctx, "BTC", mock.Anything, mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) { order = append(order, args.Get(3).(time.Time)) }).
+		Return(nil).Twice()
+
+	collector.collectPrices(ctx)
+
+	stats, _ = collector.BufferStats()
+	assert.Equal(t, 0, stats.Depth)
+	if assert.Len(t, order, 2) {
+		assert.True(t, order[0].Equal(firstTimestamp))
+		assert.True(t, order[1].After(order[0]))
+	}
+}
+
+func TestPriceCollector_degradedDatabase(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]map[string]float64{
+			"bitcoin": {"usd": 65000.50},
+		})
+	}))
+	defer mockServer.Close()
+
+	mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+	mockPriceRepo := mocks.NewPriceRepositoryInterface(t)
+	dbDown := apperrors.NewInternalServerError("database error", errors.New("connection refused"))
+
+	mockCurrencyRepo.On("List", ctx).Return(trackedCurrencies("BTC"), nil).Once()
+	mockPriceRepo.On("Add", ctx, "BTC", decimal.NewFromFloat(65000.50), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	cfg := config.CollectorConfig{
+		ApiBaseURL: mockServer.URL,
+		BufferPath: filepath.Join(t.TempDir(), "prices.ndjson"),
+	}
+	collector := NewPriceCollector(mockCurrencyRepo, mockPriceRepo, nopLogger, cfg)
+	collector.collectPrices(ctx)
+
+	// База пропала целиком: список валют берётся из последнего успешного чтения.
+	mockCurrencyRepo.On("List", ctx).Return(nil, dbDown).Once()
+	mockPriceRepo.On("Add", ctx, "BTC", decimal.NewFromFloat(65000.50), mock.AnythingOfType("time.Time")).Return(dbDown).Once()
+
+	collector.collectPrices(ctx)
+
+	stats, _ := collector.BufferStats()
+	assert.Equal(t, 1, stats.Depth)
+}
+
+func TestChunkIDs(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e"}
+
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, chunkIDs(ids, 2))
+	assert.Equal(t, [][]string{ids}, chunkIDs(ids, 0))
+	assert.Equal(t, [][]string{ids}, chunkIDs(ids, 10))
+}
+
+func TestPriceCollector_CoinDetails(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/coins/solana" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "false", r.URL.Query().Get("market_data"))
+		json.NewEncoder(w).Encode(map[string]any{"id": "solana", "symbol": "sol", "name": "Solana"})
+	}))
+	defer mockServer.Close()
+
+	collector := NewPriceCollector(nil, nil, logger.NewNopLogger(), config.CollectorConfig{CoinsURL: mockServer.URL + "/coins/"})
+
+	details, err := collector.CoinDetails(context.Background(), "solana")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CoinDetails{ID: "solana", Symbol: "SOL", Name: "Solana"}, details)
+
+	_, err = collector.CoinDetails(context.Background(), "no-such-coin")
+	assert.ErrorContains(t, err, "status 404")
+}
+
+func TestPriceCollector_populateMetadata(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	var hits atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/coins/solana":
+			json.NewEncoder(w).Encode(map[string]any{"id": "solana", "symbol": "sol", "name": "Solana"})
+		case "/coins/ethereum":
+			json.NewEncoder(w).Encode(map[string]any{"id": "ethereum", "symbol": "eth", "name": "Ethereum"})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer mockServer.Close()
+	cfg := config.CollectorConfig{Interval: time.Minute, RateLimitPerMinute: 1000, CoinsURL: mockServer.URL + "/coins/"}
+
+	t.Run("fills_name_and_provider_id", func(t *testing.T) {
+		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockCurrencyRepo.On("FillMetadata", ctx, "SOL", "Solana", "solana").Return(true, nil)
+
+		collector := NewPriceCollector(mockCurrencyRepo, nil, nopLogger, cfg)
+		collector.populateMetadata(ctx, []domain.Currency{
+			{Symbol: "ETH", Name: "Ether", Enabled: true},
+			{Symbol: "SOL", Enabled: true},
+		}, now)
+
+		assert.Empty(t, collector.metadataRetry)
+	})
+
+	t.Run("row_changed_after_list", func(t *testing.T) {
+		// Между List и записью имя задали через PATCH: репозиторий пропускает запись, повтор не нужен.
+		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+		mockCurrencyRepo.On("FillMetadata", ctx, "SOL", "Solana", "solana").Return(false, nil)
+
+		collector := NewPriceCollector(mockCurrencyRepo, nil, nopLogger, cfg)
+		collector.populateMetadata(ctx, []domain.Currency{{Symbol: "SOL", Enabled: true}}, now)
+
+		mockCurrencyRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		assert.Empty(t, collector.metadataRetry)
+	})
+
+	t.Run("symbol_mismatch_not_saved", func(t *testing.T) {
+		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+
+		collector := NewPriceCollector(mockCurrencyRepo, nil, nopLogger, cfg)
+		collector.populateMetadata(ctx, []domain.Currency{
+			{Symbol: "BTC", Enabled: true, ProviderIDs: map[string]string{domain.ProviderCoinGecko: "ethereum"}},
+		}, now)
+
+		mockCurrencyRepo.AssertNotCalled(t, "FillMetadata", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		assert.Contains(t, collector.metadataRetry, "BTC")
+	})
+
+	t.Run("one_per_call_with_backoff", func(t *testing.T) {
+		mockCurrencyRepo := mocks.NewCurrencyRepositoryInterface(t)
+		collector := NewPriceCollector(mockCurrencyRepo, nil, nopLogger, cfg)
+		// У монеты провайдер отвечает ошибкой; у архивной валюты имя не заполняется вовсе.
+		currencies := []domain.Currency{
+			{Symbol: "FOO", Enabled: true, ProviderIDs: map[string]string{domain.ProviderCoinGecko: "foo"}},
+			{Symbol: "BAR", Enabled: true, ProviderIDs: map[string]string{domain.ProviderCoinGecko: "bar"}},
+			{Symbol: "OLD", Enabled: true, ProviderIDs: map[string]string{domain.ProviderCoinGecko: "old"}, ArchivedAt: &now},
+		}
+		hits.Store(0)
+
+		collector.populateMetadata(ctx, currencies, now)
+		assert.Equal(t, int32(1), hits.Load(), "only one lookup per call")
+		collector.populateMetadata(ctx, currencies, now)
+		assert.Equal(t, int32(2), hits.Load(), "FOO is backed off, BAR is tried next")
+		collector.populateMetadata(ctx, currencies, now.Add(30*time.Second))
+		assert.Equal(t, int32(2), hits.Load(), "both are backed off for cfg.Interval")
+
+		collector.populateMetadata(ctx, currencies, now.Add(time.Minute))
+		assert.Equal(t, int32(3), hits.Load())
+		assert.Equal(t, 2*time.Minute, collector.metadataRetry["FOO"].backoff, "backoff doubles")
+		assert.NotContains(t, collector.metadataRetry, "OLD")
+	})
 }
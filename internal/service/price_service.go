@@ -2,26 +2,56 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/internal/repository"
+	"github.com/adal4ik/crypto-service/internal/timeseries"
 	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+	defaultBatchLimit   = 1000
+)
+
 type PriceServiceInterface interface {
 	GetNearestPrice(ctx context.Context, symbol string, unixTimestamp int64) (decimal.Decimal, time.Time, *apperrors.AppError)
+	GetPriceAt(ctx context.Context, req domain.PriceAtRequest) (domain.PriceQuote, *apperrors.AppError)
+	GetHistory(ctx context.Context, query domain.PriceRangeQuery, cursor string) (domain.PricePage, *apperrors.AppError)
+	GetCandles(ctx context.Context, req domain.CandleRequest) ([]domain.Candle, *apperrors.AppError)
+	GetLatest(ctx context.Context, symbols []string) ([]domain.LatestPrice, *apperrors.AppError)
+	GetNearestPrices(ctx context.Context, lookups []domain.PriceLookup) ([]BatchPriceResult, *apperrors.AppError)
+}
+
+// BatchPriceResult - результат элемента пакета: цена или ошибка этого элемента. Ошибка живёт
+// в слое сервиса, а не в domain, потому что несёт транспортный код ответа.
+type BatchPriceResult struct {
+	Symbol      string
+	RequestedAt time.Time
+	Price       decimal.Decimal
+	Timestamp   time.Time
+	Err         *apperrors.AppError
 }
 
 type priceService struct {
 	repo   repository.PriceRepositoryInterface
 	logger logger.Logger
+	cfg    config.AppConfig
 }
 
-func NewPriceService(repo repository.PriceRepositoryInterface, logger logger.Logger) PriceServiceInterface {
-	return &priceService{repo: repo, logger: logger}
+func NewPriceService(repo repository.PriceRepositoryInterface, logger logger.Logger, cfg config.AppConfig) PriceServiceInterface {
+	return &priceService{repo: repo, logger: logger, cfg: cfg}
 }
 
 func (s *priceService) GetNearestPrice(ctx context.Context, symbol string, unixTimestamp int64) (decimal.Decimal, time.Time, *apperrors.AppError) {
@@ -32,3 +62,296 @@ func (s *priceService) GetNearestPrice(ctx context.Context, symbol string, unixT
 
 	return s.repo.GetNearest(ctx, symbol, targetTime)
 }
+
+// GetPriceAt ищет цену на момент req.Timestamp в заданном режиме. Если подходящий сэмпл
+// дальше req.MaxDistance, возвращается 404 с расстоянием до ближайшего из них.
+func (s *priceService) GetPriceAt(ctx context.Context, req domain.PriceAtRequest) (domain.PriceQuote, *apperrors.AppError) {
+	l := s.logger.With(zap.String("symbol", req.Symbol), zap.Time("timestamp", req.Timestamp), zap.String("layer", "price_service"))
+	l.Info("Getting price at timestamp")
+
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if req.Symbol == "" {
+		return domain.PriceQuote{}, apperrors.NewBadRequest("currency symbol cannot be empty", nil)
+	}
+	switch req.Mode {
+	case "":
+		req.Mode = domain.PriceModeNearest
+	case domain.PriceModeNearest, domain.PriceModeBefore, domain.PriceModeAfter, domain.PriceModeInterpolate:
+	default:
+		return domain.PriceQuote{}, apperrors.NewBadRequest("mode must be 'nearest', 'before', 'after' or 'interpolate'", nil)
+	}
+	if req.MaxDistance < 0 {
+		return domain.PriceQuote{}, apperrors.NewBadRequest("max_distance cannot be negative", nil)
+	}
+
+	before, after, appErr := s.repo.GetBracketing(ctx, req.Symbol, req.Timestamp)
+	if appErr != nil {
+		return domain.PriceQuote{}, appErr
+	}
+
+	if req.Mode == domain.PriceModeInterpolate {
+		return s.interpolatePrice(l, req, before, after)
+	}
+
+	var candidate *domain.PriceSample
+	switch req.Mode {
+	case domain.PriceModeBefore:
+		candidate = before
+	case domain.PriceModeAfter:
+		candidate = after
+	default:
+		candidate = nearerSample(req.Timestamp, before, after)
+	}
+	if candidate == nil {
+		return domain.PriceQuote{}, apperrors.NewNotFound(noSampleMessage(req.Mode), nil)
+	}
+	if appErr := checkDistance(l, req, candidate); appErr != nil {
+		return domain.PriceQuote{}, appErr
+	}
+
+	return domain.PriceQuote{Price: candidate.Price, Timestamp: candidate.Timestamp}, nil
+}
+
+// interpolatePrice вычисляет цену на req.Timestamp между соседними сэмплами. Нужны обе стороны,
+// и каждая должна укладываться в req.MaxDistance.
+func (s *priceService) interpolatePrice(l logger.Logger, req domain.PriceAtRequest, before, after *domain.PriceSample) (domain.PriceQuote, *apperrors.AppError) {
+	if before != nil && before.Timestamp.Equal(req.Timestamp) {
+		return domain.PriceQuote{Price: before.Price, Timestamp: before.Timestamp, Before: before, After: before}, nil
+	}
+	if before == nil {
+		return domain.PriceQuote{}, apperrors.NewNotFound("cannot interpolate: no price at or before this timestamp", nil)
+	}
+	if after == nil {
+		return domain.PriceQuote{}, apperrors.NewNotFound("cannot interpolate: no price at or after this timestamp", nil)
+	}
+	for _, side := range []*domain.PriceSample{before, after} {
+		if appErr := checkDistance(l, req, side); appErr != nil {
+			return domain.PriceQuote{}, appErr
+		}
+	}
+
+	price := timeseries.Interpolate(
+		timeseries.Point{Time: before.Timestamp, Value: before.Price},
+		timeseries.Point{Time: after.Timestamp, Value: after.Price},
+		req.Timestamp,
+	)
+	return domain.PriceQuote{
+		Price:        price,
+		Timestamp:    req.Timestamp,
+		Interpolated: true,
+		Before:       before,
+		After:        after,
+	}, nil
+}
+
+// checkDistance возвращает 404 с расстоянием до сэмпла, если он дальше req.MaxDistance.
+func checkDistance(l logger.Logger, req domain.PriceAtRequest, sample *domain.PriceSample) *apperrors.AppError {
+	distance := absDuration(sample.Timestamp.Sub(req.Timestamp))
+	if req.MaxDistance == 0 || distance <= req.MaxDistance {
+		return nil
+	}
+	l.Warn("sample is outside tolerance", zap.Duration("distance", distance))
+	return apperrors.NewNotFound(
+		fmt.Sprintf("sample is %s away from the requested time, max_distance is %s", distance, req.MaxDistance), nil,
+	).WithDetails(map[string]int64{
+		"closest_timestamp": sample.Timestamp.Unix(),
+		"distance_seconds":  int64(distance / time.Second),
+	})
+}
+
+// nearerSample выбирает из двух соседних сэмплов ближайший к t; при равенстве - более ранний.
+func nearerSample(t time.Time, before, after *domain.PriceSample) *domain.PriceSample {
+	switch {
+	case before == nil:
+		return after
+	case after == nil:
+		return before
+	case absDuration(after.Timestamp.Sub(t)) < absDuration(t.Sub(before.Timestamp)):
+		return after
+	default:
+		return before
+	}
+}
+
+func noSampleMessage(mode domain.PriceMode) string {
+	switch mode {
+	case domain.PriceModeBefore:
+		return "no price found at or before this timestamp"
+	case domain.PriceModeAfter:
+		return "no price found at or after this timestamp"
+	default:
+		return "no price history found for this currency"
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// GetHistory отдаёт страницу истории цен. Курсор непрозрачен для клиента и привязан к порядку сортировки.
+func (s *priceService) GetHistory(ctx context.Context, query domain.PriceRangeQuery, cursor string) (domain.PricePage, *apperrors.AppError) {
+	l := s.logger.With(zap.String("symbol", query.Symbol), zap.String("layer", "price_service"))
+	l.Info("Getting price history")
+
+	query.Symbol = strings.ToUpper(strings.TrimSpace(query.Symbol))
+	if query.Symbol == "" {
+		return domain.PricePage{}, apperrors.NewBadRequest("currency symbol cannot be empty", nil)
+	}
+	switch query.Order {
+	case "":
+		query.Order = domain.SortDesc
+	case domain.SortAsc, domain.SortDesc:
+	default:
+		return domain.PricePage{}, apperrors.NewBadRequest("order must be 'asc' or 'desc'", nil)
+	}
+	switch {
+	case query.Limit == 0:
+		query.Limit = defaultHistoryLimit
+	case query.Limit < 0 || query.Limit > maxHistoryLimit:
+		return domain.PricePage{}, apperrors.NewBadRequest(fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit), nil)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		return domain.PricePage{}, apperrors.NewBadRequest("'from' must not be after 'to'", nil)
+	}
+	if cursor != "" {
+		after, afterID, err := decodeHistoryCursor(cursor, query.Order)
+		if err != nil {
+			return domain.PricePage{}, apperrors.NewBadRequest("invalid cursor", err)
+		}
+		query.After, query.AfterID = after, afterID
+	}
+
+	// Берём на одну запись больше, чтобы понять, есть ли следующая страница.
+	limit := query.Limit
+	query.Limit++
+	samples, appErr := s.repo.GetRange(ctx, query)
+	if appErr != nil {
+		return domain.PricePage{}, appErr
+	}
+
+	page := domain.PricePage{Samples: samples}
+	if len(samples) > limit {
+		page.Samples = samples[:limit]
+		page.NextCursor = encodeHistoryCursor(page.Samples[limit-1], query.Order)
+	}
+	return page, nil
+}
+
+// GetLatest возвращает последние цены отслеживаемых валют с изменением за 24 часа.
+func (s *priceService) GetLatest(ctx context.Context, symbols []string) ([]domain.LatestPrice, *apperrors.AppError) {
+	l := s.logger.With(zap.Strings("symbols", symbols), zap.String("layer", "price_service"))
+	l.Info("Getting latest prices")
+
+	normalized := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			normalized = append(normalized, symbol)
+		}
+	}
+
+	prices, appErr := s.repo.GetLatest(ctx, normalized)
+	if appErr != nil {
+		return nil, appErr
+	}
+	for i := range prices {
+		p := &prices[i]
+		if p.Price24hAgo == nil {
+			continue
+		}
+		change := p.Price.Sub(*p.Price24hAgo)
+		p.Change24h = &change
+		if !p.Price24hAgo.IsZero() {
+			percent := change.Div(*p.Price24hAgo).Mul(decimal.NewFromInt(100)).Round(4)
+			p.ChangePercent24h = &percent
+		}
+	}
+	return prices, nil
+}
+
+// GetNearestPrices разрешает пакет запросов ближайшей цены. Ошибки отдельных элементов
+// (пустой символ, неотслеживаемая валюта, нет истории) возвращаются в их результатах
+// и не прерывают обработку остального пакета.
+func (s *priceService) GetNearestPrices(ctx context.Context, lookups []domain.PriceLookup) ([]BatchPriceResult, *apperrors.AppError) {
+	l := s.logger.With(zap.Int("items", len(lookups)), zap.String("layer", "price_service"))
+	l.Info("Getting nearest prices batch")
+
+	limit := s.cfg.PriceBatchLimit
+	if limit <= 0 {
+		limit = defaultBatchLimit
+	}
+	if len(lookups) == 0 {
+		return nil, apperrors.NewBadRequest("batch must contain at least one item", nil)
+	}
+	if len(lookups) > limit {
+		return nil, apperrors.NewBadRequest(fmt.Sprintf("batch contains %d items, at most %d allowed", len(lookups), limit), nil)
+	}
+
+	results := make([]BatchPriceResult, len(lookups))
+	valid := make([]domain.PriceLookup, 0, len(lookups))
+	positions := make([]int, 0, len(lookups))
+	for i, lookup := range lookups {
+		symbol := strings.ToUpper(strings.TrimSpace(lookup.Symbol))
+		results[i] = BatchPriceResult{Symbol: symbol, RequestedAt: lookup.Timestamp}
+		switch {
+		case symbol == "":
+			results[i].Err = apperrors.NewBadRequest("field 'coin' is required", nil)
+		case lookup.Timestamp.IsZero():
+			results[i].Err = apperrors.NewBadRequest("field 'timestamp' is required", nil)
+		default:
+			valid = append(valid, domain.PriceLookup{Symbol: symbol, Timestamp: lookup.Timestamp})
+			positions = append(positions, i)
+		}
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	found, appErr := s.repo.GetNearestBatch(ctx, valid)
+	if appErr != nil {
+		return nil, appErr
+	}
+	for j, res := range found {
+		out := &results[positions[j]]
+		switch {
+		case !res.Tracked:
+			out.Err = apperrors.NewNotFound("currency is not tracked", nil)
+		case !res.Found:
+			out.Err = apperrors.NewNotFound("no price history found for this currency", nil)
+		default:
+			out.Price, out.Timestamp = res.Price, res.Timestamp
+		}
+	}
+	return results, nil
+}
+
+// encodeHistoryCursor кодирует позицию последнего сэмпла страницы: порядок, timestamp и id записи.
+func encodeHistoryCursor(last domain.PriceSample, order domain.SortOrder) string {
+	raw := fmt.Sprintf("%s:%d:%d", order, last.Timestamp.UnixNano(), last.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryCursor(cursor string, order domain.SortOrder) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return time.Time{}, 0, errors.New("malformed cursor")
+	}
+	if domain.SortOrder(parts[0]) != order {
+		return time.Time{}, 0, errors.New("cursor was issued for a different order")
+	}
+	n, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return time.Unix(0, n).UTC(), id, nil
+}
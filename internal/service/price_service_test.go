@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/adal4ik/crypto-service/internal/config"
+	"github.com/adal4ik/crypto-service/internal/domain"
 	"github.com/adal4ik/crypto-service/internal/repository/mocks"
 	"github.com/adal4ik/crypto-service/pkg/apperrors"
 	"github.com/adal4ik/crypto-service/pkg/logger"
@@ -33,7 +35,7 @@ func TestPriceService_GetNearestPrice(t *testing.T) {
 		mockRepo.On("GetNearest", ctx, symbol, expectedTime).
 			Return(expectedPrice, expectedFoundTime, nil)
 
-		priceService := NewPriceService(mockRepo, nopLogger)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
 
 		price, foundTime, appErr := priceService.GetNearestPrice(ctx, symbol, unixTimestamp)
 
@@ -53,7 +55,7 @@ func TestPriceService_GetNearestPrice(t *testing.T) {
 		mockRepo.On("GetNearest", ctx, symbol, expectedTime).
 			Return(decimal.Zero, time.Time{}, expectedError)
 
-		priceService := NewPriceService(mockRepo, nopLogger)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
 
 		_, _, appErr := priceService.GetNearestPrice(ctx, symbol, unixTimestamp)
 
@@ -73,7 +75,7 @@ func TestPriceService_GetNearestPrice(t *testing.T) {
 		mockRepo.On("GetNearest", ctx, symbol, expectedTime).
 			Return(decimal.Zero, time.Time{}, expectedError)
 
-		priceService := NewPriceService(mockRepo, nopLogger)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
 
 		_, _, appErr := priceService.GetNearestPrice(ctx, symbol, unixTimestamp)
 
@@ -81,3 +83,281 @@ func TestPriceService_GetNearestPrice(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, appErr.Code)
 	})
 }
+
+func TestPriceService_GetHistory(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	samples := func(ts ...int64) []domain.PriceSample {
+		result := make([]domain.PriceSample, 0, len(ts))
+		for _, t := range ts {
+			result = append(result, domain.PriceSample{Symbol: "BTC", Price: decimal.NewFromInt(t), Timestamp: time.Unix(t, 0).UTC()})
+		}
+		return result
+	}
+
+	t.Run("pages_through_history", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		mockRepo.On("GetRange", ctx, domain.PriceRangeQuery{Symbol: "BTC", Order: domain.SortDesc, Limit: 3}).
+			Return(samples(300, 200, 100), nil)
+
+		page, appErr := priceService.GetHistory(ctx, domain.PriceRangeQuery{Symbol: " btc ", Limit: 2}, "")
+
+		require.Nil(t, appErr)
+		assert.Len(t, page.Samples, 2)
+		require.NotEmpty(t, page.NextCursor)
+
+		mockRepo.On("GetRange", ctx, domain.PriceRangeQuery{Symbol: "BTC", Order: domain.SortDesc, Limit: 3, After: time.Unix(200, 0).UTC()}).
+			Return(samples(100), nil)
+
+		page, appErr = priceService.GetHistory(ctx, domain.PriceRangeQuery{Symbol: "BTC", Limit: 2}, page.NextCursor)
+
+		require.Nil(t, appErr)
+		assert.Len(t, page.Samples, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("duplicate_timestamps_across_page_boundary", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		ts := time.Unix(200, 0).UTC()
+		sample := func(id int64) domain.PriceSample {
+			return domain.PriceSample{ID: id, Symbol: "BTC", Price: decimal.NewFromInt(id), Timestamp: ts}
+		}
+		mockRepo.On("GetRange", ctx, domain.PriceRangeQuery{Symbol: "BTC", Order: domain.SortAsc, Limit: 3}).
+			Return([]domain.PriceSample{sample(1), sample(2), sample(3)}, nil)
+
+		page, appErr := priceService.GetHistory(ctx, domain.PriceRangeQuery{Symbol: "BTC", Order: domain.SortAsc, Limit: 2}, "")
+
+		require.Nil(t, appErr)
+		require.Len(t, page.Samples, 2)
+		require.NotEmpty(t, page.NextCursor)
+
+		// Курсор указывает на (timestamp, id) последнего сэмпла, поэтому третья запись с тем же timestamp
+		// попадает на следующую страницу, а не теряется.
+		mockRepo.On("GetRange", ctx, domain.PriceRangeQuery{Symbol: "BTC", Order: domain.SortAsc, Limit: 3, After: ts, AfterID: 2}).
+			Return([]domain.PriceSample{sample(3)}, nil)
+
+		page, appErr = priceService.GetHistory(ctx, domain.PriceRangeQuery{Symbol: "BTC", Order: domain.SortAsc, Limit: 2}, page.NextCursor)
+
+		require.Nil(t, appErr)
+		require.Len(t, page.Samples, 1)
+		assert.Equal(t, int64(3), page.Samples[0].ID)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("failure_cursor_for_other_order", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		cursor := encodeHistoryCursor(domain.PriceSample{Timestamp: time.Unix(200, 0)}, domain.SortDesc)
+		_, appErr := priceService.GetHistory(ctx, domain.PriceRangeQuery{Symbol: "BTC", Order: domain.SortAsc}, cursor)
+
+		require.Error(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+
+	t.Run("failure_invalid_params", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		cases := []domain.PriceRangeQuery{
+			{Symbol: ""},
+			{Symbol: "BTC", Order: "sideways"},
+			{Symbol: "BTC", Limit: maxHistoryLimit + 1},
+			{Symbol: "BTC", From: time.Unix(200, 0), To: time.Unix(100, 0)},
+		}
+		for _, q := range cases {
+			_, appErr := priceService.GetHistory(ctx, q, "")
+			require.Error(t, appErr)
+			assert.Equal(t, http.StatusBadRequest, appErr.Code)
+		}
+		_, appErr := priceService.GetHistory(ctx, domain.PriceRangeQuery{Symbol: "BTC"}, "not-a-cursor!")
+		require.Error(t, appErr)
+	})
+}
+
+func TestPriceService_GetLatest(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+
+	mockRepo := mocks.NewPriceRepositoryInterface(t)
+	priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+	dayAgo := decimal.NewFromInt(50000)
+	mockRepo.On("GetLatest", ctx, []string{"BTC", "ETH"}).Return([]domain.LatestPrice{
+		{Symbol: "BTC", Price: decimal.NewFromInt(55000), Timestamp: time.Unix(1700000000, 0), Price24hAgo: &dayAgo},
+		{Symbol: "ETH", Price: decimal.NewFromInt(3000), Timestamp: time.Unix(1700000000, 0)},
+	}, nil)
+
+	prices, appErr := priceService.GetLatest(ctx, []string{" btc", "eth", ""})
+
+	require.Nil(t, appErr)
+	require.Len(t, prices, 2)
+	require.NotNil(t, prices[0].Change24h)
+	assert.Equal(t, "5000", prices[0].Change24h.String())
+	assert.Equal(t, "10", prices[0].ChangePercent24h.String())
+	assert.Nil(t, prices[1].Change24h)
+	assert.Nil(t, prices[1].ChangePercent24h)
+}
+
+func TestPriceService_GetNearestPrices(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	ts := time.Unix(1700000000, 0)
+
+	t.Run("per_item_results", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		price := decimal.NewFromInt(55000)
+		mockRepo.On("GetNearestBatch", ctx, []domain.PriceLookup{
+			{Symbol: "BTC", Timestamp: ts},
+			{Symbol: "DOGE", Timestamp: ts},
+			{Symbol: "ETH", Timestamp: ts},
+		}).Return([]domain.PriceLookupResult{
+			{Symbol: "BTC", RequestedAt: ts, Tracked: true, Found: true, Price: price, Timestamp: ts},
+			{Symbol: "DOGE", RequestedAt: ts},
+			{Symbol: "ETH", RequestedAt: ts, Tracked: true},
+		}, nil)
+
+		results, appErr := priceService.GetNearestPrices(ctx, []domain.PriceLookup{
+			{Symbol: " btc", Timestamp: ts},
+			{Symbol: "", Timestamp: ts},
+			{Symbol: "doge", Timestamp: ts},
+			{Symbol: "SOL"},
+			{Symbol: "eth", Timestamp: ts},
+		})
+
+		require.Nil(t, appErr)
+		require.Len(t, results, 5)
+		assert.Nil(t, results[0].Err)
+		assert.Equal(t, price, results[0].Price)
+		require.NotNil(t, results[1].Err)
+		assert.Equal(t, http.StatusBadRequest, results[1].Err.Code)
+		require.NotNil(t, results[2].Err)
+		assert.Equal(t, "currency is not tracked", results[2].Err.Message)
+		require.NotNil(t, results[3].Err)
+		assert.Equal(t, http.StatusBadRequest, results[3].Err.Code)
+		require.NotNil(t, results[4].Err)
+		assert.Equal(t, http.StatusNotFound, results[4].Err.Code)
+	})
+
+	t.Run("batch_over_limit", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{PriceBatchLimit: 2})
+
+		_, appErr := priceService.GetNearestPrices(ctx, make([]domain.PriceLookup, 3))
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+
+	t.Run("empty_batch", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		_, appErr := priceService.GetNearestPrices(ctx, nil)
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
+
+func TestPriceService_GetPriceAt(t *testing.T) {
+	nopLogger := logger.NewNopLogger()
+	ctx := context.Background()
+	ts := time.Unix(1700000000, 0)
+	before := &domain.PriceSample{Symbol: "BTC", Price: decimal.NewFromInt(100), Timestamp: ts.Add(-30 * time.Second)}
+	after := &domain.PriceSample{Symbol: "BTC", Price: decimal.NewFromInt(110), Timestamp: ts.Add(10 * time.Second)}
+
+	tests := []struct {
+		name      string
+		mode      domain.PriceMode
+		before    *domain.PriceSample
+		after     *domain.PriceSample
+		maxDist   time.Duration
+		wantPrice string
+		wantCode  int
+	}{
+		{name: "nearest_default", before: before, after: after, wantPrice: "110"},
+		{name: "before", mode: domain.PriceModeBefore, before: before, after: after, wantPrice: "100"},
+		{name: "after", mode: domain.PriceModeAfter, before: before, after: after, wantPrice: "110"},
+		{name: "before_missing", mode: domain.PriceModeBefore, after: after, wantCode: http.StatusNotFound},
+		{name: "within_tolerance", mode: domain.PriceModeBefore, before: before, maxDist: time.Minute, wantPrice: "100"},
+		{name: "outside_tolerance", mode: domain.PriceModeBefore, before: before, maxDist: 20 * time.Second, wantCode: http.StatusNotFound},
+		{name: "interpolate", mode: domain.PriceModeInterpolate, before: before, after: after, wantPrice: "107.5"},
+		{name: "interpolate_missing_after", mode: domain.PriceModeInterpolate, before: before, wantCode: http.StatusNotFound},
+		{name: "interpolate_outside_tolerance", mode: domain.PriceModeInterpolate, before: before, after: after, maxDist: 20 * time.Second, wantCode: http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := mocks.NewPriceRepositoryInterface(t)
+			priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+			mockRepo.On("GetBracketing", ctx, "BTC", ts).Return(tc.before, tc.after, nil)
+
+			sample, appErr := priceService.GetPriceAt(ctx, domain.PriceAtRequest{
+				Symbol: "btc", Timestamp: ts, Mode: tc.mode, MaxDistance: tc.maxDist,
+			})
+
+			if tc.wantCode != 0 {
+				require.NotNil(t, appErr)
+				assert.Equal(t, tc.wantCode, appErr.Code)
+				return
+			}
+			require.Nil(t, appErr)
+			assert.Equal(t, tc.wantPrice, sample.Price.String())
+		})
+	}
+
+	t.Run("distance_in_details", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+		mockRepo.On("GetBracketing", ctx, "BTC", ts).Return(before, after, nil)
+
+		_, appErr := priceService.GetPriceAt(ctx, domain.PriceAtRequest{Symbol: "BTC", Timestamp: ts, MaxDistance: 5 * time.Second})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, map[string]int64{"closest_timestamp": after.Timestamp.Unix(), "distance_seconds": 10}, appErr.Details)
+	})
+
+	t.Run("interpolate_returns_sources", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+		mockRepo.On("GetBracketing", ctx, "BTC", ts).Return(before, after, nil)
+
+		quote, appErr := priceService.GetPriceAt(ctx, domain.PriceAtRequest{Symbol: "BTC", Timestamp: ts, Mode: domain.PriceModeInterpolate})
+
+		require.Nil(t, appErr)
+		assert.True(t, quote.Interpolated)
+		assert.Equal(t, ts, quote.Timestamp)
+		assert.Equal(t, before, quote.Before)
+		assert.Equal(t, after, quote.After)
+	})
+
+	t.Run("interpolate_exact_match", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+		exact := &domain.PriceSample{Symbol: "BTC", Price: decimal.NewFromInt(105), Timestamp: ts}
+		mockRepo.On("GetBracketing", ctx, "BTC", ts).Return(exact, exact, nil)
+
+		quote, appErr := priceService.GetPriceAt(ctx, domain.PriceAtRequest{Symbol: "BTC", Timestamp: ts, Mode: domain.PriceModeInterpolate})
+
+		require.Nil(t, appErr)
+		assert.False(t, quote.Interpolated)
+		assert.Equal(t, "105", quote.Price.String())
+	})
+
+	t.Run("invalid_mode", func(t *testing.T) {
+		mockRepo := mocks.NewPriceRepositoryInterface(t)
+		priceService := NewPriceService(mockRepo, nopLogger, config.AppConfig{})
+
+		_, appErr := priceService.GetPriceAt(ctx, domain.PriceAtRequest{Symbol: "BTC", Timestamp: ts, Mode: "closest"})
+
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	})
+}
@@ -10,12 +10,25 @@ type Service struct {
 	Currency       CurrencyServiceInterface
 	PriceCollector *PriceCollector
 	Price          PriceServiceInterface
+	Health         HealthServiceInterface
+	Analytics      AnalyticsServiceInterface
+	Conversion     ConversionServiceInterface
+	Export         ExportServiceInterface
+	Import         ImportServiceInterface
 }
 
 func NewService(repo *repository.Repository, logger logger.Logger, cfg *config.Config) *Service {
+	price := NewPriceService(repo.Price, logger, cfg.App)
+	collector := NewPriceCollector(repo.CurrencyRepository, repo.Price, logger, cfg.Collector)
+
 	return &Service{
-		Currency:       NewCurrencyService(repo.CurrencyRepository, logger),
-		PriceCollector: NewPriceCollector(repo.CurrencyRepository, repo.Price, logger, cfg.Collector),
-		Price:          NewPriceService(repo.Price, logger),
+		Currency:       NewCurrencyService(repo.CurrencyRepository, collector, logger),
+		PriceCollector: collector,
+		Price:          price,
+		Health:         NewHealthService(repo.Health),
+		Analytics:      NewAnalyticsService(repo.Price, logger),
+		Conversion:     NewConversionService(price, logger),
+		Export:         NewExportService(repo.Price, repo.CurrencyRepository, logger),
+		Import:         NewImportService(repo.Price, repo.CurrencyRepository, logger, cfg.App),
 	}
 }
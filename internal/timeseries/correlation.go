@@ -0,0 +1,100 @@
+package timeseries
+
+import (
+	"math"
+	"sort"
+)
+
+// LogReturns возвращает логарифмические доходности между соседними точками сетки.
+// Доходность i-го шага - ln(values[i+1] / values[i]); если одна из точек не заполнена
+// или цена не положительна, на её месте NaN.
+func LogReturns(values []GridValue) []float64 {
+	if len(values) < 2 {
+		return nil
+	}
+	returns := make([]float64, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		prev, cur := values[i-1], values[i]
+		if !prev.Valid || !cur.Valid || !prev.Value.IsPositive() || !cur.Value.IsPositive() {
+			returns[i-1] = math.NaN()
+			continue
+		}
+		returns[i-1] = math.Log(cur.Value.InexactFloat64() / prev.Value.InexactFloat64())
+	}
+	return returns
+}
+
+// Overlap оставляет только позиции, где оба ряда не NaN.
+func Overlap(x, y []float64) ([]float64, []float64) {
+	n := min(len(x), len(y))
+	xs := make([]float64, 0, n)
+	ys := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		if math.IsNaN(x[i]) || math.IsNaN(y[i]) {
+			continue
+		}
+		xs = append(xs, x[i])
+		ys = append(ys, y[i])
+	}
+	return xs, ys
+}
+
+// Pearson - выборочный коэффициент корреляции Пирсона. ok=false, если наблюдений меньше двух
+// или один из рядов постоянен.
+func Pearson(x, y []float64) (float64, bool) {
+	n := len(x)
+	if n != len(y) || n < 2 {
+		return 0, false
+	}
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	r := cov / math.Sqrt(varX*varY)
+	return math.Max(-1, math.Min(1, r)), true
+}
+
+// Spearman - ранговая корреляция Спирмена: Пирсон по рангам, одинаковым значениям
+// назначается средний ранг.
+func Spearman(x, y []float64) (float64, bool) {
+	if len(x) != len(y) {
+		return 0, false
+	}
+	return Pearson(ranks(x), ranks(y))
+}
+
+func ranks(values []float64) []float64 {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	result := make([]float64, len(values))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && values[idx[j+1]] == values[idx[i]] {
+			j++
+		}
+		rank := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			result[idx[k]] = rank
+		}
+		i = j + 1
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package timeseries
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogReturns(t *testing.T) {
+	values := []GridValue{
+		{Value: decimal.NewFromInt(100), Valid: true},
+		{Value: decimal.NewFromInt(110), Valid: true},
+		{Valid: false},
+		{Value: decimal.NewFromInt(121), Valid: true},
+		{Value: decimal.NewFromInt(121), Valid: true},
+	}
+
+	returns := LogReturns(values)
+
+	require.Len(t, returns, 4)
+	assert.InDelta(t, math.Log(1.1), returns[0], 1e-12)
+	assert.True(t, math.IsNaN(returns[1]))
+	assert.True(t, math.IsNaN(returns[2]))
+	assert.Equal(t, 0.0, returns[3])
+}
+
+func TestOverlap(t *testing.T) {
+	nan := math.NaN()
+	x, y := Overlap([]float64{1, nan, 3, 4}, []float64{5, 6, nan, 8})
+
+	assert.Equal(t, []float64{1, 4}, x)
+	assert.Equal(t, []float64{5, 8}, y)
+}
+
+func TestPearson(t *testing.T) {
+	// Эталон: numpy.corrcoef([1, 2, 3, 4, 5], [2, 4, 5, 4, 5])[0, 1] = 0.7745966692414834.
+	r, ok := Pearson([]float64{1, 2, 3, 4, 5}, []float64{2, 4, 5, 4, 5})
+	require.True(t, ok)
+	assert.InDelta(t, 0.7745966692414834, r, 1e-12)
+
+	r, ok = Pearson([]float64{1, 2, 3}, []float64{3, 2, 1})
+	require.True(t, ok)
+	assert.InDelta(t, -1, r, 1e-12)
+
+	_, ok = Pearson([]float64{1, 2, 3}, []float64{7, 7, 7})
+	assert.False(t, ok)
+	_, ok = Pearson([]float64{1}, []float64{2})
+	assert.False(t, ok)
+}
+
+func TestSpearman(t *testing.T) {
+	// Монотонная, но нелинейная связь даёт ровно 1.
+	r, ok := Spearman([]float64{1, 2, 3, 4, 5}, []float64{1, 8, 27, 64, 125})
+	require.True(t, ok)
+	assert.InDelta(t, 1, r, 1e-12)
+
+	// Эталон с повторами: scipy.stats.spearmanr([1, 2, 2, 3], [1, 3, 2, 4]).statistic = 0.9486832980505138.
+	r, ok = Spearman([]float64{1, 2, 2, 3}, []float64{1, 3, 2, 4})
+	require.True(t, ok)
+	assert.InDelta(t, 0.9486832980505138, r, 1e-12)
+}
@@ -0,0 +1,66 @@
+package timeseries
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// drawdownPlaces - точность долей просадки.
+const drawdownPlaces = 8
+
+// UnderwaterPoint - просадка в момент Time: доля падения от исторического максимума (<= 0).
+type UnderwaterPoint struct {
+	Time     time.Time
+	Value    decimal.Decimal
+	Drawdown decimal.Decimal
+}
+
+// DrawdownResult - максимальная просадка ряда. Peak - максимум перед самой глубокой точкой Trough,
+// Recovery - первая точка после Trough, вернувшаяся к уровню Peak (nil, если ещё не вернулась).
+// Для ряда без падений Max равен нулю, а Peak, Trough и Recovery - nil.
+type DrawdownResult struct {
+	Max        decimal.Decimal
+	Peak       *Point
+	Trough     *Point
+	Recovery   *Point
+	Underwater []UnderwaterPoint
+}
+
+// Drawdown считает просадки отсортированного по времени ряда относительно бегущего максимума.
+// Точки с неположительной ценой пропускаются.
+func Drawdown(points []Point) DrawdownResult {
+	var (
+		result    = DrawdownResult{Underwater: make([]UnderwaterPoint, 0, len(points))}
+		peak      *Point
+		worstPeak Point
+		trough    *Point
+	)
+	for i := range points {
+		p := points[i]
+		if !p.Value.IsPositive() {
+			continue
+		}
+		if peak == nil || p.Value.GreaterThanOrEqual(peak.Value) {
+			peak = &points[i]
+		}
+		dd := p.Value.Div(peak.Value).Sub(decimal.NewFromInt(1)).Round(drawdownPlaces)
+		result.Underwater = append(result.Underwater, UnderwaterPoint{Time: p.Time, Value: p.Value, Drawdown: dd})
+		if dd.LessThan(result.Max) {
+			result.Max = dd
+			worstPeak, trough = *peak, &points[i]
+		}
+	}
+	if trough == nil {
+		return result
+	}
+
+	result.Peak, result.Trough = &worstPeak, trough
+	for i := range points {
+		if points[i].Time.After(trough.Time) && points[i].Value.GreaterThanOrEqual(worstPeak.Value) {
+			result.Recovery = &points[i]
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package timeseries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawdown(t *testing.T) {
+	t.Run("recovered", func(t *testing.T) {
+		// Первая просадка 100 -> 90 (-10%), вторая 120 -> 84 (-30%), восстановление на 125.
+		points := []Point{pt(0, 100), pt(1, 90), pt(2, 120), pt(3, 96), pt(4, 84), pt(5, 110), pt(6, 125)}
+
+		result := Drawdown(points)
+
+		assert.Equal(t, "-0.3", result.Max.String())
+		require.NotNil(t, result.Peak)
+		assert.Equal(t, at(2), result.Peak.Time)
+		require.NotNil(t, result.Trough)
+		assert.Equal(t, at(4), result.Trough.Time)
+		require.NotNil(t, result.Recovery)
+		assert.Equal(t, at(6), result.Recovery.Time)
+		require.Len(t, result.Underwater, 7)
+		assert.Equal(t, "-0.1", result.Underwater[1].Drawdown.String())
+		assert.Equal(t, "0", result.Underwater[2].Drawdown.String())
+		assert.Equal(t, "-0.08333333", result.Underwater[5].Drawdown.String())
+	})
+
+	t.Run("not_recovered", func(t *testing.T) {
+		result := Drawdown([]Point{pt(0, 100), pt(1, 50), pt(2, 99)})
+
+		assert.Equal(t, "-0.5", result.Max.String())
+		assert.Nil(t, result.Recovery)
+	})
+
+	t.Run("monotonic", func(t *testing.T) {
+		result := Drawdown([]Point{pt(0, 1), pt(1, 2), pt(2, 3)})
+
+		assert.True(t, result.Max.IsZero())
+		assert.Nil(t, result.Peak)
+		assert.Nil(t, result.Trough)
+		assert.Len(t, result.Underwater, 3)
+	})
+}
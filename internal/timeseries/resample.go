@@ -0,0 +1,104 @@
+// Package timeseries содержит чистые функции для работы с рядами цен:
+// выравнивание на регулярную сетку и заполнение пропусков.
+package timeseries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FillStrategy - способ заполнить точку сетки, в ячейку которой не попало ни одного сэмпла.
+type FillStrategy string
+
+const (
+	// FillPrevious - последнее известное значение (LOCF).
+	FillPrevious FillStrategy = "previous"
+	// FillLinear - линейная интерполяция между соседними сэмплами.
+	FillLinear FillStrategy = "linear"
+	// FillNull - значение отсутствует.
+	FillNull FillStrategy = "null"
+)
+
+// interpolationPlaces - точность интерполированных цен, как у price_history.price NUMERIC(20, 8).
+const interpolationPlaces = 8
+
+// Point - сэмпл ряда.
+type Point struct {
+	Time  time.Time
+	Value decimal.Decimal
+}
+
+// GridValue - значение ряда в точке сетки. Observed означает, что в ячейку (Time-step, Time]
+// попал реальный сэмпл; иначе значение получено стратегией заполнения, а Valid=false
+// означает, что заполнить не удалось.
+type GridValue struct {
+	Time     time.Time
+	Value    decimal.Decimal
+	Valid    bool
+	Observed bool
+}
+
+func ParseFillStrategy(s string) (FillStrategy, error) {
+	switch FillStrategy(s) {
+	case FillPrevious, FillLinear, FillNull:
+		return FillStrategy(s), nil
+	case "", "locf":
+		return FillPrevious, nil
+	default:
+		return "", fmt.Errorf("unknown fill strategy %q", s)
+	}
+}
+
+// Grid возвращает точки from, from+step, ... не позже to.
+func Grid(from, to time.Time, step time.Duration) []time.Time {
+	if step <= 0 || to.Before(from) {
+		return nil
+	}
+	grid := make([]time.Time, 0, int(to.Sub(from)/step)+1)
+	for t := from; !t.After(to); t = t.Add(step) {
+		grid = append(grid, t)
+	}
+	return grid
+}
+
+// Resample выравнивает отсортированные по времени сэмплы на сетку. Для точек без
+// сэмплов в ячейке применяется fill; для previous и linear points должны включать
+// последний сэмпл до первой ячейки и (для linear) первый сэмпл после последней точки.
+func Resample(points []Point, grid []time.Time, step time.Duration, fill FillStrategy) []GridValue {
+	result := make([]GridValue, 0, len(grid))
+	// next - индекс первого сэмпла строго позже текущей точки сетки.
+	next := 0
+	for _, t := range grid {
+		for next < len(points) && !points[next].Time.After(t) {
+			next++
+		}
+		v := GridValue{Time: t}
+		var prev *Point
+		if next > 0 {
+			prev = &points[next-1]
+		}
+
+		switch {
+		case prev != nil && prev.Time.After(t.Add(-step)):
+			v.Value, v.Valid, v.Observed = prev.Value, true, true
+		case fill == FillPrevious && prev != nil:
+			v.Value, v.Valid = prev.Value, true
+		case fill == FillLinear && prev != nil && next < len(points):
+			v.Value, v.Valid = Interpolate(*prev, points[next], t), true
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// Interpolate линейно по времени интерполирует значение в момент t между a и b (a.Time <= t <= b.Time).
+func Interpolate(a, b Point, t time.Time) decimal.Decimal {
+	span := b.Time.Sub(a.Time)
+	if span <= 0 {
+		return a.Value
+	}
+	weight := decimal.NewFromInt(int64(t.Sub(a.Time))).Div(decimal.NewFromInt(int64(span)))
+	return a.Value.Add(b.Value.Sub(a.Value).Mul(weight)).Round(interpolationPlaces)
+}
@@ -0,0 +1,81 @@
+package timeseries
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(min int) time.Time {
+	return time.Date(2024, 1, 1, 0, min, 0, 0, time.UTC)
+}
+
+func pt(min int, v int64) Point {
+	return Point{Time: at(min), Value: decimal.NewFromInt(v)}
+}
+
+func TestGrid(t *testing.T) {
+	assert.Equal(t, []time.Time{at(0), at(15), at(30)}, Grid(at(0), at(30), 15*time.Minute))
+	assert.Equal(t, []time.Time{at(0), at(15)}, Grid(at(0), at(29), 15*time.Minute))
+	assert.Nil(t, Grid(at(30), at(0), 15*time.Minute))
+}
+
+func TestResample(t *testing.T) {
+	step := 15 * time.Minute
+	grid := Grid(at(0), at(60), step)
+	// Сэмпл до сетки, два внутри и один после.
+	points := []Point{pt(-20, 90), pt(10, 100), pt(14, 110), pt(50, 150), pt(70, 170)}
+
+	t.Run("previous", func(t *testing.T) {
+		values := Resample(points, grid, step, FillPrevious)
+
+		require.Len(t, values, 5)
+		assert.Equal(t, GridValue{Time: at(0), Value: decimal.NewFromInt(90), Valid: true}, values[0])
+		assert.Equal(t, GridValue{Time: at(15), Value: decimal.NewFromInt(110), Valid: true, Observed: true}, values[1])
+		assert.Equal(t, GridValue{Time: at(30), Value: decimal.NewFromInt(110), Valid: true}, values[2])
+		assert.Equal(t, GridValue{Time: at(45), Value: decimal.NewFromInt(110), Valid: true}, values[3])
+		assert.Equal(t, GridValue{Time: at(60), Value: decimal.NewFromInt(150), Valid: true, Observed: true}, values[4])
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		values := Resample(points, grid, step, FillLinear)
+
+		// Между 00:14 (110) и 00:50 (150): 00:30 -> 110 + 40*16/36.
+		assert.Equal(t, "127.77777778", values[2].Value.String())
+		assert.False(t, values[2].Observed)
+		assert.Equal(t, "144.44444444", values[3].Value.String())
+		assert.Equal(t, "96.66666667", values[0].Value.String())
+	})
+
+	t.Run("null", func(t *testing.T) {
+		values := Resample(points, grid, step, FillNull)
+
+		assert.False(t, values[0].Valid)
+		assert.True(t, values[1].Valid)
+		assert.False(t, values[2].Valid)
+		assert.False(t, values[3].Valid)
+	})
+
+	t.Run("linear_without_next_sample_stays_empty", func(t *testing.T) {
+		values := Resample([]Point{pt(10, 100)}, grid, step, FillLinear)
+
+		assert.True(t, values[1].Observed)
+		assert.False(t, values[2].Valid)
+	})
+}
+
+func TestParseFillStrategy(t *testing.T) {
+	fill, err := ParseFillStrategy("")
+	require.NoError(t, err)
+	assert.Equal(t, FillPrevious, fill)
+
+	fill, err = ParseFillStrategy("linear")
+	require.NoError(t, err)
+	assert.Equal(t, FillLinear, fill)
+
+	_, err = ParseFillStrategy("cubic")
+	assert.Error(t, err)
+}
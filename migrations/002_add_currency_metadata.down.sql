@@ -0,0 +1,7 @@
+ALTER TABLE tracked_currencies
+    DROP COLUMN IF EXISTS notes,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS enabled,
+    DROP COLUMN IF EXISTS display_decimals,
+    DROP COLUMN IF EXISTS provider_ids,
+    DROP COLUMN IF EXISTS name;
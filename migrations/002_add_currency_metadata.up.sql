@@ -0,0 +1,7 @@
+ALTER TABLE tracked_currencies
+    ADD COLUMN IF NOT EXISTS name VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS provider_ids JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ADD COLUMN IF NOT EXISTS display_decimals SMALLINT NOT NULL DEFAULT 2,
+    ADD COLUMN IF NOT EXISTS enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+ALTER TABLE price_history
+    DROP CONSTRAINT IF EXISTS fk_currency,
+    ADD CONSTRAINT fk_currency
+        FOREIGN KEY(currency_id)
+        REFERENCES tracked_currencies(id)
+        ON DELETE CASCADE;
+
+ALTER TABLE tracked_currencies
+    DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,10 @@
+ALTER TABLE tracked_currencies
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+-- История больше не удаляется вместе с валютой: удалить её можно только явной очисткой.
+ALTER TABLE price_history
+    DROP CONSTRAINT IF EXISTS fk_currency,
+    ADD CONSTRAINT fk_currency
+        FOREIGN KEY(currency_id)
+        REFERENCES tracked_currencies(id)
+        ON DELETE RESTRICT;
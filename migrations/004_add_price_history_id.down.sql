@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_price_history_currency_id_timestamp_id;
+CREATE INDEX IF NOT EXISTS idx_price_history_currency_id_timestamp ON price_history (currency_id, timestamp DESC);
+
+ALTER TABLE price_history
+    DROP COLUMN IF EXISTS id;
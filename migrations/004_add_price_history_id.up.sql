@@ -0,0 +1,6 @@
+-- Суррогатный ключ записи истории: тай-брейкер курсора для сэмплов с одинаковым timestamp.
+ALTER TABLE price_history
+    ADD COLUMN IF NOT EXISTS id BIGINT GENERATED ALWAYS AS IDENTITY;
+
+DROP INDEX IF EXISTS idx_price_history_currency_id_timestamp;
+CREATE INDEX IF NOT EXISTS idx_price_history_currency_id_timestamp_id ON price_history (currency_id, timestamp DESC, id DESC);
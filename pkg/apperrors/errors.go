@@ -10,6 +10,8 @@ type AppError struct {
 	Code    int
 	Message string
 	Err     error
+	// Details - необязательные структурированные подробности, отдаются клиенту как есть.
+	Details any
 }
 
 func (e *AppError) Error() string {
@@ -23,6 +25,12 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// WithDetails добавляет к ошибке подробности для клиента.
+func (e *AppError) WithDetails(details any) *AppError {
+	e.Details = details
+	return e
+}
+
 func New(code int, message string, err error) *AppError {
 	return &AppError{
 		Code:    code,
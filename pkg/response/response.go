@@ -43,6 +43,7 @@ type APIError struct {
 	Code     int    `json:"code"`
 	Message  string `json:"message"`
 	Resource string `json:"resource"`
+	Details  any    `json:"details,omitempty"`
 }
 
 func (e APIError) Send(w http.ResponseWriter) {